@@ -12,6 +12,7 @@ import (
 
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/integcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/performance"
@@ -26,6 +27,7 @@ type newControllerWrapper func(config *loadtest.Config, controllerConfig interfa
 type API struct {
 	newControllerFn newControllerWrapper
 	agents          map[string]*loadtest.LoadTester
+	receivers       map[string]*integcontroller.Receiver
 	metrics         *performance.Metrics
 }
 
@@ -48,6 +50,7 @@ func (a *API) createLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
 		LoadTestConfig         loadtest.Config
 		SimpleControllerConfig *simplecontroller.Config `json:",omitempty"`
 		SimulControllerConfig  *simulcontroller.Config  `json:",omitempty"`
+		IntegControllerConfig  *integcontroller.Config  `json:",omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 		writeResponse(w, http.StatusBadRequest, &Response{
@@ -80,6 +83,13 @@ func (a *API) createLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 		ucConfig = data.SimulControllerConfig
+	case loadtest.UserControllerIntegrations:
+		if data.IntegControllerConfig == nil {
+			mlog.Warn("could not read controller config from the request")
+			ucConfig, err = integcontroller.ReadConfig("")
+			break
+		}
+		ucConfig = data.IntegControllerConfig
 	}
 	if err != nil {
 		writeResponse(w, http.StatusBadRequest, &Response{
@@ -113,6 +123,19 @@ func (a *API) createLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+
+	if ltConfig.UserControllerConfiguration.Type == loadtest.UserControllerIntegrations {
+		receiver, err := integcontroller.NewReceiver(ucConfig.(*integcontroller.Config).ReceiverListenAddress)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{
+				Id:      agentId,
+				Message: "load-test agent creation failed",
+				Error:   fmt.Sprintf("could not start integrations receiver: %s", err),
+			})
+			return
+		}
+		a.receivers[agentId] = receiver
+	}
 	a.agents[agentId] = lt
 
 	writeResponse(w, http.StatusCreated, &Response{
@@ -169,6 +192,40 @@ func (a *API) stopLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (a *API) pauseLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
+	lt, err := a.getLoadAgentById(w, r)
+	if err != nil {
+		return
+	}
+	if err = lt.Pause(); err != nil {
+		writeResponse(w, http.StatusOK, &Response{
+			Error: err.Error(),
+		})
+		return
+	}
+	writeResponse(w, http.StatusOK, &Response{
+		Message: "load-test agent paused",
+		Status:  lt.Status(),
+	})
+}
+
+func (a *API) resumeLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
+	lt, err := a.getLoadAgentById(w, r)
+	if err != nil {
+		return
+	}
+	if err = lt.Resume(); err != nil {
+		writeResponse(w, http.StatusOK, &Response{
+			Error: err.Error(),
+		})
+		return
+	}
+	writeResponse(w, http.StatusOK, &Response{
+		Message: "load-test agent resumed",
+		Status:  lt.Status(),
+	})
+}
+
 func (a *API) destroyLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
 	lt, err := a.getLoadAgentById(w, r)
 	if err != nil {
@@ -177,7 +234,12 @@ func (a *API) destroyLoadAgentHandler(w http.ResponseWriter, r *http.Request) {
 
 	_ = lt.Stop() // we are ignoring the error here in case the load test was previously stopped
 
-	delete(a.agents, mux.Vars(r)["id"])
+	agentId := mux.Vars(r)["id"]
+	if receiver, ok := a.receivers[agentId]; ok {
+		_ = receiver.Close()
+		delete(a.receivers, agentId)
+	}
+	delete(a.agents, agentId)
 	writeResponse(w, http.StatusOK, &Response{
 		Message: "load-test agent destroyed",
 		Status:  lt.Status(),
@@ -238,8 +300,20 @@ func (a *API) removeUsersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var n int
+	if strategy := loadtest.RemovalStrategy(r.FormValue("strategy")); strategy != "" {
+		if err := strategy.IsValid(); err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{
+				Error: fmt.Sprintf("invalid strategy: %s", strategy),
+			})
+			return
+		}
+		n, err = lt.RemoveUsersWithStrategy(amount, strategy)
+	} else {
+		n, err = lt.RemoveUsers(amount)
+	}
+
 	var res Response
-	n, err := lt.RemoveUsers(amount)
 	if err != nil {
 		res.Error = err.Error()
 	}
@@ -249,6 +323,30 @@ func (a *API) removeUsersHandler(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, http.StatusOK, &res)
 }
 
+func (a *API) setRatesHandler(w http.ResponseWriter, r *http.Request) {
+	lt, err := a.getLoadAgentById(w, r)
+	if err != nil {
+		return
+	}
+
+	var dist []loadtest.RatesDistribution
+	if err := json.NewDecoder(r.Body).Decode(&dist); err != nil {
+		writeResponse(w, http.StatusBadRequest, &Response{
+			Error: fmt.Sprintf("could not read request: %s", err),
+		})
+		return
+	}
+
+	var res Response
+	if err := lt.SetRatesDistribution(dist); err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Message = "rates distribution updated"
+	}
+	res.Status = lt.Status()
+	writeResponse(w, http.StatusOK, &res)
+}
+
 func (a *API) pprofIndexHandler(w http.ResponseWriter, r *http.Request) {
 	html := `
 		<html>
@@ -272,16 +370,20 @@ func SetupAPIRouter(f newControllerWrapper) *mux.Router {
 	api := API{
 		newControllerFn: f,
 		agents:          make(map[string]*loadtest.LoadTester),
+		receivers:       make(map[string]*integcontroller.Receiver),
 		metrics:         performance.NewMetrics(),
 	}
 	r.HandleFunc("/create", api.createLoadAgentHandler).Methods("POST").Queries("id", "{^[a-z]+[0-9]*$}")
 	r.HandleFunc("/{id}/run", api.runLoadAgentHandler).Methods("POST")
 	r.HandleFunc("/{id}/stop", api.stopLoadAgentHandler).Methods("POST")
+	r.HandleFunc("/{id}/pause", api.pauseLoadAgentHandler).Methods("POST")
+	r.HandleFunc("/{id}/resume", api.resumeLoadAgentHandler).Methods("POST")
 	r.HandleFunc("/{id}", api.destroyLoadAgentHandler).Methods("DELETE")
 	r.HandleFunc("/{id}", api.getLoadAgentStatusHandler).Methods("GET")
 	r.HandleFunc("/{id}/status", api.getLoadAgentStatusHandler).Methods("GET")
 	r.HandleFunc("/{id}/addusers", api.addUsersHandler).Methods("POST").Queries("amount", "{[0-9]*?}")
 	r.HandleFunc("/{id}/removeusers", api.removeUsersHandler).Methods("POST").Queries("amount", "{[0-9]*?}")
+	r.HandleFunc("/{id}/rates", api.setRatesHandler).Methods("POST")
 
 	// Add profile endpoints
 	p := router.PathPrefix("/debug/pprof").Subrouter()
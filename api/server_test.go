@@ -9,12 +9,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/fakeserver"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store/memstore"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user/userentity"
 	"github.com/mattermost/mattermost-load-test-ng/performance"
 
 	"github.com/gavv/httpexpect"
@@ -98,6 +102,10 @@ func TestAPI(t *testing.T) {
 		e.POST(ltId + "/run").Expect().Status(http.StatusOK)
 		e.POST(ltId+"/addusers").WithQuery("amount", 10).Expect().Status(http.StatusOK)
 		e.POST(ltId+"/removeusers").WithQuery("amount", 3).Expect().Status(http.StatusOK)
+		e.POST(ltId+"/removeusers").WithQuery("amount", 1).WithQuery("strategy", "random").Expect().Status(http.StatusOK)
+		e.POST(ltId+"/removeusers").WithQuery("amount", 1).WithQuery("strategy", "bad").Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object().ContainsKey("error")
 		e.POST(ltId+"/addusers").WithQuery("amount", 0).Expect().
 			Status(http.StatusBadRequest).
 			JSON().Object().ContainsKey("error")
@@ -122,6 +130,31 @@ func TestAPI(t *testing.T) {
 			Status(http.StatusBadRequest).
 			JSON().Object().ContainsKey("error")
 
+		e.POST(ltId + "/rates").WithJSON([]loadtest.RatesDistribution{
+			{Rate: 0.5, Percentage: 0.5},
+			{Rate: 2.0, Percentage: 0.5},
+		}).Expect().Status(http.StatusOK).
+			JSON().Object().NotContainsKey("error")
+		e.POST(ltId + "/rates").WithJSON([]loadtest.RatesDistribution{
+			{Rate: 0.5, Percentage: 0.7},
+		}).Expect().Status(http.StatusOK).
+			JSON().Object().ContainsKey("error")
+		e.POST(ltId + "/rates").WithBytes([]byte("bad")).Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object().ContainsKey("error")
+
+		e.POST(ltId+"/resume").Expect().Status(http.StatusOK).
+			JSON().Object().ValueEqual("error", loadtest.ErrNotPaused.Error())
+		obj = e.POST(ltId + "/pause").Expect().Status(http.StatusOK).
+			JSON().Object().NotContainsKey("error")
+		obj.Value("status").Object().ValueEqual("State", "paused")
+		e.POST(ltId+"/pause").Expect().Status(http.StatusOK).
+			JSON().Object().ValueEqual("error", loadtest.ErrNotRunning.Error())
+		obj = e.POST(ltId + "/resume").Expect().Status(http.StatusOK).
+			JSON().Object().NotContainsKey("error")
+		obj.Value("status").Object().ValueEqual("State", "running")
+		e.POST(ltId + "/pause").Expect().Status(http.StatusOK)
+
 		e.POST(ltId + "/stop").Expect().Status(http.StatusOK)
 		e.DELETE(ltId).Expect().Status(http.StatusOK)
 	})
@@ -176,3 +209,76 @@ func TestAPI(t *testing.T) {
 		e.DELETE(ltId).Expect().Status(http.StatusOK)
 	})
 }
+
+func TestAPIWithFakeServer(t *testing.T) {
+	fs := fakeserver.New()
+	defer fs.Close()
+
+	newUser := func(namePrefix string, id int) (*userentity.UserEntity, error) {
+		store, err := memstore.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		return userentity.New(userentity.Setup{Store: store}, userentity.Config{
+			ServerURL:    fs.URL(),
+			WebSocketURL: fs.WebSocketURL(),
+			Username:     fmt.Sprintf("%s-%d", namePrefix, id),
+			Email:        fmt.Sprintf("%s-%d@example.com", namePrefix, id),
+			Password:     "testPass123$",
+		}), nil
+	}
+
+	newControllerFn := func(config *loadtest.Config, controllerConfig interface{}, userOffset int, namePrefix string, metrics *performance.Metrics) loadtest.NewController {
+		return func(id int, status chan<- control.UserStatus) (control.UserController, error) {
+			ue, err := newUser(namePrefix, id+userOffset)
+			if err != nil {
+				return nil, err
+			}
+			return simplecontroller.New(id, ue, controllerConfig.(*simplecontroller.Config), status)
+		}
+	}
+
+	server := httptest.NewServer(SetupAPIRouter(newControllerFn))
+	defer server.Close()
+	e := httpexpect.New(t, server.URL+"/loadagent")
+
+	ltConfig := loadtest.Config{}
+	require.NoError(t, defaults.Set(&ltConfig))
+	ltConfig.ConnectionConfiguration.ServerURL = fs.URL()
+	ltConfig.ConnectionConfiguration.WebSocketURL = fs.WebSocketURL()
+	ltConfig.UserControllerConfiguration.Type = loadtest.UserControllerSimple
+	ltConfig.UsersConfiguration.InitialActiveUsers = 0
+	ltConfig.UsersConfiguration.MaxActiveUsers = 10
+	ltConfig.LogSettings.EnableConsole = false
+	ltConfig.LogSettings.EnableFile = false
+
+	ucConfig, err := simplecontroller.ReadConfig("../config/simplecontroller.sample.json")
+	require.NoError(t, err)
+	for i := range ucConfig.Actions {
+		ucConfig.Actions[i].WaitAfterMs = 10
+	}
+
+	ltId := "lt0"
+	e.POST("/create").WithQuery("id", ltId).WithJSON(requestData{
+		LoadTestConfig:         ltConfig,
+		SimpleControllerConfig: ucConfig,
+	}).Expect().Status(http.StatusCreated)
+	e.POST(ltId + "/run").Expect().Status(http.StatusOK)
+	e.POST(ltId+"/addusers").WithQuery("amount", 2).Expect().Status(http.StatusOK)
+
+	require.Eventually(t, func() bool {
+		status := e.GET(ltId + "/status").Expect().Status(http.StatusOK).
+			JSON().Object().Value("status").Object()
+		return status.Value("NumUsers").Number().Raw() == 2
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// The users of the agent should have signed up to the server.
+	for id := 1; id <= 2; id++ {
+		ue, err := newUser(ltId, id)
+		require.NoError(t, err)
+		require.NoError(t, ue.Login())
+	}
+
+	e.POST(ltId + "/stop").Expect().Status(http.StatusOK)
+	e.DELETE(ltId).Expect().Status(http.StatusOK)
+}
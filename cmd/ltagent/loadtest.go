@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
@@ -14,6 +17,7 @@ import (
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/clustercontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/gencontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/integcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/noopcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
@@ -41,6 +45,21 @@ func runGenLoadtest(lt *loadtest.LoadTester, numUsers int) error {
 	return lt.Stop()
 }
 
+// waitForRamp blocks until the ramp profile of the given load-test is done or
+// an interrupt signal is received.
+func waitForRamp(lt *loadtest.LoadTester, interruptChannel <-chan os.Signal) {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for lt.Status().Ramp.Stage != loadtest.RampStageDone {
+		select {
+		case <-interruptChannel:
+			mlog.Info("loadtest interrupted")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func RunLoadTestCmdF(cmd *cobra.Command, args []string) error {
 	configFilePath, err := cmd.Flags().GetString("config")
 	if err != nil {
@@ -75,11 +94,21 @@ func RunLoadTestCmdF(cmd *cobra.Command, args []string) error {
 		ucConfig, err = simulcontroller.ReadConfig(ucConfigPath)
 	case loadtest.UserControllerGenerative:
 		ucConfig, err = gencontroller.ReadConfig(ucConfigPath)
+	case loadtest.UserControllerIntegrations:
+		ucConfig, err = integcontroller.ReadConfig(ucConfigPath)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to read controller configuration: %w", err)
 	}
 
+	if controllerType == loadtest.UserControllerIntegrations {
+		receiver, err := integcontroller.NewReceiver(ucConfig.(*integcontroller.Config).ReceiverListenAddress)
+		if err != nil {
+			return err
+		}
+		defer receiver.Close()
+	}
+
 	userPrefix, err := cmd.Flags().GetString("user-prefix")
 	if err != nil {
 		return err
@@ -128,11 +157,24 @@ func RunLoadTestCmdF(cmd *cobra.Command, args []string) error {
 
 	mlog.Info("loadtest started")
 
-	durationSec, err := cmd.Flags().GetInt("duration")
-	if err != nil {
-		return err
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interruptChannel)
+
+	if len(config.RampProfile.Phases) > 0 {
+		// The ramp profile defines the duration of the load-test.
+		waitForRamp(lt, interruptChannel)
+	} else {
+		durationSec, err := cmd.Flags().GetInt("duration")
+		if err != nil {
+			return err
+		}
+		select {
+		case <-interruptChannel:
+			mlog.Info("loadtest interrupted")
+		case <-time.After(time.Duration(durationSec) * time.Second):
+		}
 	}
-	time.Sleep(time.Duration(durationSec) * time.Second)
 	err = lt.Stop()
 	mlog.Info("loadtest done", mlog.String("elapsed", time.Since(start).String()))
 
@@ -148,7 +190,7 @@ func MakeLoadTestCommand() *cobra.Command {
 	}
 	cmd.PersistentFlags().StringP("config", "c", "", "path to the configuration file to use")
 	cmd.Flags().StringP("controller-config", "", "", "path to the controller configuration file to use")
-	cmd.Flags().IntP("duration", "d", 60, "number of seconds to pass before stopping the load-test")
+	cmd.Flags().IntP("duration", "d", 60, "number of seconds to pass before stopping the load-test, ignored if a ramp profile is configured")
 	cmd.Flags().IntP("num-users", "n", 0, "number of users to run, setting this value will override the config setting")
 	cmd.Flags().Float64P("rate", "r", 1.0, "rate value for the controller")
 	cmd.PersistentFlags().StringP("user-prefix", "", "testuser", "prefix used when generating usernames and emails")
@@ -189,6 +231,8 @@ func newControllerWrapper(config *loadtest.Config, controllerConfig interface{},
 			MaxStoredUsers:          1000,
 			MaxStoredChannelMembers: 1000,
 			MaxStoredStatuses:       1000,
+			MaxStoredThreads:        200,
+			MaxStoredFileInfos:      200,
 		})
 		if err != nil {
 			return nil, err
@@ -204,11 +248,41 @@ func newControllerWrapper(config *loadtest.Config, controllerConfig interface{},
 
 		switch config.UserControllerConfiguration.Type {
 		case loadtest.UserControllerSimple:
-			return simplecontroller.New(id, ue, controllerConfig.(*simplecontroller.Config), status)
+			controller, err := simplecontroller.New(id, ue, controllerConfig.(*simplecontroller.Config), status)
+			if err != nil {
+				return nil, err
+			}
+			if metrics != nil {
+				controller.SetMetrics(metrics.ControllerMetrics())
+			}
+			return controller, nil
 		case loadtest.UserControllerSimulative:
-			return simulcontroller.New(id, ue, controllerConfig.(*simulcontroller.Config), status)
+			controller, err := simulcontroller.New(id, ue, controllerConfig.(*simulcontroller.Config), status)
+			if err != nil {
+				return nil, err
+			}
+			if metrics != nil {
+				controller.SetMetrics(metrics.ControllerMetrics())
+			}
+			return controller, nil
 		case loadtest.UserControllerGenerative:
-			return gencontroller.New(id, ue, controllerConfig.(*gencontroller.Config), status)
+			controller, err := gencontroller.New(id, ue, controllerConfig.(*gencontroller.Config), status)
+			if err != nil {
+				return nil, err
+			}
+			if metrics != nil {
+				controller.SetMetrics(metrics.ControllerMetrics())
+			}
+			return controller, nil
+		case loadtest.UserControllerIntegrations:
+			controller, err := integcontroller.New(id, ue, controllerConfig.(*integcontroller.Config), status)
+			if err != nil {
+				return nil, err
+			}
+			if metrics != nil {
+				controller.SetMetrics(metrics.ControllerMetrics())
+			}
+			return controller, nil
 		case loadtest.UserControllerNoop:
 			return noopcontroller.New(id, ue, status)
 		case loadtest.UserControllerCluster:
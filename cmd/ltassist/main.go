@@ -13,6 +13,7 @@ import (
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
 	"github.com/mattermost/mattermost-load-test-ng/deployment"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/integcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
 
@@ -25,6 +26,7 @@ var docs = map[string]string{
 	"deployer":         "./docs/deployer_config.md",
 	"simplecontroller": "./docs/simplecontroller_config.md",
 	"simulcontroller":  "./docs/simulcontroller_config.md",
+	"integcontroller":  "./docs/integcontroller_config.md",
 }
 
 func main() {
@@ -161,6 +163,8 @@ func getDefaultConfig(configType string) (interface{}, error) {
 		cfg = &simplecontroller.Config{}
 	case "simulcontroller":
 		cfg = &simulcontroller.Config{}
+	case "integcontroller":
+		cfg = &integcontroller.Config{}
 	default:
 		return nil, fmt.Errorf("could not find: %q", configType)
 	}
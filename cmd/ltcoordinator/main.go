@@ -5,8 +5,11 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/mattermost/mattermost-load-test-ng/coordinator"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest"
@@ -39,7 +42,39 @@ func RunCoordinatorCmdF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create coordinator: %w", err)
 	}
 
-	return c.Run()
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return err
+	}
+	if port <= 0 {
+		return c.Run()
+	}
+	host, err := cmd.Flags().GetString("host")
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		mlog.Info("API server started, listening on", mlog.String("host", host), mlog.Int("port", port))
+		if err := http.ListenAndServe(fmt.Sprintf("%s:%d", host, port), setupRouter(c)); err != nil {
+			mlog.Error("API server failed", mlog.Err(err))
+		}
+	}()
+
+	// The signal interrupting the run is also received here, so the process
+	// exits right away in that case.
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	if err := c.Run(); err != nil {
+		return err
+	}
+
+	// Keep the API server up so that the final results can be fetched.
+	mlog.Info("coordinator finished, API server still serving the results until interrupted")
+	<-interruptChannel
+
+	return nil
 }
 
 func main() {
@@ -51,6 +86,8 @@ func main() {
 	}
 	rootCmd.PersistentFlags().StringP("config", "c", "", "path to the configuration file to use")
 	rootCmd.PersistentFlags().StringP("ltagent-config", "l", "", "path to the load-test agent configuration file to use")
+	rootCmd.PersistentFlags().IntP("port", "p", 4001, "port the API server listens on, 0 to disable it")
+	rootCmd.PersistentFlags().StringP("host", "", "127.0.0.1", "address the API server binds to. The API is unauthenticated, so it should only be reachable by trusted clients")
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
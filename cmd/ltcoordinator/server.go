@@ -0,0 +1,81 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator"
+
+	"github.com/gorilla/mux"
+)
+
+// Response contains the data returned by the coordinator HTTP API.
+type Response struct {
+	Message string              `json:"message,omitempty"` // Message contains information about the response.
+	Status  *coordinator.Status `json:"status,omitempty"`  // Status contains the current status of the coordinator.
+	Error   string              `json:"error,omitempty"`   // Error is set if there was an error during the operation.
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeResult writes the outcome of an operation on the coordinator.
+func writeResult(w http.ResponseWriter, c *coordinator.Coordinator, err error, message string) {
+	var res Response
+	if err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Message = message
+	}
+	status := c.Status()
+	res.Status = &status
+	writeJSON(w, http.StatusOK, &res)
+}
+
+// setupRouter creates the router exposing the coordinator HTTP API.
+func setupRouter(c *coordinator.Coordinator) *mux.Router {
+	router := mux.NewRouter()
+	r := router.PathPrefix("/coordinator").Subrouter()
+
+	r.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		status := c.Status()
+		writeJSON(w, http.StatusOK, &Response{Status: &status})
+	}).Methods("GET")
+
+	r.HandleFunc("/results", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, c.Results())
+	}).Methods("GET")
+
+	r.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, c, c.Stop(), "coordinator stopping")
+	}).Methods("POST")
+
+	r.HandleFunc("/pause", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, c, c.Pause(), "feedback loop paused")
+	}).Methods("POST")
+
+	r.HandleFunc("/resume", func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, c, c.Resume(), "feedback loop resumed")
+	}).Methods("POST")
+
+	r.HandleFunc("/target", func(w http.ResponseWriter, r *http.Request) {
+		users, err := strconv.Atoi(r.FormValue("users"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, &Response{
+				Error: fmt.Sprintf("invalid users: %s", r.FormValue("users")),
+			})
+			return
+		}
+		writeResult(w, c, c.SetTarget(users), fmt.Sprintf("target set to %d users", users))
+	}).Methods("POST")
+
+	return router
+}
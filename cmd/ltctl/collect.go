@@ -134,6 +134,17 @@ func createClients(output *terraform.Output) (map[string]*ssh.Client, error) {
 	return clients, nil
 }
 
+// listFiles returns the paths of the files matching the given glob pattern on
+// the instance reachable through sshc.
+func listFiles(sshc *ssh.Client, pattern string) []string {
+	out, err := sshc.RunCommand("ls -1 " + pattern)
+	if err != nil {
+		fmt.Printf("failed to list files matching %q: %s\n", pattern, err)
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
 func RunCollectCmdF(cmd *cobra.Command, args []string) error {
 	if os.Getenv("SSH_AUTH_SOCK") == "" {
 		return fmt.Errorf("ssh agent not running. Please run eval \"$(ssh-agent -s)\" and then ssh-add")
@@ -181,6 +192,10 @@ func RunCollectCmdF(cmd *cobra.Command, args []string) error {
 			addInfo(name, "/home/ubuntu/agent.log", true)
 		case name == "coordinator":
 			addInfo(name, "/home/ubuntu/mattermost-load-test-ng/ltcoordinator.log", true)
+			// Runs started with a name store their results in a file of their own.
+			for _, path := range listFiles(clients[name], "/home/ubuntu/mattermost-load-test-ng/ltcoordinator_results*.json") {
+				addInfo(name, path, false)
+			}
 			addInfo(name, "/home/ubuntu/mattermost-load-test-ng/config/config.json", false)
 			addInfo(name, "/home/ubuntu/mattermost-load-test-ng/config/coordinator.json", false)
 			addInfo(name, "/home/ubuntu/mattermost-load-test-ng/config/simplecontroller.json", false)
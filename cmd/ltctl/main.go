@@ -43,9 +43,14 @@ func RunStartCmdF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	runName, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return err
+	}
+
 	t := terraform.New(config)
 	defer t.Cleanup()
-	return t.StartCoordinator()
+	return t.StartCoordinator(runName)
 }
 
 func RunInfoCmdF(cmd *cobra.Command, args []string) error {
@@ -139,12 +144,16 @@ func main() {
 		Short: "Manage the load-test",
 	}
 
+	startCmd := &cobra.Command{
+		Use:     "start",
+		Short:   "Start the coordinator in the current load-test deployment",
+		Example: "ltctl loadtest start --name=base",
+		RunE:    RunStartCmdF,
+	}
+	startCmd.Flags().StringP("name", "n", "", "A name for the load-test run. Its results are stored under this name and can be used to generate reports later.")
+
 	loadtestComands := []*cobra.Command{
-		{
-			Use:   "start",
-			Short: "Start the coordinator in the current load-test deployment",
-			RunE:  RunStartCmdF,
-		},
+		startCmd,
 		{
 			Use:   "stop",
 			Short: "Stop the coordinator in the current load-test deployment",
@@ -211,13 +220,19 @@ func main() {
 	}
 
 	genReport := &cobra.Command{
-		Use:     "generate",
-		Short:   "Generate a report from a load-test from a start time to end time.",
-		Example: "ltctl report generate --output=base.out --label=base \"2020-06-17 04:37:05\" \"2020-06-17 04:42:00\"",
-		RunE:    RunGenerateReportCmdF,
+		Use:   "generate [start-time end-time]",
+		Short: "Generate a report from a load-test from a start time to end time.",
+		Long:  "Generate a report from a load-test from a start time to end time. If no times are given, the time range of the latest load-test run, or the one set with --run, is used.",
+		Example: "ltctl report generate --output=base.out --label=base \"2020-06-17 04:37:05\" \"2020-06-17 04:42:00\"\n" +
+			"ltctl report generate --output=base.out --run=base --trim-start=5m",
+		RunE: RunGenerateReportCmdF,
 	}
 	genReport.Flags().StringP("output", "o", "ltreport.out", "Path to the output file to write the report to.")
 	genReport.Flags().StringP("label", "l", "", "A friendly name for the report.")
+	genReport.Flags().String("format", "json", "The format of the report. Possible values are json and html. Only reports in json format can be compared.")
+	genReport.Flags().String("run", "", "The name of the load-test run, as set with ltctl loadtest start --name, to take the time range from. Defaults to the latest run. Also used as label if --label is not set.")
+	genReport.Flags().Duration("trim-start", 0, "The warm-up time to leave out from the beginning of the load-test run.")
+	genReport.Flags().Duration("trim-end", 0, "The cool-down time to leave out from the end of the load-test run.")
 
 	compareReport := &cobra.Command{
 		Use:     "compare",
@@ -227,9 +242,22 @@ func main() {
 		RunE:    RunCompareReportCmdF,
 	}
 	compareReport.Flags().StringP("output", "o", "", "Path to the output file to write the comparison to. If this is not set, the report is displayed to stdout.")
-	compareReport.Flags().Bool("graph", false, "If set to true, it also generates graphs comparing different metrics from the load tests. This needs gnuplot to be present in the system.")
+	compareReport.Flags().Bool("graph", false, "If set to true, it also generates graphs comparing different metrics from the load tests.")
+	compareReport.Flags().String("graph-format", "png", "The image format of the graphs generated with --graph. Possible values are png and svg.")
+	compareReport.Flags().String("format", "markdown", "The format of the comparison. Possible values are markdown and html. The html format includes interactive graphs.")
+	compareReport.Flags().Bool("significance", false, "If set to true, it annotates each delta with the confidence that it is statistically significant rather than noise.")
+
+	checkReport := &cobra.Command{
+		Use:     "check",
+		Short:   "Check a report for performance regressions",
+		Long:    "Check a candidate report for performance regressions against a base report. It exits with a non-zero status if any measurement regressed more than allowed by the thresholds.",
+		Example: "ltctl report check base.out new.out --thresholds thresholds.json",
+		RunE:    RunCheckReportCmdF,
+	}
+	checkReport.Flags().StringP("thresholds", "t", "", "Path to the file containing the allowed regressions. See config/thresholds.sample.json for an example.")
+	_ = checkReport.MarkFlagRequired("thresholds")
 
-	reportCmds := []*cobra.Command{genReport, compareReport}
+	reportCmds := []*cobra.Command{genReport, compareReport, checkReport}
 	reportCmd.AddCommand(reportCmds...)
 	rootCmd.AddCommand(reportCmd)
 
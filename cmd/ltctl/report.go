@@ -8,37 +8,70 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"regexp"
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
+	"github.com/mattermost/mattermost-load-test-ng/deployment"
 	"github.com/mattermost/mattermost-load-test-ng/deployment/terraform"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/report"
 
 	"github.com/spf13/cobra"
 )
 
-func RunGenerateReportCmdF(cmd *cobra.Command, args []string) error {
-	err := cobra.MinimumNArgs(2)(cmd, args)
+// getRunTimeRange returns the time range of a load-test run from the
+// results stored by the coordinator in the current deployment.
+func getRunTimeRange(cmd *cobra.Command, config *deployment.Config) (time.Time, time.Time, error) {
+	runName, err := cmd.Flags().GetString("run")
 	if err != nil {
-		return err
+		return time.Time{}, time.Time{}, err
 	}
-
-	config, err := getConfig(cmd)
+	trimStart, err := cmd.Flags().GetDuration("trim-start")
 	if err != nil {
-		return err
+		return time.Time{}, time.Time{}, err
+	}
+	trimEnd, err := cmd.Flags().GetDuration("trim-end")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
 	}
 
-	const layout = "2006-01-02 15:04:05"
-	startTime, err := time.Parse(layout, args[0])
+	t := terraform.New(config)
+	defer t.Cleanup()
+	results, err := t.GetCoordinatorResults(runName)
 	if err != nil {
-		return fmt.Errorf("start-time in incorrect format: %w", err)
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to get results of the load-test run: %w", err)
+	}
+	return results.TimeRange(trimStart, trimEnd)
+}
+
+func RunGenerateReportCmdF(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 && len(args) != 2 {
+		return errors.New("either both start-time and end-time or none of them must be provided")
 	}
 
-	endTime, err := time.Parse(layout, args[1])
+	config, err := getConfig(cmd)
 	if err != nil {
-		return fmt.Errorf("end-time in incorrect format: %w", err)
+		return err
+	}
+
+	var startTime, endTime time.Time
+	if len(args) == 2 {
+		const layout = "2006-01-02 15:04:05"
+		startTime, err = time.Parse(layout, args[0])
+		if err != nil {
+			return fmt.Errorf("start-time in incorrect format: %w", err)
+		}
+
+		endTime, err = time.Parse(layout, args[1])
+		if err != nil {
+			return fmt.Errorf("end-time in incorrect format: %w", err)
+		}
+	} else {
+		startTime, endTime, err = getRunTimeRange(cmd, config)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Using the time range of the load-test run: %s - %s\n", startTime.UTC().Format(time.RFC3339), endTime.UTC().Format(time.RFC3339))
 	}
 
 	file, err := cmd.Flags().GetString("output")
@@ -50,6 +83,20 @@ func RunGenerateReportCmdF(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if label == "" {
+		label, err = cmd.Flags().GetString("run")
+		if err != nil {
+			return err
+		}
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != string(report.FormatHTML) {
+		return fmt.Errorf("invalid format %q", format)
+	}
 
 	if endTime.Before(startTime) {
 		return errors.New("end-time is before start-time")
@@ -87,6 +134,10 @@ func RunGenerateReportCmdF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error while generating report: %w", err)
 	}
 
+	if format == string(report.FormatHTML) {
+		return report.WriteHTML(f, false, data)
+	}
+
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "  ")
 	err = enc.Encode(data)
@@ -116,10 +167,29 @@ func RunCompareReportCmdF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if err := report.Format(format).IsValid(); err != nil {
+		return err
+	}
+
+	var graphFormat report.GraphFormat
 	if genGraph {
-		if _, err := exec.LookPath("gnuplot"); err != nil {
-			return fmt.Errorf("gnuplot is not installed. The --graph option requires it to be installed: %w", err)
+		f, err := cmd.Flags().GetString("graph-format")
+		if err != nil {
+			return err
 		}
+		graphFormat = report.GraphFormat(f)
+		if err := graphFormat.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	significance, err := cmd.Flags().GetBool("significance")
+	if err != nil {
+		return err
 	}
 
 	file, err := cmd.Flags().GetString("output")
@@ -136,5 +206,45 @@ func RunCompareReportCmdF(cmd *cobra.Command, args []string) error {
 		defer target.Close()
 	}
 
-	return report.Compare(target, genGraph, reports...)
+	opts := report.CompareOpts{
+		Format:       report.Format(format),
+		GraphFormat:  graphFormat,
+		Significance: significance,
+	}
+	return report.Compare(target, opts, reports...)
+}
+
+func RunCheckReportCmdF(cmd *cobra.Command, args []string) error {
+	err := cobra.ExactArgs(2)(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	file, err := cmd.Flags().GetString("thresholds")
+	if err != nil {
+		return err
+	}
+	thresholds, err := report.LoadThresholds(file)
+	if err != nil {
+		return fmt.Errorf("error loading thresholds %s: %w", file, err)
+	}
+
+	base, err := report.Load(args[0])
+	if err != nil {
+		return fmt.Errorf("error loading report %s: %w", args[0], err)
+	}
+	candidate, err := report.Load(args[1])
+	if err != nil {
+		return fmt.Errorf("error loading report %s: %w", args[1], err)
+	}
+
+	violations := report.Check(base, candidate, thresholds)
+	if len(violations) == 0 {
+		fmt.Println("No performance regressions found.")
+		return nil
+	}
+
+	report.DisplayViolations(os.Stdout, violations)
+	cmd.SilenceUsage = true
+	return fmt.Errorf("found %d performance regressions", len(violations))
 }
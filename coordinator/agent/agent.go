@@ -8,11 +8,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 
 	"github.com/mattermost/mattermost-load-test-ng/api"
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/integcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
 
@@ -84,12 +87,46 @@ func (a *LoadAgent) RemoveUsers(n int) error {
 	return a.apiRequest(req)
 }
 
+func (a *LoadAgent) SetRatesDistribution(dist []loadtest.RatesDistribution) error {
+	data, err := json.Marshal(dist)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/loadagent/%s/rates", a.config.ApiURL, a.config.Id)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return a.apiRequest(req)
+}
+
+func (a *LoadAgent) Pause() error {
+	url := fmt.Sprintf("%s/loadagent/%s/pause", a.config.ApiURL, a.config.Id)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return err
+	}
+	return a.apiRequest(req)
+}
+
+func (a *LoadAgent) Resume() error {
+	url := fmt.Sprintf("%s/loadagent/%s/resume", a.config.ApiURL, a.config.Id)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return err
+	}
+	return a.apiRequest(req)
+}
+
 func (a *LoadAgent) Start() error {
 	a.config.LoadTestConfig.UsersConfiguration.InitialActiveUsers = 0
+	// The number of active users is driven by the coordinator.
+	a.config.LoadTestConfig.RampProfile.Phases = nil
 	var data = struct {
 		LoadTestConfig         loadtest.Config
 		SimpleControllerConfig *simplecontroller.Config `json:",omitempty"`
 		SimulControllerConfig  *simulcontroller.Config  `json:",omitempty"`
+		IntegControllerConfig  *integcontroller.Config  `json:",omitempty"`
 	}{
 		LoadTestConfig: a.config.LoadTestConfig,
 	}
@@ -104,6 +141,13 @@ func (a *LoadAgent) Start() error {
 		var scc *simulcontroller.Config
 		scc, err = simulcontroller.ReadConfig("")
 		data.SimulControllerConfig = scc
+	case loadtest.UserControllerIntegrations:
+		var icc *integcontroller.Config
+		icc, err = integcontroller.ReadConfig("")
+		if err == nil && icc.ReceiverURL == "" {
+			icc.ReceiverURL, err = receiverURL(a.config.ApiURL, icc.ReceiverListenAddress)
+		}
+		data.IntegControllerConfig = icc
 	}
 	if err != nil {
 		return err
@@ -156,3 +200,17 @@ func (a *LoadAgent) Stop() error {
 func (a *LoadAgent) Status() *loadtest.Status {
 	return a.status
 }
+
+// receiverURL returns the URL of the integrations receiver started by the
+// agent reachable at apiURL and listening on listenAddress.
+func receiverURL(apiURL, listenAddress string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", fmt.Errorf("agent: invalid api url: %w", err)
+	}
+	_, port, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		return "", fmt.Errorf("agent: invalid receiver listen address: %w", err)
+	}
+	return "http://" + net.JoinHostPort(u.Hostname(), port), nil
+}
@@ -154,8 +154,12 @@ func (c *LoadAgentCluster) DecrementUsers(n int) error {
 // Status returns the current status of the LoadAgentCluster.
 func (c *LoadAgentCluster) Status() Status {
 	var status Status
-	for _, agent := range c.agents {
+	for i, agent := range c.agents {
 		st := agent.Status()
+		status.Agents = append(status.Agents, AgentStatus{
+			Id:     c.config.Agents[i].Id,
+			Status: *st,
+		})
 		status.ActiveUsers += int(st.NumUsers)
 		currentError := st.NumErrors
 		errInfo := c.errMap[agent]
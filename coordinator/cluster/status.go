@@ -3,7 +3,18 @@
 
 package cluster
 
+import (
+	"github.com/mattermost/mattermost-load-test-ng/loadtest"
+)
+
 type Status struct {
 	ActiveUsers int
 	NumErrors   int64
+	Agents      []AgentStatus // The status of each load-test agent.
+}
+
+// AgentStatus holds the status of a single load-test agent.
+type AgentStatus struct {
+	Id     string
+	Status loadtest.Status
 }
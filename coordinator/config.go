@@ -26,7 +26,60 @@ type Config struct {
 	// The number of seconds to wait after a performance degradation alert before
 	// incrementing or decrementing users again.
 	RestTimeSec int `default:"10" validate:"range:(0,]"`
-	LogSettings logger.Settings
+	// Strategy configures the algorithm used by the feedback loop to search
+	// for the number of supported users.
+	Strategy StrategyConfig
+	// The path of the file where the results of the run are stored once
+	// the coordinator is done. If empty, the results are not stored.
+	ResultsFileLocation string `default:"ltcoordinator_results.json"`
+	LogSettings         logger.Settings
+}
+
+// StrategyConfig holds the configuration of the feedback loop's search
+// strategy.
+type StrategyConfig struct {
+	// The algorithm to use.
+	// Possible values:
+	//   StrategySlope - Increments and decrements users by NumUsersInc and
+	//   NumUsersDec until the number of active users stabilizes.
+	//   StrategyBinarySearch - Halves the interval of candidate user counts
+	//   after each probe.
+	//   StrategyPID - Drives the number of users through a PID controller.
+	Type StrategyType `default:"slope" validate:"oneof:{slope,binary_search,pid}"`
+	// The slope of the best fit line for the gathered samples under which
+	// the search is considered done. Used by the slope and pid strategies.
+	StopThreshold float64 `default:"0.1" validate:"range:(0,]"`
+	// The timespan in minutes of the samples used to calculate the best fit
+	// line. Used by the slope and pid strategies.
+	SamplesTimeRangeMin int                `default:"30" validate:"range:(0,]"`
+	BinarySearch        BinarySearchConfig // Used by the binary_search strategy.
+	PID                 PIDConfig          // Used by the pid strategy.
+}
+
+// BinarySearchConfig holds the configuration of the binary search strategy.
+type BinarySearchConfig struct {
+	// The number of seconds a probed number of users needs to run without
+	// alerts to be considered supported. It should be greater than RestTimeSec.
+	HoldTimeSec int `default:"120" validate:"range:(0,]"`
+	// The search is done when the distance between the highest supported
+	// and the lowest unsupported number of users is within this value.
+	PrecisionUsers int `default:"16" validate:"range:(0,]"`
+}
+
+// PIDConfig holds the configuration of the PID strategy. The error signal of
+// the controller is the relative distance of the value of a query from its
+// threshold, and its output is the target number of active users.
+type PIDConfig struct {
+	// The description of the query, as set in MonitorConfig.Queries, driving
+	// the controller. If empty, the first query with Alert set is used.
+	Query string
+	// The proportional gain, in users.
+	Kp float64 `default:"16" validate:"range:[0,]"`
+	// The integral gain, in users per second spent at the maximum distance
+	// below the threshold.
+	Ki float64 `default:"1" validate:"range:[0,]"`
+	// The derivative gain, in users times seconds.
+	Kd float64 `default:"0" validate:"range:[0,]"`
 }
 
 // ReadConfig reads the configuration file from the given string. If the string
@@ -5,9 +5,9 @@ package coordinator
 
 import (
 	"fmt"
-	"math"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,9 +22,21 @@ import (
 // Coordinator is the object used to coordinate a cluster of
 // load-test agents.
 type Coordinator struct {
-	config  *Config
-	cluster *cluster.LoadAgentCluster
-	monitor *performance.Monitor
+	config   *Config
+	ltConfig loadtest.Config
+	cluster  *cluster.LoadAgentCluster
+	monitor  *performance.Monitor
+	strategy Strategy
+	stopChan chan struct{}
+	// suspended is set while the feedback loop is paused or driven towards
+	// a fixed target. It's only accessed from the feedback loop.
+	suspended bool
+
+	// mut protects status and results which can be accessed while the
+	// coordinator is running.
+	mut     sync.RWMutex
+	status  Status
+	results Results
 }
 
 // Run starts a cluster of load-test agents.
@@ -34,6 +46,17 @@ func (c *Coordinator) Run() error {
 	interruptChannel := make(chan os.Signal, 1)
 	signal.Notify(interruptChannel, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	startTime := time.Now()
+	c.mut.Lock()
+	c.status.StartTime = startTime
+	c.results = Results{
+		StartTime:      startTime,
+		Config:         *c.config,
+		LoadTestConfig: c.ltConfig,
+	}
+	c.mut.Unlock()
+	defer c.saveResults()
+
 	defer c.cluster.Shutdown()
 	if err := c.cluster.Run(); err != nil {
 		mlog.Error("coordinator: running cluster failed", mlog.Err(err))
@@ -43,36 +66,10 @@ func (c *Coordinator) Run() error {
 	monitorChan := c.monitor.Run()
 	defer c.monitor.Stop()
 
-	var lastActionTime, lastAlertTime time.Time
-
-	// For now we are keeping all these values constant but in the future they
-	// might change based on the state of the feedback loop.
-	// Ideally we want the value of users to increment/decrement to react
-	// to the speed at which metrics are changing.
-
-	// The value of users to be incremented at each iteration.
-	// TODO: It should be proportional to the maximum number of users expected to test.
-	incValue := c.config.NumUsersInc
-	// The value of users to be decremented at each iteration.
-	// TODO: It should be proportional to the maximum number of users expected to test.
-	decValue := c.config.NumUsersDec
-	// The timespan to wait after a performance degradation alert before
-	// incrementing or decrementing users again.
-	restTime := time.Duration(c.config.RestTimeSec) * time.Second
-
-	// TODO: considering making the following values configurable.
-
-	// The threshold at which we consider the load-test done and we are ready to
-	// give an answer. The value represent the slope of the best fine line for
-	// the gathered samples. This value approaching zero means we have found
-	// an equilibrium point.
-	stopThreshold := 0.1
-	// The timespan to consider when calculating the best fit line. A higher
-	// value means considering a higher number of samples which improves the precision of
-	// the final result.
-	samplesTimeRange := 30 * time.Minute
-
-	var samples []point
+	c.setPhase(PhaseSearching)
+
+	// The change applied after the latest decision of the strategy.
+	var applied int
 
 	for {
 		var perfStatus performance.Status
@@ -81,59 +78,242 @@ func (c *Coordinator) Run() error {
 		case <-interruptChannel:
 			mlog.Info("coordinator: shutting down")
 			return nil
+		case <-c.stopChan:
+			mlog.Info("coordinator: stopped")
+			return nil
 		case perfStatus = <-monitorChan:
 		}
 
-		if perfStatus.Alert {
-			lastAlertTime = time.Now()
-		}
-
 		status := c.cluster.Status()
 		mlog.Info("coordinator: cluster status:", mlog.Int("active_users", status.ActiveUsers), mlog.Int64("errors", status.NumErrors))
+		logPerformanceStatus(perfStatus)
+
+		now := time.Now()
+		decision, fromStrategy := c.step(StrategyInput{
+			Time:        now,
+			ActiveUsers: status.ActiveUsers,
+			Alert:       perfStatus.Alert,
+			Applied:     applied,
+			Queries:     perfStatus.Queries,
+		})
+
+		var delta int
+		if !decision.Done {
+			delta = c.applyDelta(decision.Delta, status.ActiveUsers)
+		}
+		if fromStrategy {
+			applied = delta
+		}
+
+		c.mut.Lock()
+		c.status.ActiveUsers = status.ActiveUsers
+		c.status.NumErrors = status.NumErrors
+		c.status.MonitorStatus = perfStatus
+		c.results.addSample(Sample{
+			Time:        now,
+			ActiveUsers: status.ActiveUsers,
+			NumErrors:   status.NumErrors,
+			Alert:       perfStatus.Alert,
+			FiredRules:  perfStatus.FiredRules,
+			Queries:     perfStatus.Queries,
+			Delta:       delta,
+		})
+		c.results.Agents = status.Agents
+		c.results.Done = decision.Done
+		c.results.SupportedUsers = decision.SupportedUsers
+		c.mut.Unlock()
 
-		if !lastAlertTime.IsZero() {
-			samples = append(samples, point{
-				x: time.Now(),
-				y: status.ActiveUsers,
-			})
-			latest := getLatestSamples(samples, samplesTimeRange)
-			if len(latest) > 0 && len(latest) < len(samples) && math.Abs(slope(latest)) < stopThreshold {
-				mlog.Info("coordinator done!")
-				mlog.Info(fmt.Sprintf("estimated number of supported users is %f", math.Round(avg(latest))))
-				return nil
-			}
-			// We replace older samples which are not needed anymore.
-			if len(samples) >= 2*len(latest) {
-				copy(samples, latest)
-				samples = samples[:len(latest)]
-			}
+		if decision.Done {
+			mlog.Info("coordinator done!")
+			mlog.Info(fmt.Sprintf("estimated number of supported users is %d", decision.SupportedUsers))
+			return nil
 		}
+	}
+}
 
-		// We give the feedback loop some rest time in case of performance
-		// degradation alerts. We want metrics to stabilize before incrementing/decrementing users again.
-		if lastAlertTime.IsZero() || lastActionTime.IsZero() || hasPassed(lastActionTime, restTime) {
-			if perfStatus.Alert {
-				mlog.Info("coordinator: decrementing active users", mlog.Int("num_users", decValue))
-				if err := c.cluster.DecrementUsers(decValue); err != nil {
-					mlog.Error("coordinator: failed to decrement users", mlog.Err(err))
-				} else {
-					lastActionTime = time.Now()
-				}
-			} else if lastAlertTime.IsZero() || hasPassed(lastAlertTime, restTime) {
-				if status.ActiveUsers < c.config.ClusterConfig.MaxActiveUsers {
-					inc := min(incValue, c.config.ClusterConfig.MaxActiveUsers-status.ActiveUsers)
-					mlog.Info("coordinator: incrementing active users", mlog.Int("num_users", inc))
-					if err := c.cluster.IncrementUsers(inc); err != nil {
-						mlog.Error("coordinator: failed to increment users", mlog.Err(err))
-					} else {
-						lastActionTime = time.Now()
-					}
-				}
-			}
-		} else {
-			mlog.Info("coordinator: waiting for metrics to stabilize")
+// applyDelta adds or removes the given number of users to the cluster,
+// within the allowed range. It returns the change that was applied.
+func (c *Coordinator) applyDelta(delta, activeUsers int) int {
+	if delta < 0 {
+		dec := min(-delta, activeUsers)
+		if dec == 0 {
+			return 0
+		}
+		mlog.Info("coordinator: decrementing active users", mlog.Int("num_users", dec))
+		if err := c.cluster.DecrementUsers(dec); err != nil {
+			mlog.Error("coordinator: failed to decrement users", mlog.Err(err))
+			return 0
 		}
+		return -dec
+	} else if delta > 0 && activeUsers < c.config.ClusterConfig.MaxActiveUsers {
+		inc := min(delta, c.config.ClusterConfig.MaxActiveUsers-activeUsers)
+		mlog.Info("coordinator: incrementing active users", mlog.Int("num_users", inc))
+		if err := c.cluster.IncrementUsers(inc); err != nil {
+			mlog.Error("coordinator: failed to increment users", mlog.Err(err))
+			return 0
+		}
+		return inc
 	}
+	return 0
+}
+
+// logPerformanceStatus logs the values of the queries which led to the
+// given performance status.
+func logPerformanceStatus(status performance.Status) {
+	for _, q := range status.Queries {
+		if q.Error != "" {
+			// Failed queries are already reported by the monitor.
+			continue
+		}
+		mlog.Info("coordinator: performance query",
+			mlog.String("query_description", q.Description),
+			mlog.String("query_returned_value", fmt.Sprintf("%2.8f", q.Value)),
+			mlog.String("query_threshold", fmt.Sprintf("%2.8f", q.Threshold)),
+		)
+	}
+	if status.Alert {
+		mlog.Info("coordinator: performance degradation alert", mlog.Any("fired_rules", status.FiredRules))
+	}
+}
+
+// step decides how the number of active users should change according to
+// the current phase of the feedback loop. It also reports whether the
+// decision was taken by the strategy.
+func (c *Coordinator) step(in StrategyInput) (StrategyDecision, bool) {
+	c.mut.RLock()
+	phase := c.status.Phase
+	target := c.status.TargetUsers
+	c.mut.RUnlock()
+
+	switch phase {
+	case PhasePaused:
+		c.suspended = true
+		return StrategyDecision{}, false
+	case PhaseFixedTarget:
+		c.suspended = true
+		return StrategyDecision{Delta: target - in.ActiveUsers}, false
+	default:
+		if c.suspended {
+			c.strategy.Reset()
+			c.suspended = false
+		}
+		return c.strategy.Next(in), true
+	}
+}
+
+func (c *Coordinator) setPhase(phase Phase) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.status.Phase = phase
+	c.status.TargetUsers = 0
+}
+
+// Status returns information regarding the current state of the coordinator.
+func (c *Coordinator) Status() Status {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.status
+}
+
+// Stop makes the coordinator stop its feedback loop and shut down the
+// cluster of load-test agents. The results gathered so far are saved.
+// It returns an error if the coordinator is not running.
+func (c *Coordinator) Stop() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.status.isRunning() {
+		return ErrNotRunning
+	}
+
+	select {
+	case <-c.stopChan:
+	default:
+		close(c.stopChan)
+	}
+
+	return nil
+}
+
+// Pause suspends the feedback loop, keeping the current number of active
+// users until Resume or SetTarget are called.
+// It returns an error if the coordinator is not running.
+func (c *Coordinator) Pause() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.status.isRunning() {
+		return ErrNotRunning
+	}
+	c.status.Phase = PhasePaused
+	c.status.TargetUsers = 0
+
+	return nil
+}
+
+// Resume makes the feedback loop go back to searching for the number of
+// supported users after a call to Pause or SetTarget.
+// It returns an error if the coordinator is not running.
+func (c *Coordinator) Resume() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.status.isRunning() {
+		return ErrNotRunning
+	}
+	c.status.Phase = PhaseSearching
+	c.status.TargetUsers = 0
+
+	return nil
+}
+
+// SetTarget suspends the search for the number of supported users and makes
+// the feedback loop drive the cluster towards the given number of active
+// users until Resume is called.
+// It returns an error if the coordinator is not running or the target is out
+// of range.
+func (c *Coordinator) SetTarget(numUsers int) error {
+	if numUsers < 0 || numUsers > c.config.ClusterConfig.MaxActiveUsers {
+		return ErrInvalidTarget
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.status.isRunning() {
+		return ErrNotRunning
+	}
+	c.status.Phase = PhaseFixedTarget
+	c.status.TargetUsers = numUsers
+
+	return nil
+}
+
+// Results returns a snapshot of the results of the current run. It can be
+// called while the coordinator is running.
+func (c *Coordinator) Results() Results {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.results.copy()
+}
+
+// saveResults marks the end of the run and stores the results to the
+// configured file, if any.
+func (c *Coordinator) saveResults() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.status.Phase = PhaseDone
+	c.status.TargetUsers = 0
+	c.results.EndTime = time.Now()
+	if c.config.ResultsFileLocation == "" {
+		return
+	}
+	if err := c.results.writeToFile(c.config.ResultsFileLocation); err != nil {
+		mlog.Error("coordinator: failed to save results", mlog.Err(err))
+		return
+	}
+	mlog.Info("coordinator: results saved", mlog.String("path", c.config.ResultsFileLocation))
 }
 
 // New creates and initializes a new Coordinator for the given config.
@@ -152,14 +332,32 @@ func New(config *Config, ltConfig loadtest.Config) (*Coordinator, error) {
 		return nil, fmt.Errorf("coordinator: failed to create cluster: %w", err)
 	}
 
-	monitor, err := performance.NewMonitor(config.MonitorConfig)
+	agentURLs := make([]string, len(config.ClusterConfig.Agents))
+	for i, agent := range config.ClusterConfig.Agents {
+		agentURLs[i] = agent.ApiURL
+	}
+	source, err := performance.NewSource(config.MonitorConfig, agentURLs)
+	if err != nil {
+		return nil, fmt.Errorf("coordinator: failed to create metrics source: %w", err)
+	}
+
+	monitor, err := performance.NewMonitor(config.MonitorConfig, source)
 	if err != nil {
 		return nil, fmt.Errorf("coordinator: failed to create performance monitor: %w", err)
 	}
 
+	strategy, err := newStrategy(config)
+	if err != nil {
+		return nil, fmt.Errorf("coordinator: failed to create strategy: %w", err)
+	}
+
 	return &Coordinator{
-		config:  config,
-		cluster: cluster,
-		monitor: monitor,
+		config:   config,
+		ltConfig: ltConfig,
+		cluster:  cluster,
+		monitor:  monitor,
+		strategy: strategy,
+		stopChan: make(chan struct{}),
+		status:   Status{Phase: PhaseNotStarted},
 	}, nil
 }
@@ -0,0 +1,83 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fixedStrategy struct {
+	delta     int
+	numResets int
+}
+
+func (s *fixedStrategy) Next(in StrategyInput) StrategyDecision {
+	return StrategyDecision{Delta: s.delta}
+}
+
+func (s *fixedStrategy) Reset() {
+	s.numResets++
+}
+
+func newTestCoordinator(t *testing.T) *Coordinator {
+	config := newStrategyConfig(t, StrategySlope)
+	return &Coordinator{
+		config:   config,
+		strategy: &fixedStrategy{delta: 8},
+		stopChan: make(chan struct{}),
+		status:   Status{Phase: PhaseNotStarted},
+	}
+}
+
+func TestCoordinatorControl(t *testing.T) {
+	c := newTestCoordinator(t)
+	in := StrategyInput{Time: time.Now(), ActiveUsers: 100}
+
+	require.Equal(t, ErrNotRunning, c.Pause())
+	require.Equal(t, ErrNotRunning, c.Resume())
+	require.Equal(t, ErrNotRunning, c.SetTarget(10))
+	require.Equal(t, ErrNotRunning, c.Stop())
+
+	c.setPhase(PhaseSearching)
+	decision, fromStrategy := c.step(in)
+	require.Equal(t, 8, decision.Delta)
+	require.True(t, fromStrategy)
+	require.Zero(t, c.strategy.(*fixedStrategy).numResets)
+
+	require.NoError(t, c.Pause())
+	require.Equal(t, PhasePaused, c.Status().Phase)
+	decision, fromStrategy = c.step(in)
+	require.Zero(t, decision.Delta)
+	require.False(t, fromStrategy)
+
+	require.Equal(t, ErrInvalidTarget, c.SetTarget(-1))
+	require.Equal(t, ErrInvalidTarget, c.SetTarget(c.config.ClusterConfig.MaxActiveUsers+1))
+	require.NoError(t, c.SetTarget(40))
+	require.Equal(t, PhaseFixedTarget, c.Status().Phase)
+	require.Equal(t, 40, c.Status().TargetUsers)
+	decision, fromStrategy = c.step(in)
+	require.Equal(t, -60, decision.Delta)
+	require.False(t, fromStrategy)
+
+	require.NoError(t, c.Resume())
+	require.Equal(t, PhaseSearching, c.Status().Phase)
+	require.Zero(t, c.Status().TargetUsers)
+	decision, fromStrategy = c.step(in)
+	require.Equal(t, 8, decision.Delta)
+	require.True(t, fromStrategy)
+	require.Equal(t, 1, c.strategy.(*fixedStrategy).numResets)
+	c.step(in)
+	require.Equal(t, 1, c.strategy.(*fixedStrategy).numResets)
+
+	require.NoError(t, c.Stop())
+	require.NoError(t, c.Stop())
+	select {
+	case <-c.stopChan:
+	default:
+		require.FailNow(t, "stopChan should be closed")
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	requestTimeout = 5 * time.Second
+)
+
+// The queries supported by the Helper.
+const (
+	// The average time in seconds taken by client requests.
+	QueryRequestTimeAvg = "request_time_avg"
+	// The number of client errors per second.
+	QueryErrorsRate = "errors_rate"
+	// The number of client errors per second with a 5xx status code.
+	Query5xxErrorsRate = "5xx_errors_rate"
+	// The ratio of client requests resulting in an error.
+	QueryErrorsRatio = "errors_ratio"
+	// The number of client timeouts per second.
+	QueryTimeoutsRate = "timeouts_rate"
+)
+
+const (
+	metricRequestTime = "loadtest_http_request_time"
+	metricErrors      = "loadtest_http_errors_total"
+	metricTimeouts    = "loadtest_http_timeouts_total"
+)
+
+// ErrNoPreviousSample is returned when a query is run for the first time,
+// since its value is computed from the difference between two samples.
+var ErrNoPreviousSample = errors.New("agents: no previous sample to compare against")
+
+// IsValidQuery reports whether the given query is supported by the Helper.
+func IsValidQuery(query string) bool {
+	switch query {
+	case QueryRequestTimeAvg, QueryErrorsRate, Query5xxErrorsRate, QueryErrorsRatio, QueryTimeoutsRate:
+		return true
+	}
+	return false
+}
+
+// snapshot holds the client metrics of all the agents at a given time.
+type snapshot struct {
+	time        time.Time
+	requestSum  float64
+	requests    float64
+	errors      float64
+	errors5xx   float64
+	numTimeouts float64
+}
+
+// Helper computes performance signals from the metrics exposed by the
+// load-test agents on their /metrics endpoint.
+type Helper struct {
+	urls   []string
+	client *http.Client
+	mut    sync.Mutex
+	// The metrics gathered by the latest call to Update. Nil if it failed.
+	curr *snapshot
+	// The metrics each query is compared against.
+	last map[string]snapshot
+	// The queries run since the latest call to Update.
+	used map[string]bool
+}
+
+// NewHelper creates a helper scraping the metrics of the load-test agents
+// reachable at the given API URLs.
+func NewHelper(agentURLs []string) (*Helper, error) {
+	if len(agentURLs) == 0 {
+		return nil, errors.New("agents: agentURLs cannot be empty")
+	}
+	return &Helper{
+		urls:   agentURLs,
+		client: &http.Client{Timeout: requestTimeout},
+		last:   make(map[string]snapshot),
+		used:   make(map[string]bool),
+	}, nil
+}
+
+// Update gathers the metrics of all the agents. The values of the queries
+// run afterwards are computed from them, until the next call to Update.
+func (h *Helper) Update() error {
+	curr, err := h.scrape()
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	// The queries run since the previous update are now compared against
+	// the metrics they were computed from.
+	if h.curr != nil {
+		for query := range h.used {
+			h.last[query] = *h.curr
+		}
+	}
+	h.used = make(map[string]bool)
+	h.curr = nil
+
+	if err != nil {
+		return err
+	}
+	h.curr = &curr
+	return nil
+}
+
+// Value returns the value of the given query over the time between the
+// metrics the query was previously computed from and the ones gathered by
+// the latest call to Update. ErrNoPreviousSample is returned the first time
+// a query is run.
+func (h *Helper) Value(query string) (float64, error) {
+	if !IsValidQuery(query) {
+		return 0, fmt.Errorf("agents: unsupported query %q", query)
+	}
+
+	h.mut.Lock()
+	if h.curr == nil {
+		h.mut.Unlock()
+		return 0, errors.New("agents: no metrics available, Update failed or was not called")
+	}
+	curr := *h.curr
+	prev, ok := h.last[query]
+	h.used[query] = true
+	h.mut.Unlock()
+
+	if !ok {
+		return 0, ErrNoPreviousSample
+	}
+	if curr.requests < prev.requests || curr.requestSum < prev.requestSum || curr.errors < prev.errors ||
+		curr.errors5xx < prev.errors5xx || curr.numTimeouts < prev.numTimeouts {
+		// An agent was restarted and its counters were reset.
+		return 0, ErrNoPreviousSample
+	}
+
+	elapsed := curr.time.Sub(prev.time).Seconds()
+	requests := curr.requests - prev.requests
+
+	switch query {
+	case QueryRequestTimeAvg:
+		if requests == 0 {
+			return 0, nil
+		}
+		return (curr.requestSum - prev.requestSum) / requests, nil
+	case QueryErrorsRatio:
+		if requests == 0 {
+			return 0, nil
+		}
+		return (curr.errors - prev.errors) / requests, nil
+	case QueryErrorsRate:
+		return (curr.errors - prev.errors) / elapsed, nil
+	case Query5xxErrorsRate:
+		return (curr.errors5xx - prev.errors5xx) / elapsed, nil
+	default:
+		return (curr.numTimeouts - prev.numTimeouts) / elapsed, nil
+	}
+}
+
+// scrape gathers and sums up the metrics of all agents.
+func (h *Helper) scrape() (snapshot, error) {
+	s := snapshot{time: time.Now()}
+	for _, url := range h.urls {
+		families, err := h.fetch(url + "/metrics")
+		if err != nil {
+			return snapshot{}, fmt.Errorf("agents: failed to get metrics from %s: %w", url, err)
+		}
+
+		if mf, ok := families[metricRequestTime]; ok {
+			for _, m := range mf.GetMetric() {
+				s.requestSum += m.GetHistogram().GetSampleSum()
+				s.requests += float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+		if mf, ok := families[metricErrors]; ok {
+			for _, m := range mf.GetMetric() {
+				value := m.GetCounter().GetValue()
+				s.errors += value
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "status_code" && strings.HasPrefix(l.GetValue(), "5") {
+						s.errors5xx += value
+					}
+				}
+			}
+		}
+		if mf, ok := families[metricTimeouts]; ok {
+			for _, m := range mf.GetMetric() {
+				s.numTimeouts += m.GetCounter().GetValue()
+			}
+		}
+	}
+	return s, nil
+}
+
+func (h *Helper) fetch(url string) (map[string]*dto.MetricFamily, error) {
+	resp, err := h.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
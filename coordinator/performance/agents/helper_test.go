@@ -0,0 +1,118 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package agents
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/performance"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelperValue(t *testing.T) {
+	metrics := []*performance.Metrics{performance.NewMetrics(), performance.NewMetrics()}
+	var urls []string
+	var numScrapes int64
+	for _, m := range metrics {
+		handler := m.Handler()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&numScrapes, 1)
+			handler.ServeHTTP(w, r)
+		}))
+		defer srv.Close()
+		urls = append(urls, srv.URL)
+	}
+
+	_, err := NewHelper(nil)
+	require.Error(t, err)
+
+	h, err := NewHelper(urls)
+	require.NoError(t, err)
+
+	_, err = h.Value("unknown")
+	require.Error(t, err)
+	_, err = h.Value(QueryRequestTimeAvg)
+	require.Error(t, err)
+
+	require.NoError(t, h.Update())
+	queries := []string{QueryRequestTimeAvg, QueryErrorsRatio, QueryErrorsRate, Query5xxErrorsRate, QueryTimeoutsRate}
+	for _, q := range queries {
+		_, err := h.Value(q)
+		require.Equal(t, ErrNoPreviousSample, err)
+	}
+
+	for i, m := range metrics {
+		ue := m.UserEntityMetrics()
+		for j := 0; j < 4; j++ {
+			ue.HTTPRequestTimes.Observe(float64(i + 1))
+		}
+		ue.HTTPErrors.With(prometheus.Labels{"path": "/", "method": "GET", "status_code": "500"}).Inc()
+		ue.HTTPErrors.With(prometheus.Labels{"path": "/", "method": "GET", "status_code": "404"}).Inc()
+	}
+
+	require.NoError(t, h.Update())
+	value, err := h.Value(QueryRequestTimeAvg)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, value)
+
+	// Running the same query again before the next update gives the same value.
+	value, err = h.Value(QueryRequestTimeAvg)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, value)
+
+	value, err = h.Value(QueryErrorsRatio)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, value)
+
+	value, err = h.Value(QueryErrorsRate)
+	require.NoError(t, err)
+	require.Greater(t, value, 0.0)
+
+	value, err = h.Value(Query5xxErrorsRate)
+	require.NoError(t, err)
+	require.Greater(t, value, 0.0)
+
+	value, err = h.Value(QueryTimeoutsRate)
+	require.NoError(t, err)
+	require.Zero(t, value)
+
+	// Agents are only scraped once per update, regardless of the number of
+	// queries.
+	require.EqualValues(t, 2*len(metrics), atomic.LoadInt64(&numScrapes))
+
+	// No new requests since the last sample.
+	require.NoError(t, h.Update())
+	value, err = h.Value(QueryRequestTimeAvg)
+	require.NoError(t, err)
+	require.Zero(t, value)
+
+	// The values are unavailable until the agents can be scraped again.
+	h.urls = append(h.urls, "http://localhost:0")
+	require.Error(t, h.Update())
+	_, err = h.Value(QueryRequestTimeAvg)
+	require.Error(t, err)
+}
+
+func TestHelperCounterReset(t *testing.T) {
+	h, err := NewHelper([]string{"http://localhost"})
+	require.NoError(t, err)
+
+	prev := snapshot{time: time.Unix(0, 0), requests: 10, requestSum: 5, errors: 4, errors5xx: 2}
+	for _, curr := range []snapshot{
+		{requests: 10, requestSum: 4, errors: 4, errors5xx: 2},
+		{requests: 10, requestSum: 5, errors: 4, errors5xx: 1},
+	} {
+		curr.time = time.Unix(1, 0)
+		h.last[QueryRequestTimeAvg] = prev
+		h.curr = &curr
+		_, err := h.Value(QueryRequestTimeAvg)
+		require.Equal(t, ErrNoPreviousSample, err)
+	}
+}
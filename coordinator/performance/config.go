@@ -6,23 +6,31 @@ package performance
 import (
 	"fmt"
 
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/agents"
 	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
 )
 
 // MonitorConfig holds the necessary information to create a Monitor.
 type MonitorConfig struct {
-	// The URL of the Prometheus server to query.
+	// The backend providing the metrics to query.
+	Backend BackendType `default:"prometheus" validate:"oneof:{prometheus,agents}"`
+	// The URL of the Prometheus server to query. Only used by the prometheus
+	// backend.
 	PrometheusURL string `default:"http://localhost:9090" validate:"url"`
 	// The time interval in milliseconds to wait before querying again.
 	UpdateIntervalMs int `default:"2000" validate:"range:[1000,]"`
 	// The slice of queries to run.
 	Queries []prometheus.Query `default_size:"1"`
+	// The rules deciding when to fire a performance degradation alert based
+	// on the values returned by the queries. If empty, an alert is fired as
+	// soon as any query with Alert set goes above its Threshold.
+	Rules []AlertRule
 }
 
 // IsValid checks whether a MonitorConfig is valid or not.
 // Returns an error if the validation fails.
 func (c MonitorConfig) IsValid() error {
-	if c.PrometheusURL == "" {
+	if c.Backend == BackendPrometheus && c.PrometheusURL == "" {
 		return fmt.Errorf("PrometheusURL cannot be empty")
 	}
 	if c.UpdateIntervalMs < 1000 {
@@ -31,5 +39,27 @@ func (c MonitorConfig) IsValid() error {
 	if len(c.Queries) == 0 {
 		return fmt.Errorf("Queries cannot be empty")
 	}
+
+	queries := make(map[string]bool, len(c.Queries))
+	for _, q := range c.Queries {
+		if queries[q.Description] {
+			return fmt.Errorf("duplicate query %q", q.Description)
+		}
+		queries[q.Description] = true
+		if c.Backend == BackendAgents && !agents.IsValidQuery(q.Query) {
+			return fmt.Errorf("query %q is not supported by the agents backend", q.Query)
+		}
+	}
+	for _, r := range c.Rules {
+		if err := r.IsValid(); err != nil {
+			return err
+		}
+		for _, cond := range r.Conditions {
+			if !queries[cond.Query] {
+				return fmt.Errorf("rule %q: unknown query %q", r.Name, cond.Query)
+			}
+		}
+	}
+
 	return nil
 }
@@ -7,30 +7,30 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
-
 	"github.com/mattermost/mattermost-server/v5/mlog"
 )
 
 type Monitor struct {
 	config     MonitorConfig
-	helper     *prometheus.Helper
+	source     Source
+	rules      []*rule
 	stopChan   chan struct{}
 	statusChan chan Status
 }
 
-// NewMonitor creates and initializes a new Monitor.
-func NewMonitor(config MonitorConfig) (*Monitor, error) {
+// NewMonitor creates and initializes a new Monitor running its queries
+// against the given source.
+func NewMonitor(config MonitorConfig, source Source) (*Monitor, error) {
 	if err := config.IsValid(); err != nil {
 		return nil, fmt.Errorf("could not validate configuration: %w", err)
 	}
-	helper, err := prometheus.NewHelper(config.PrometheusURL)
-	if err != nil {
-		return nil, fmt.Errorf("performance: failed to create prometheus.Helper: %w", err)
+	if source == nil {
+		return nil, fmt.Errorf("performance: source should not be nil")
 	}
 	return &Monitor{
 		config:     config,
-		helper:     helper,
+		source:     source,
+		rules:      newRules(config),
 		stopChan:   make(chan struct{}),
 		statusChan: make(chan Status),
 	}, nil
@@ -60,7 +60,12 @@ func (m *Monitor) Stop() {
 }
 
 func (m *Monitor) runQueries() Status {
-	var status Status
+	results := make([]QueryResult, 0, len(m.config.Queries))
+	values := make(map[string]float64, len(m.config.Queries))
+	updateErr := m.source.Update()
+	if updateErr != nil {
+		mlog.Warn("monitor: error while updating source:", mlog.Err(updateErr))
+	}
 	for _, query := range m.config.Queries {
 		select {
 		case <-m.stopChan:
@@ -68,9 +73,21 @@ func (m *Monitor) runQueries() Status {
 			return Status{}
 		default:
 		}
-		value, err := m.helper.VectorFirst(query.Query)
+		result := QueryResult{
+			Description: query.Description,
+			Threshold:   query.Threshold,
+			Time:        time.Now(),
+		}
+		if updateErr != nil {
+			result.Error = updateErr.Error()
+			results = append(results, result)
+			continue
+		}
+		value, err := m.source.Value(query.Query)
 		if err != nil {
-			mlog.Warn("monitor: error while querying Prometheus:", mlog.String("query_description", query.Description), mlog.Err(err))
+			mlog.Warn("monitor: error while running query:", mlog.String("query_description", query.Description), mlog.Err(err))
+			result.Error = err.Error()
+			results = append(results, result)
 			continue
 		}
 		mlog.Debug("monitor: ran query",
@@ -78,14 +95,25 @@ func (m *Monitor) runQueries() Status {
 			mlog.String("query_returned_value", fmt.Sprintf("%2.8f", value)),
 			mlog.String("query_threshold", fmt.Sprintf("%2.8f", query.Threshold)),
 		)
-		if query.Alert && value >= query.Threshold {
-			mlog.Warn("monitor: returned value is above the threshold",
-				mlog.String("query_description", query.Description),
-				mlog.String("query_returned_value", fmt.Sprintf("%2.8f", value)),
-				mlog.String("query_threshold", fmt.Sprintf("%2.8f", query.Threshold)),
-			)
-			status = Status{Alert: true}
-			break
+		result.Value = value
+		results = append(results, result)
+		values[query.Description] = value
+	}
+
+	status := m.evaluateRules(values)
+	status.Queries = results
+	return status
+}
+
+// evaluateRules updates the state of the rules with the latest query values
+// and returns the resulting status.
+func (m *Monitor) evaluateRules(values map[string]float64) Status {
+	var status Status
+	for _, r := range m.rules {
+		if r.update(values) {
+			mlog.Warn("monitor: alert rule is firing", mlog.String("rule", r.Name))
+			status.Alert = true
+			status.FiredRules = append(status.FiredRules, r.Name)
 		}
 	}
 	return status
@@ -0,0 +1,163 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package performance
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
+)
+
+// RuleOperator determines how the conditions of an AlertRule are combined.
+type RuleOperator string
+
+// Available rule operators.
+const (
+	RuleOperatorAnd RuleOperator = "and"
+	RuleOperatorOr  RuleOperator = "or"
+)
+
+// BoundType determines which side of the threshold a RuleCondition matches.
+type BoundType string
+
+// Available bound types.
+const (
+	// The condition matches when the value is greater than or equal to the
+	// threshold.
+	BoundUpper BoundType = "upper"
+	// The condition matches when the value is less than or equal to the
+	// threshold.
+	BoundLower BoundType = "lower"
+)
+
+// RuleCondition is a comparison between the latest value of a query and a
+// threshold.
+type RuleCondition struct {
+	// The Description of the query, as set in MonitorConfig.Queries.
+	Query string `validate:"notempty"`
+	// The side of the threshold on which the condition matches.
+	Bound BoundType `default:"upper" validate:"oneof:{upper,lower}"`
+	// The value the query result is compared against.
+	Threshold float64
+}
+
+// AlertRule combines one or more conditions which need to match for a
+// number of consecutive samples in order to fire a performance degradation
+// alert.
+type AlertRule struct {
+	// The name identifying the rule.
+	Name string `validate:"notempty"`
+	// How the conditions are combined.
+	Operator RuleOperator `default:"and" validate:"oneof:{and,or}"`
+	// The conditions to evaluate.
+	Conditions []RuleCondition `default_size:"1"`
+	// The number of consecutive samples the conditions need to match for
+	// the rule to fire.
+	ForSamples int `default:"1" validate:"range:[1,]"`
+	// The number of consecutive samples the conditions need to not match
+	// for a fired rule to clear.
+	ClearSamples int `default:"1" validate:"range:[1,]"`
+}
+
+// IsValid reports whether a given AlertRule is valid or not.
+// Returns an error if the validation fails.
+func (r AlertRule) IsValid() error {
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("rule %q: Conditions cannot be empty", r.Name)
+	}
+	return nil
+}
+
+// match reports whether the condition matches the given values. A
+// condition on a query without a value never matches.
+func (c RuleCondition) match(values map[string]float64) bool {
+	value, ok := values[c.Query]
+	if !ok {
+		return false
+	}
+	if c.Bound == BoundLower {
+		return value <= c.Threshold
+	}
+	return value >= c.Threshold
+}
+
+// rule keeps track of the evaluation of an AlertRule across samples.
+type rule struct {
+	AlertRule
+	matchCount int
+	clearCount int
+	firing     bool
+}
+
+// match reports whether the rule's conditions match the given values.
+func (r *rule) match(values map[string]float64) bool {
+	for _, c := range r.Conditions {
+		matched := c.match(values)
+		if r.Operator == RuleOperatorOr && matched {
+			return true
+		}
+		if r.Operator != RuleOperatorOr && !matched {
+			return false
+		}
+	}
+	return r.Operator != RuleOperatorOr
+}
+
+// update evaluates the rule against a new sample and reports whether the
+// rule is firing.
+func (r *rule) update(values map[string]float64) bool {
+	if r.match(values) {
+		r.matchCount++
+		r.clearCount = 0
+		if r.matchCount >= r.ForSamples {
+			r.firing = true
+		}
+	} else {
+		r.clearCount++
+		r.matchCount = 0
+		if r.clearCount >= r.ClearSamples {
+			r.firing = false
+		}
+	}
+	return r.firing
+}
+
+// newRules creates the rules to evaluate from the given config. If no rules
+// are configured, a rule firing at the first sample over the threshold is
+// created for each query with Alert set.
+func newRules(config MonitorConfig) []*rule {
+	alertRules := config.Rules
+	if len(alertRules) == 0 {
+		alertRules = rulesFromQueries(config.Queries)
+	}
+
+	rules := make([]*rule, len(alertRules))
+	for i := range alertRules {
+		rules[i] = &rule{AlertRule: alertRules[i]}
+	}
+	return rules
+}
+
+func rulesFromQueries(queries []prometheus.Query) []AlertRule {
+	var rules []AlertRule
+	for _, q := range queries {
+		if !q.Alert {
+			continue
+		}
+		rules = append(rules, AlertRule{
+			Name:     q.Description,
+			Operator: RuleOperatorAnd,
+			Conditions: []RuleCondition{
+				{
+					Query:     q.Description,
+					Bound:     BoundUpper,
+					Threshold: q.Threshold,
+				},
+			},
+			ForSamples:   1,
+			ClearSamples: 1,
+		})
+	}
+	return rules
+}
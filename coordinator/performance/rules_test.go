@@ -0,0 +1,138 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package performance
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/agents"
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatch(t *testing.T) {
+	conditions := []RuleCondition{
+		{Query: "latency", Bound: BoundUpper, Threshold: 0.5},
+		{Query: "throughput", Bound: BoundLower, Threshold: 100},
+	}
+
+	tcs := []struct {
+		name     string
+		operator RuleOperator
+		values   map[string]float64
+		expected bool
+	}{
+		{"and none", RuleOperatorAnd, map[string]float64{"latency": 0.1, "throughput": 200}, false},
+		{"and one", RuleOperatorAnd, map[string]float64{"latency": 0.5, "throughput": 200}, false},
+		{"and both", RuleOperatorAnd, map[string]float64{"latency": 0.6, "throughput": 100}, true},
+		{"and missing", RuleOperatorAnd, map[string]float64{"latency": 0.6}, false},
+		{"or none", RuleOperatorOr, map[string]float64{"latency": 0.1, "throughput": 200}, false},
+		{"or upper", RuleOperatorOr, map[string]float64{"latency": 0.5, "throughput": 200}, true},
+		{"or lower", RuleOperatorOr, map[string]float64{"latency": 0.1, "throughput": 50}, true},
+		{"or missing", RuleOperatorOr, map[string]float64{}, false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &rule{AlertRule: AlertRule{
+				Name:       "rule",
+				Operator:   tc.operator,
+				Conditions: conditions,
+			}}
+			require.Equal(t, tc.expected, r.match(tc.values))
+		})
+	}
+}
+
+func TestRuleHysteresis(t *testing.T) {
+	r := &rule{AlertRule: AlertRule{
+		Name:         "rule",
+		Operator:     RuleOperatorAnd,
+		Conditions:   []RuleCondition{{Query: "latency", Bound: BoundUpper, Threshold: 0.5}},
+		ForSamples:   3,
+		ClearSamples: 2,
+	}}
+
+	high := map[string]float64{"latency": 1}
+	low := map[string]float64{"latency": 0.1}
+
+	// A single noisy sample should not fire the rule.
+	require.False(t, r.update(high))
+	require.False(t, r.update(low))
+
+	require.False(t, r.update(high))
+	require.False(t, r.update(high))
+	require.True(t, r.update(high))
+
+	// A single good sample should not clear the rule.
+	require.True(t, r.update(low))
+	require.True(t, r.update(high))
+	require.True(t, r.update(low))
+	require.False(t, r.update(low))
+}
+
+func TestEvaluateRules(t *testing.T) {
+	m := &Monitor{
+		rules: newRules(MonitorConfig{
+			Queries: []prometheus.Query{
+				{Description: "latency", Threshold: 0.5, Alert: true},
+				{Description: "errors", Threshold: 1, Alert: true},
+				{Description: "info", Threshold: 1},
+			},
+		}),
+	}
+	require.Len(t, m.rules, 2)
+
+	status := m.evaluateRules(map[string]float64{"latency": 0.1, "errors": 0, "info": 5})
+	require.False(t, status.Alert)
+	require.Empty(t, status.FiredRules)
+
+	status = m.evaluateRules(map[string]float64{"latency": 0.6, "errors": 2})
+	require.True(t, status.Alert)
+	require.Equal(t, []string{"latency", "errors"}, status.FiredRules)
+}
+
+func TestMonitorConfigIsValid(t *testing.T) {
+	config := MonitorConfig{
+		Backend:          BackendPrometheus,
+		PrometheusURL:    "http://localhost:9090",
+		UpdateIntervalMs: 1000,
+		Queries: []prometheus.Query{
+			{Description: "latency", Query: "latency_query", Threshold: 0.5},
+		},
+		Rules: []AlertRule{
+			{
+				Name:       "rule",
+				Operator:   RuleOperatorAnd,
+				Conditions: []RuleCondition{{Query: "latency", Bound: BoundUpper}},
+			},
+		},
+	}
+	require.NoError(t, config.IsValid())
+
+	config.Rules[0].Conditions[0].Query = "unknown"
+	require.Error(t, config.IsValid())
+
+	config.Rules[0].Conditions = nil
+	require.Error(t, config.IsValid())
+
+	config.Rules = nil
+	config.Backend = BackendAgents
+	require.Error(t, config.IsValid())
+	config.Queries[0].Query = agents.QueryRequestTimeAvg
+	require.NoError(t, config.IsValid())
+
+	config.Queries = append(config.Queries, config.Queries[0])
+	require.Error(t, config.IsValid())
+}
+
+func TestAlertRuleDefaults(t *testing.T) {
+	var r AlertRule
+	require.NoError(t, defaults.Set(&r))
+	require.Len(t, r.Conditions, 1)
+	require.Equal(t, BoundUpper, r.Conditions[0].Bound)
+	require.Equal(t, RuleOperatorAnd, r.Operator)
+}
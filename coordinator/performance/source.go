@@ -0,0 +1,67 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package performance
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/agents"
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
+)
+
+// BackendType identifies the source of the metrics queried by the Monitor.
+type BackendType string
+
+// Available metrics backends.
+const (
+	// Queries are PromQL expressions run against a Prometheus server.
+	BackendPrometheus BackendType = "prometheus"
+	// Queries are computed from the client-side metrics exposed by the
+	// load-test agents.
+	BackendAgents BackendType = "agents"
+)
+
+// Source provides the values of the queries run by the Monitor.
+type Source interface {
+	// Update gathers the data the queries are computed from. It's called
+	// once before running the queries at each update of the Monitor.
+	Update() error
+	// Value returns the current value of the given query.
+	Value(query string) (float64, error)
+}
+
+type prometheusSource struct {
+	helper *prometheus.Helper
+}
+
+// Update is a no-op since each query is run against the Prometheus server.
+func (s *prometheusSource) Update() error {
+	return nil
+}
+
+func (s *prometheusSource) Value(query string) (float64, error) {
+	return s.helper.VectorFirst(query)
+}
+
+// NewSource creates the Source for the backend set in the given config.
+// The agentURLs parameter holds the API URLs of the load-test agents and is
+// only used by the agents backend.
+func NewSource(config MonitorConfig, agentURLs []string) (Source, error) {
+	switch config.Backend {
+	case BackendPrometheus:
+		helper, err := prometheus.NewHelper(config.PrometheusURL)
+		if err != nil {
+			return nil, fmt.Errorf("performance: failed to create prometheus.Helper: %w", err)
+		}
+		return &prometheusSource{helper}, nil
+	case BackendAgents:
+		helper, err := agents.NewHelper(agentURLs)
+		if err != nil {
+			return nil, fmt.Errorf("performance: failed to create agents.Helper: %w", err)
+		}
+		return helper, nil
+	default:
+		return nil, fmt.Errorf("performance: unknown backend %q", config.Backend)
+	}
+}
@@ -3,9 +3,26 @@
 
 package performance
 
+import (
+	"time"
+)
+
+// QueryResult holds the outcome of a single query run by the Monitor.
+type QueryResult struct {
+	Description string    // The description of the query.
+	Value       float64   // The value returned by the query. Zero if Error is set.
+	Threshold   float64   // The threshold configured for the query.
+	Time        time.Time // The time at which the query was run.
+	Error       string    `json:",omitempty"` // Set if the query failed.
+}
+
 // Status is a structure containing information on the performance status
 // of the target instance.
 type Status struct {
 	// A boolean value indicating if performance degradation occurred.
 	Alert bool
+	// The names of the rules which are firing.
+	FiredRules []string `json:",omitempty"`
+	// The results of the queries used to compute the status.
+	Queries []QueryResult `json:",omitempty"`
 }
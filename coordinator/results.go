@@ -0,0 +1,119 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/cluster"
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest"
+)
+
+// Results holds the outcome of a coordinator run in a machine readable format.
+type Results struct {
+	StartTime time.Time // Time when the coordinator started.
+	EndTime   time.Time // Time when the coordinator finished. Zero while running.
+	// Done is set if the feedback loop converged to an estimate.
+	Done bool
+	// The estimated number of supported users. Only set if Done is true.
+	SupportedUsers int
+	// The samples gathered at each iteration of the feedback loop.
+	Samples []Sample
+	// The timeline of the performance degradation alerts.
+	Alerts []AlertPeriod
+	// The latest status of each load-test agent.
+	Agents []cluster.AgentStatus
+	// The configuration used to run the coordinator.
+	Config Config
+	// The configuration used to run the load-test agents. The admin password
+	// is left out.
+	LoadTestConfig loadtest.Config
+}
+
+// Sample holds the state of the cluster at a single iteration of the
+// feedback loop.
+type Sample struct {
+	Time        time.Time
+	ActiveUsers int
+	NumErrors   int64
+	Alert       bool
+	// The names of the alert rules which were firing.
+	FiredRules []string `json:",omitempty"`
+	// The results of the performance monitor queries.
+	Queries []performance.QueryResult `json:",omitempty"`
+	// The number of users added (if positive) or removed (if negative) as
+	// a consequence of this sample.
+	Delta int
+}
+
+// AlertPeriod is a timespan during which the performance monitor kept
+// reporting a degradation.
+type AlertPeriod struct {
+	StartTime   time.Time
+	EndTime     time.Time // Zero if the alert is still ongoing.
+	ActiveUsers int       // Number of active users when the alert started.
+}
+
+// addSample records a new sample, updating the alert timeline accordingly.
+func (r *Results) addSample(s Sample) {
+	r.Samples = append(r.Samples, s)
+
+	ongoing := len(r.Alerts) > 0 && r.Alerts[len(r.Alerts)-1].EndTime.IsZero()
+	if s.Alert && !ongoing {
+		r.Alerts = append(r.Alerts, AlertPeriod{
+			StartTime:   s.Time,
+			ActiveUsers: s.ActiveUsers,
+		})
+	} else if !s.Alert && ongoing {
+		r.Alerts[len(r.Alerts)-1].EndTime = s.Time
+	}
+}
+
+// copy returns a deep copy of the results. Credentials are redacted since
+// the copy is meant to be shared.
+func (r *Results) copy() Results {
+	res := *r
+	res.Samples = append([]Sample(nil), r.Samples...)
+	res.Alerts = append([]AlertPeriod(nil), r.Alerts...)
+	res.Agents = append([]cluster.AgentStatus(nil), r.Agents...)
+	res.LoadTestConfig.ConnectionConfiguration.AdminPassword = ""
+	return res
+}
+
+// writeToFile stores the results as JSON at the given path, redacting
+// credentials.
+func (r *Results) writeToFile(path string) error {
+	res := r.copy()
+	data, err := json.MarshalIndent(&res, "", "  ")
+	if err != nil {
+		return fmt.Errorf("coordinator: failed to marshal results: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("coordinator: failed to write results: %w", err)
+	}
+	return nil
+}
+
+// TimeRange returns the time window covered by a finished run, trimmed by
+// trimStart at the beginning and trimEnd at the end to leave out the
+// warm-up and cool-down periods.
+func (r Results) TimeRange(trimStart, trimEnd time.Duration) (time.Time, time.Time, error) {
+	if r.StartTime.IsZero() || r.EndTime.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("coordinator: run has not finished")
+	}
+	if trimStart < 0 || trimEnd < 0 {
+		return time.Time{}, time.Time{}, errors.New("coordinator: trim durations cannot be negative")
+	}
+	start := r.StartTime.Add(trimStart)
+	end := r.EndTime.Add(-trimEnd)
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("coordinator: trimmed time range is empty, run lasted %s", r.EndTime.Sub(r.StartTime))
+	}
+	return start, end, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package coordinator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsAddSample(t *testing.T) {
+	var r Results
+	alerts := []bool{false, true, true, false, false, true}
+	for i, alert := range alerts {
+		r.addSample(Sample{
+			Time:        time.Unix(int64(i), 0),
+			ActiveUsers: i * 10,
+			Alert:       alert,
+		})
+	}
+
+	require.Len(t, r.Samples, len(alerts))
+	require.Equal(t, []AlertPeriod{
+		{StartTime: time.Unix(1, 0), EndTime: time.Unix(3, 0), ActiveUsers: 10},
+		{StartTime: time.Unix(5, 0), ActiveUsers: 50},
+	}, r.Alerts)
+
+	cp := r.copy()
+	cp.Alerts[1].EndTime = time.Unix(6, 0)
+	require.True(t, r.Alerts[1].EndTime.IsZero())
+}
+
+func TestResultsWriteToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := Results{
+		StartTime:      time.Unix(0, 0).UTC(),
+		EndTime:        time.Unix(60, 0).UTC(),
+		Done:           true,
+		SupportedUsers: 100,
+	}
+	r.addSample(Sample{
+		Time:        time.Unix(30, 0).UTC(),
+		ActiveUsers: 100,
+		Alert:       true,
+		FiredRules:  []string{"latency"},
+		Queries: []performance.QueryResult{
+			{Description: "latency", Value: 0.5, Threshold: 0.2, Time: time.Unix(30, 0).UTC()},
+		},
+		Delta: -8,
+	})
+
+	path := filepath.Join(dir, "results.json")
+	require.NoError(t, r.writeToFile(path))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var res Results
+	require.NoError(t, json.Unmarshal(data, &res))
+	require.Equal(t, r, res)
+
+	require.Error(t, r.writeToFile(filepath.Join(dir, "missing", "results.json")))
+}
+
+func TestResultsRedactCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	const password = "Sys@dmin-secret1"
+	r := Results{StartTime: time.Unix(0, 0).UTC()}
+	r.LoadTestConfig.ConnectionConfiguration.AdminEmail = "sysadmin@example.com"
+	r.LoadTestConfig.ConnectionConfiguration.AdminPassword = password
+
+	data, err := json.Marshal(r.copy())
+	require.NoError(t, err)
+	require.NotContains(t, string(data), password)
+	require.Contains(t, string(data), "sysadmin@example.com")
+
+	path := filepath.Join(dir, "results.json")
+	require.NoError(t, r.writeToFile(path))
+	data, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), password)
+
+	// The results kept by the coordinator are left untouched.
+	require.Equal(t, password, r.LoadTestConfig.ConnectionConfiguration.AdminPassword)
+}
+
+func TestResultsTimeRange(t *testing.T) {
+	start := time.Date(2020, 6, 17, 4, 37, 0, 0, time.UTC)
+	end := start.Add(30 * time.Minute)
+
+	t.Run("running", func(t *testing.T) {
+		_, _, err := Results{StartTime: start}.TimeRange(0, 0)
+		require.Error(t, err)
+	})
+
+	t.Run("full", func(t *testing.T) {
+		s, e, err := Results{StartTime: start, EndTime: end}.TimeRange(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, start, s)
+		require.Equal(t, end, e)
+	})
+
+	t.Run("trimmed", func(t *testing.T) {
+		s, e, err := Results{StartTime: start, EndTime: end}.TimeRange(5*time.Minute, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, start.Add(5*time.Minute), s)
+		require.Equal(t, end.Add(-time.Minute), e)
+	})
+
+	t.Run("invalid trim", func(t *testing.T) {
+		_, _, err := Results{StartTime: start, EndTime: end}.TimeRange(20*time.Minute, 10*time.Minute)
+		require.Error(t, err)
+		_, _, err = Results{StartTime: start, EndTime: end}.TimeRange(-time.Minute, 0)
+		require.Error(t, err)
+	})
+}
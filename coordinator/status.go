@@ -0,0 +1,52 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package coordinator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance"
+)
+
+// Phase determines what the coordinator's feedback loop is doing.
+type Phase string
+
+// Different possible phases of the coordinator.
+const (
+	// The coordinator has not started yet.
+	PhaseNotStarted Phase = "not_started"
+	// The feedback loop is searching for the number of supported users
+	// through the configured strategy.
+	PhaseSearching Phase = "searching"
+	// The feedback loop is paused and the number of users is kept as is.
+	PhasePaused Phase = "paused"
+	// The feedback loop drives the number of users towards a fixed target.
+	PhaseFixedTarget Phase = "fixed_target"
+	// The coordinator has finished.
+	PhaseDone Phase = "done"
+)
+
+var (
+	ErrNotRunning    = errors.New("coordinator is not running")
+	ErrInvalidTarget = errors.New("target users should be >= 0 and <= MaxActiveUsers")
+)
+
+// Status contains information about the coordinator's current state.
+type Status struct {
+	Phase       Phase     // The current phase of the feedback loop.
+	StartTime   time.Time // Time when the coordinator started.
+	ActiveUsers int       // Number of active users in the cluster.
+	NumErrors   int64     // Number of errors reported by the cluster.
+	// The number of users forced through SetTarget. Only set in the
+	// PhaseFixedTarget phase.
+	TargetUsers int `json:",omitempty"`
+	// The latest status reported by the performance monitor.
+	MonitorStatus performance.Status
+}
+
+// isRunning reports whether the feedback loop is running.
+func (s Status) isRunning() bool {
+	return s.Phase == PhaseSearching || s.Phase == PhasePaused || s.Phase == PhaseFixedTarget
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package coordinator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance"
+
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+// StrategyType identifies the algorithm used by the feedback loop to search
+// for the number of users supported by the target instance.
+type StrategyType string
+
+// Available strategies.
+const (
+	StrategySlope        StrategyType = "slope"
+	StrategyBinarySearch StrategyType = "binary_search"
+	StrategyPID          StrategyType = "pid"
+)
+
+// StrategyInput holds the information gathered at each iteration of the
+// feedback loop.
+type StrategyInput struct {
+	Time        time.Time // Time at which the information was gathered.
+	ActiveUsers int       // Number of active users in the cluster.
+	Alert       bool      // Whether the performance monitor detected a degradation.
+	// The change in the number of active users that was actually applied
+	// after the previous decision of the strategy. It's zero if the decision
+	// had no effect, for example because updating the cluster failed.
+	Applied int
+	// The results of the performance monitor queries.
+	Queries []performance.QueryResult
+}
+
+// StrategyDecision is the outcome of a single iteration of a Strategy.
+type StrategyDecision struct {
+	// The number of users to add (if positive) or remove (if negative).
+	Delta int
+	// Done is set when the search has converged.
+	Done bool
+	// The estimated number of supported users. It's only set if Done is true.
+	SupportedUsers int
+}
+
+// Strategy is an algorithm driving the feedback loop in search of the
+// maximum number of users the target instance can support.
+type Strategy interface {
+	// Next is called at each iteration of the feedback loop and decides how
+	// the number of active users should change.
+	Next(in StrategyInput) StrategyDecision
+	// Reset is called before the feedback loop goes back to calling Next
+	// after having been paused or driven towards a fixed target, so that the
+	// time spent meanwhile doesn't affect the strategy.
+	Reset()
+}
+
+// newStrategy creates the Strategy configured in the given config.
+func newStrategy(config *Config) (Strategy, error) {
+	maxUsers := config.ClusterConfig.MaxActiveUsers
+	restTime := time.Duration(config.RestTimeSec) * time.Second
+	conv := convergence{
+		threshold: config.Strategy.StopThreshold,
+		timeRange: time.Duration(config.Strategy.SamplesTimeRangeMin) * time.Minute,
+	}
+
+	switch config.Strategy.Type {
+	case StrategySlope:
+		return &slopeStrategy{
+			maxUsers: maxUsers,
+			incValue: config.NumUsersInc,
+			decValue: config.NumUsersDec,
+			restTime: restTime,
+			conv:     conv,
+		}, nil
+	case StrategyBinarySearch:
+		return &binarySearchStrategy{
+			maxUsers:  maxUsers,
+			restTime:  restTime,
+			holdTime:  time.Duration(config.Strategy.BinarySearch.HoldTimeSec) * time.Second,
+			precision: config.Strategy.BinarySearch.PrecisionUsers,
+			high:      maxUsers + 1,
+			target:    (maxUsers + 1) / 2,
+		}, nil
+	case StrategyPID:
+		query, err := pidQuery(config)
+		if err != nil {
+			return nil, err
+		}
+		return &pidStrategy{
+			maxUsers: maxUsers,
+			query:    query,
+			kp:       config.Strategy.PID.Kp,
+			ki:       config.Strategy.PID.Ki,
+			kd:       config.Strategy.PID.Kd,
+			conv:     conv,
+		}, nil
+	default:
+		return nil, fmt.Errorf("coordinator: unknown strategy %q", config.Strategy.Type)
+	}
+}
+
+// convergence detects when the number of active users has settled around an
+// equilibrium point, which happens when the slope of the best fit line for
+// the latest samples approaches zero.
+type convergence struct {
+	// The slope below which we consider an equilibrium to be reached.
+	threshold float64
+	// The timespan to consider when calculating the best fit line.
+	timeRange time.Duration
+	samples   []point
+}
+
+// add records a new sample. It returns the estimated number of supported
+// users and true if an equilibrium was reached.
+func (c *convergence) add(p point) (int, bool) {
+	c.samples = append(c.samples, p)
+	latest := getLatestSamples(c.samples, c.timeRange)
+	if len(latest) > 0 && len(latest) < len(c.samples) && math.Abs(slope(latest)) < c.threshold {
+		return int(math.Round(avg(latest))), true
+	}
+	// We replace older samples which are not needed anymore.
+	if len(c.samples) >= 2*len(latest) {
+		copy(c.samples, latest)
+		c.samples = c.samples[:len(latest)]
+	}
+	return 0, false
+}
+
+// reset discards the gathered samples.
+func (c *convergence) reset() {
+	c.samples = nil
+}
+
+// slopeStrategy increments users by a fixed amount until a performance
+// degradation alert is fired, then decrements them, waiting some rest time
+// after each alert. It's done when the number of active users stabilizes.
+type slopeStrategy struct {
+	maxUsers       int
+	incValue       int
+	decValue       int
+	restTime       time.Duration
+	conv           convergence
+	lastActionTime time.Time
+	lastAlertTime  time.Time
+	// The time of the latest decision to change the number of users, which
+	// becomes lastActionTime once the change is reported as applied.
+	pendingTime time.Time
+}
+
+func (s *slopeStrategy) Next(in StrategyInput) StrategyDecision {
+	if !s.pendingTime.IsZero() {
+		if in.Applied != 0 {
+			s.lastActionTime = s.pendingTime
+		}
+		s.pendingTime = time.Time{}
+	}
+
+	if in.Alert {
+		s.lastAlertTime = in.Time
+	}
+
+	if !s.lastAlertTime.IsZero() {
+		if users, done := s.conv.add(point{x: in.Time, y: in.ActiveUsers}); done {
+			return StrategyDecision{Done: true, SupportedUsers: users}
+		}
+	}
+
+	// We give the feedback loop some rest time in case of performance
+	// degradation alerts. We want metrics to stabilize before incrementing/decrementing users again.
+	var delta int
+	if s.lastAlertTime.IsZero() || s.lastActionTime.IsZero() || in.Time.Sub(s.lastActionTime) > s.restTime {
+		if in.Alert {
+			delta = -s.decValue
+		} else if s.lastAlertTime.IsZero() || in.Time.Sub(s.lastAlertTime) > s.restTime {
+			if in.ActiveUsers < s.maxUsers {
+				delta = min(s.incValue, s.maxUsers-in.ActiveUsers)
+			}
+		}
+	} else {
+		mlog.Info("coordinator: waiting for metrics to stabilize")
+	}
+
+	if delta != 0 {
+		s.pendingTime = in.Time
+	}
+
+	return StrategyDecision{Delta: delta}
+}
+
+func (s *slopeStrategy) Reset() {
+	s.conv.reset()
+	s.pendingTime = time.Time{}
+}
+
+// binarySearchStrategy searches the number of supported users by halving
+// the interval between the highest number of users known to be supported
+// and the lowest one known to cause a degradation.
+type binarySearchStrategy struct {
+	maxUsers  int
+	restTime  time.Duration
+	holdTime  time.Duration
+	precision int
+	// The highest number of users known to be supported.
+	low int
+	// The lowest number of users known to be unsupported.
+	high int
+	// The number of users being probed.
+	target int
+	// The time at which target was reached.
+	reachedTime time.Time
+	// The change requested by the latest decision to move towards target.
+	requested int
+}
+
+func (s *binarySearchStrategy) Next(in StrategyInput) StrategyDecision {
+	if in.ActiveUsers != s.target {
+		s.reachedTime = time.Time{}
+		// The cluster could not get any closer to the target, for example
+		// because the agents are at capacity, so it's treated as unsupported.
+		if s.requested != 0 && in.Applied == 0 {
+			mlog.Warn("coordinator: could not reach target", mlog.Int("target_users", s.target), mlog.Int("active_users", in.ActiveUsers))
+			s.high = s.target
+			return s.probe(in)
+		}
+		s.requested = s.target - in.ActiveUsers
+		return StrategyDecision{Delta: s.requested}
+	}
+	s.requested = 0
+
+	if s.reachedTime.IsZero() {
+		s.reachedTime = in.Time
+	}
+
+	// Alerts fired during the rest time are likely caused by the previous
+	// target, so they are ignored.
+	elapsed := in.Time.Sub(s.reachedTime)
+	if elapsed < s.restTime {
+		mlog.Info("coordinator: waiting for metrics to stabilize")
+		return StrategyDecision{}
+	}
+
+	if in.Alert {
+		s.high = s.target
+	} else if elapsed >= s.holdTime {
+		s.low = s.target
+	} else {
+		return StrategyDecision{}
+	}
+
+	return s.probe(in)
+}
+
+func (s *binarySearchStrategy) Reset() {
+	// The current target needs to be reached and held again.
+	s.reachedTime = time.Time{}
+	s.requested = 0
+}
+
+// probe moves the search to the middle of the current interval, or ends it
+// if the interval is small enough.
+func (s *binarySearchStrategy) probe(in StrategyInput) StrategyDecision {
+	if s.low == s.maxUsers || s.high-s.low <= s.precision {
+		return StrategyDecision{Done: true, SupportedUsers: s.low}
+	}
+
+	s.target = s.low + (s.high-s.low)/2
+	s.reachedTime = time.Time{}
+	s.requested = s.target - in.ActiveUsers
+	mlog.Info("coordinator: probing new target", mlog.Int("target_users", s.target), mlog.Int("low", s.low), mlog.Int("high", s.high))
+
+	return StrategyDecision{Delta: s.requested}
+}
+
+// pidQuery returns the description of the query driving the PID strategy.
+// If none is configured, the first query with Alert set is used.
+func pidQuery(config *Config) (string, error) {
+	queries := config.MonitorConfig.Queries
+	if config.Strategy.PID.Query == "" {
+		for _, q := range queries {
+			if q.Alert {
+				return q.Description, nil
+			}
+		}
+		return "", errors.New("coordinator: the pid strategy needs a query with Alert set")
+	}
+	for _, q := range queries {
+		if q.Description == config.Strategy.PID.Query {
+			return q.Description, nil
+		}
+	}
+	return "", fmt.Errorf("coordinator: unknown pid query %q", config.Strategy.PID.Query)
+}
+
+// pidStrategy computes the target number of users through a PID controller.
+// The error signal is the relative distance of the value of a query from its
+// threshold, which is positive while the target instance performs well and
+// negative once the threshold is crossed. The number of users then settles
+// where the query value meets the threshold.
+type pidStrategy struct {
+	maxUsers int
+	query    string
+	kp       float64
+	ki       float64
+	kd       float64
+	conv     convergence
+	// Set once the threshold has been reached for the first time.
+	reached  bool
+	integral float64
+	lastErr  float64
+	lastTime time.Time
+}
+
+// errorSignal returns the error signal for the given input, limited to [-1, 1].
+// If the query didn't return a usable value, the alert status is used
+// instead.
+func (s *pidStrategy) errorSignal(in StrategyInput) float64 {
+	for _, q := range in.Queries {
+		if q.Description != s.query {
+			continue
+		}
+		if q.Error == "" && q.Threshold > 0 {
+			return math.Max(-1, math.Min((q.Threshold-q.Value)/q.Threshold, 1))
+		}
+		break
+	}
+	if in.Alert {
+		return -1
+	}
+	return 1
+}
+
+func (s *pidStrategy) Next(in StrategyInput) StrategyDecision {
+	e := s.errorSignal(in)
+	if e <= 0 {
+		s.reached = true
+	}
+
+	var derivative float64
+	if !s.lastTime.IsZero() {
+		if dt := in.Time.Sub(s.lastTime).Seconds(); dt > 0 {
+			s.integral += e * dt
+			derivative = (e - s.lastErr) / dt
+		}
+	}
+	s.lastErr = e
+	s.lastTime = in.Time
+
+	// Anti-windup: the integral term alone should never push the target
+	// outside of the allowed range.
+	if s.ki > 0 {
+		s.integral = math.Max(0, math.Min(s.integral, float64(s.maxUsers)/s.ki))
+	}
+
+	if s.reached {
+		if users, done := s.conv.add(point{x: in.Time, y: in.ActiveUsers}); done {
+			return StrategyDecision{Done: true, SupportedUsers: users}
+		}
+	}
+
+	output := s.kp*e + s.ki*s.integral + s.kd*derivative
+	target := int(math.Round(math.Max(0, math.Min(output, float64(s.maxUsers)))))
+
+	return StrategyDecision{Delta: target - in.ActiveUsers}
+}
+
+func (s *pidStrategy) Reset() {
+	s.conv.reset()
+	s.lastTime = time.Time{}
+}
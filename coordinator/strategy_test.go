@@ -0,0 +1,245 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance"
+	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newStrategyConfig(t *testing.T, strategyType StrategyType) *Config {
+	var config Config
+	require.NoError(t, defaults.Set(&config))
+	config.ClusterConfig.MaxActiveUsers = 2000
+	config.MonitorConfig.Queries[0] = prometheus.Query{
+		Description: "latency",
+		Query:       "latency",
+		Threshold:   1,
+		Alert:       true,
+	}
+	config.Strategy.Type = strategyType
+	return &config
+}
+
+// simulate runs the given strategy against a fake target instance whose
+// latency grows linearly with the number of active users, reaching its
+// threshold at capacity. An alert fires whenever the number of active users
+// exceeds capacity.
+// It returns the estimated number of supported users.
+func simulate(t *testing.T, s Strategy, maxUsers, capacity int) int {
+	t.Helper()
+	now := time.Unix(0, 0)
+	var activeUsers, applied int
+	for i := 0; i < 100000; i++ {
+		decision := s.Next(StrategyInput{
+			Time:        now,
+			ActiveUsers: activeUsers,
+			Alert:       activeUsers > capacity,
+			Applied:     applied,
+			Queries: []performance.QueryResult{
+				{Description: "latency", Value: float64(activeUsers) / float64(capacity), Threshold: 1},
+			},
+		})
+		if decision.Done {
+			return decision.SupportedUsers
+		}
+		applied = decision.Delta
+		activeUsers += decision.Delta
+		require.GreaterOrEqual(t, activeUsers, 0)
+		require.LessOrEqual(t, activeUsers, maxUsers)
+		now = now.Add(2 * time.Second)
+	}
+	require.FailNow(t, "strategy did not converge")
+	return 0
+}
+
+func TestNewStrategy(t *testing.T) {
+	config := newStrategyConfig(t, "bad")
+	require.Error(t, defaults.Validate(config.Strategy))
+	_, err := newStrategy(config)
+	require.Error(t, err)
+
+	for _, strategyType := range []StrategyType{StrategySlope, StrategyBinarySearch, StrategyPID} {
+		config := newStrategyConfig(t, strategyType)
+		require.NoError(t, defaults.Validate(config.Strategy))
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+		require.NotNil(t, s)
+	}
+}
+
+func TestSlopeStrategy(t *testing.T) {
+	config := newStrategyConfig(t, StrategySlope)
+	s, err := newStrategy(config)
+	require.NoError(t, err)
+
+	users := simulate(t, s, config.ClusterConfig.MaxActiveUsers, 500)
+	require.InDelta(t, 500, users, float64(2*config.NumUsersInc))
+}
+
+func TestSlopeStrategyRestTime(t *testing.T) {
+	config := newStrategyConfig(t, StrategySlope)
+	restTime := time.Duration(config.RestTimeSec) * time.Second
+
+	t.Run("applied", func(t *testing.T) {
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		now := time.Unix(0, 0)
+		decision := s.Next(StrategyInput{Time: now, ActiveUsers: 1000, Alert: true})
+		require.Equal(t, -config.NumUsersDec, decision.Delta)
+
+		now = now.Add(restTime / 2)
+		decision = s.Next(StrategyInput{Time: now, ActiveUsers: 1000 - config.NumUsersDec, Alert: true, Applied: decision.Delta})
+		require.Zero(t, decision.Delta)
+	})
+
+	t.Run("not applied", func(t *testing.T) {
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		now := time.Unix(0, 0)
+		decision := s.Next(StrategyInput{Time: now, ActiveUsers: 1000, Alert: true})
+		require.Equal(t, -config.NumUsersDec, decision.Delta)
+
+		// Updating the cluster failed, so there's nothing to wait for.
+		now = now.Add(restTime / 2)
+		decision = s.Next(StrategyInput{Time: now, ActiveUsers: 1000, Alert: true})
+		require.Equal(t, -config.NumUsersDec, decision.Delta)
+	})
+}
+
+func TestBinarySearchStrategy(t *testing.T) {
+	t.Run("Capacity", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyBinarySearch)
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		users := simulate(t, s, config.ClusterConfig.MaxActiveUsers, 500)
+		require.LessOrEqual(t, users, 500)
+		require.Greater(t, users, 500-config.Strategy.BinarySearch.PrecisionUsers)
+	})
+
+	t.Run("MaxActiveUsers", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyBinarySearch)
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		users := simulate(t, s, config.ClusterConfig.MaxActiveUsers, 5000)
+		require.LessOrEqual(t, users, config.ClusterConfig.MaxActiveUsers)
+		require.Greater(t, users, config.ClusterConfig.MaxActiveUsers-config.Strategy.BinarySearch.PrecisionUsers)
+	})
+
+	t.Run("UnreachableTarget", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyBinarySearch)
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		// The agents can't run more than 300 users, well below capacity.
+		now := time.Unix(0, 0)
+		var activeUsers, applied int
+		for i := 0; i < 100000; i++ {
+			decision := s.Next(StrategyInput{
+				Time:        now,
+				ActiveUsers: activeUsers,
+				Alert:       activeUsers > 500,
+				Applied:     applied,
+			})
+			if decision.Done {
+				require.LessOrEqual(t, decision.SupportedUsers, 300)
+				require.Greater(t, decision.SupportedUsers, 300-config.Strategy.BinarySearch.PrecisionUsers)
+				return
+			}
+			applied = min(activeUsers+decision.Delta, 300) - activeUsers
+			activeUsers += applied
+			now = now.Add(2 * time.Second)
+		}
+		require.FailNow(t, "strategy did not converge")
+	})
+
+	t.Run("IgnoreAlertsDuringRestTime", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyBinarySearch)
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		now := time.Unix(0, 0)
+		decision := s.Next(StrategyInput{Time: now})
+		require.Equal(t, 1000, decision.Delta)
+
+		decision = s.Next(StrategyInput{Time: now, ActiveUsers: 1000, Alert: true})
+		require.Zero(t, decision.Delta)
+
+		now = now.Add(time.Duration(config.RestTimeSec) * time.Second)
+		decision = s.Next(StrategyInput{Time: now, ActiveUsers: 1000, Alert: true})
+		require.Equal(t, -500, decision.Delta)
+	})
+}
+
+func TestPIDStrategy(t *testing.T) {
+	t.Run("Capacity", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyPID)
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+
+		users := simulate(t, s, config.ClusterConfig.MaxActiveUsers, 500)
+		require.InDelta(t, 500, users, 2)
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyPID)
+		config.Strategy.PID.Query = "unknown"
+		_, err := newStrategy(config)
+		require.Error(t, err)
+
+		config.MonitorConfig.Queries[0].Alert = false
+		config.Strategy.PID.Query = ""
+		_, err = newStrategy(config)
+		require.Error(t, err)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		config := newStrategyConfig(t, StrategyPID)
+		s, err := newStrategy(config)
+		require.NoError(t, err)
+		pid := s.(*pidStrategy)
+
+		now := time.Unix(0, 0)
+		s.Next(StrategyInput{Time: now})
+		now = now.Add(2 * time.Second)
+		s.Next(StrategyInput{Time: now})
+		integral := pid.integral
+
+		// The time spent while the strategy was suspended isn't integrated.
+		s.Reset()
+		now = now.Add(time.Hour)
+		s.Next(StrategyInput{Time: now})
+		require.Equal(t, integral, pid.integral)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		s := &pidStrategy{query: "latency"}
+		in := StrategyInput{
+			Queries: []performance.QueryResult{
+				{Description: "other", Value: 10, Threshold: 1},
+				{Description: "latency", Value: 0.75, Threshold: 1},
+			},
+		}
+		require.Equal(t, 0.25, s.errorSignal(in))
+
+		in.Queries[1].Value = 5
+		require.Equal(t, -1.0, s.errorSignal(in))
+
+		// Without a usable value, the alert status is used.
+		in.Queries[1].Error = "failed"
+		require.Equal(t, 1.0, s.errorSignal(in))
+		in.Alert = true
+		require.Equal(t, -1.0, s.errorSignal(in))
+	})
+}
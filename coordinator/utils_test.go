@@ -10,14 +10,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestHasPassed(t *testing.T) {
-	tm := time.Now()
-	require.False(t, hasPassed(tm, 1*time.Second))
-	time.Sleep(1 * time.Second)
-	require.True(t, hasPassed(tm, 500*time.Millisecond))
-	require.False(t, hasPassed(tm, 2*time.Second))
-}
-
 func TestMin(t *testing.T) {
 	require.Equal(t, 0, min(0, 1))
 	require.Equal(t, 0, min(1, 0))
@@ -4,17 +4,40 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/mattermost/mattermost-load-test-ng/coordinator"
 	"github.com/mattermost/mattermost-load-test-ng/coordinator/cluster"
 	"github.com/mattermost/mattermost-load-test-ng/deployment/terraform/ssh"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/integcontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
 	"github.com/mattermost/mattermost-server/v5/mlog"
 )
 
+// runNameRe matches the valid names of a load-test run. It is restrictive
+// since the name is used in file paths and shell commands.
+var runNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// resultsFileLocation returns the path of the file on the coordinator
+// instance where the results of the given run are stored. Unnamed runs
+// use the default location.
+func resultsFileLocation(runName string) string {
+	if runName == "" {
+		return "/home/ubuntu/mattermost-load-test-ng/ltcoordinator_results.json"
+	}
+	return "/home/ubuntu/mattermost-load-test-ng/ltcoordinator_results_" + runName + ".json"
+}
+
 // StartCoordinator starts the coordinator in the current load-test deployment.
-func (t *Terraform) StartCoordinator() error {
+// If runName is set, the results of the run are stored under that name so
+// that they can be retrieved later by GetCoordinatorResults.
+func (t *Terraform) StartCoordinator(runName string) error {
+	if runName != "" && !runNameRe.MatchString(runName) {
+		return fmt.Errorf("invalid run name %q: only letters, digits, dashes and underscores are allowed", runName)
+	}
+
 	if err := t.preFlightCheck(); err != nil {
 		return err
 	}
@@ -54,6 +77,7 @@ func (t *Terraform) StartCoordinator() error {
 	}
 	coordinatorConfig.ClusterConfig.Agents = loadAgentConfigs
 	coordinatorConfig.MonitorConfig.PrometheusURL = "http://" + output.MetricsServer.Value.PrivateIP + ":9090"
+	coordinatorConfig.ResultsFileLocation = resultsFileLocation(runName)
 
 	data, err := json.MarshalIndent(coordinatorConfig, "", "  ")
 	if err != nil {
@@ -82,6 +106,11 @@ func (t *Terraform) StartCoordinator() error {
 		return err
 	}
 
+	integConfig, err := integcontroller.ReadConfig("")
+	if err != nil {
+		return err
+	}
+
 	batch := []struct {
 		input   interface{}
 		dstPath string
@@ -98,6 +127,10 @@ func (t *Terraform) StartCoordinator() error {
 			input:   simpleConfig,
 			dstPath: "/home/ubuntu/mattermost-load-test-ng/config/simplecontroller.json",
 		},
+		{
+			input:   integConfig,
+			dstPath: "/home/ubuntu/mattermost-load-test-ng/config/integcontroller.json",
+		},
 	}
 
 	for _, info := range batch {
@@ -156,3 +189,61 @@ func (t *Terraform) StopCoordinator() error {
 	mlog.Info("Done")
 	return nil
 }
+
+// GetCoordinatorResults returns the results of a finished load-test run in
+// the current load-test deployment. If runName is empty, the results of the
+// latest run are returned.
+func (t *Terraform) GetCoordinatorResults(runName string) (coordinator.Results, error) {
+	var results coordinator.Results
+	if runName != "" && !runNameRe.MatchString(runName) {
+		return results, fmt.Errorf("invalid run name %q", runName)
+	}
+
+	if err := t.preFlightCheck(); err != nil {
+		return results, err
+	}
+
+	output, err := t.Output()
+	if err != nil {
+		return results, err
+	}
+
+	if len(output.Agents.Value) == 0 {
+		return results, fmt.Errorf("there are no agent instances running the coordinator")
+	}
+	ip := output.Agents.Value[0].PublicIP
+
+	extAgent, err := ssh.NewAgent()
+	if err != nil {
+		return results, err
+	}
+	sshc, err := extAgent.NewClient(ip)
+	if err != nil {
+		return results, err
+	}
+	defer sshc.Close()
+
+	path := resultsFileLocation(runName)
+	if runName == "" {
+		// Both named and unnamed runs match the pattern.
+		cmd := "ls -t " + strings.TrimSuffix(path, ".json") + "*.json 2>/dev/null | head -n 1"
+		out, err := sshc.RunCommand(cmd)
+		if err != nil {
+			return results, fmt.Errorf("error running ssh command: output: %q, error: %w", out, err)
+		}
+		path = strings.TrimSpace(string(out))
+		if path == "" {
+			return results, fmt.Errorf("no results found for any load-test run")
+		}
+	}
+
+	mlog.Info("Downloading coordinator results", mlog.String("ip", ip), mlog.String("path", path))
+	var buf bytes.Buffer
+	if err := sshc.Download(path, &buf, false); err != nil {
+		return results, fmt.Errorf("error downloading results from %s: %w", path, err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		return results, fmt.Errorf("error decoding results from %s: %w", path, err)
+	}
+	return results, nil
+}
@@ -16,6 +16,7 @@ type SampleController struct {
 	id     int
 	user   user.User
 	stop   chan struct{}
+	pause  control.PauseState
 	status chan<- control.UserStatus
 }
 
@@ -66,6 +67,9 @@ func (c *SampleController) Run() {
 				return
 			case <-time.After(actions[i].waitAfter * time.Millisecond):
 			}
+			if !c.pause.Wait(c.stop) {
+				return
+			}
 		}
 	}
 }
@@ -74,6 +78,14 @@ func (c *SampleController) SetRate(rate float64) error {
 	return nil
 }
 
+func (c *SampleController) Pause() {
+	c.pause.Pause()
+}
+
+func (c *SampleController) Resume() {
+	c.pause.Resume()
+}
+
 func (c *SampleController) Stop() {
 	close(c.stop)
 	c.stop = make(chan struct{})
@@ -284,6 +284,10 @@ func (s *SampleStore) SetChannelMember(channelId string, channelMember *model.Ch
 	return errors.New("not implemented")
 }
 
+func (s *SampleStore) SetChannelMemberNotifyProps(channelId, userId string, notifyProps model.StringMap) error {
+	return errors.New("not implemented")
+}
+
 func (s *SampleStore) ChannelMember(channelId, userId string) (model.ChannelMember, error) {
 	return model.ChannelMember{}, errors.New("not implemented")
 }
@@ -374,3 +378,43 @@ func (s *SampleStore) UserForPost(postId string) (string, error) {
 func (s *SampleStore) FileInfoForPost(postId string) ([]*model.FileInfo, error) {
 	return nil, nil
 }
+
+func (s *SampleStore) Thread(threadId string) (*store.Thread, error) {
+	return nil, nil
+}
+
+func (s *SampleStore) ThreadsSorted(unreadOnly, asc bool) ([]*store.Thread, error) {
+	return nil, nil
+}
+
+func (s *SampleStore) RandomThread() (store.Thread, error) {
+	return store.Thread{}, nil
+}
+
+func (s *SampleStore) SetThreads(threads []*store.Thread) error {
+	return nil
+}
+
+func (s *SampleStore) MarkThreadAsRead(threadId string, timestamp int64) error {
+	return nil
+}
+
+func (s *SampleStore) MarkAllThreadsInTeamAsRead(teamId string) error {
+	return nil
+}
+
+func (s *SampleStore) RemoveThread(threadId string) error {
+	return nil
+}
+
+func (s *SampleStore) FileInfo(fileId string) (*model.FileInfo, error) {
+	return nil, nil
+}
+
+func (s *SampleStore) RandomFileInfo() (model.FileInfo, error) {
+	return model.FileInfo{}, nil
+}
+
+func (s *SampleStore) SetFileInfos(infos []*model.FileInfo) error {
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
 	"github.com/mattermost/mattermost-server/v5/model"
 )
 
@@ -170,6 +171,10 @@ func (u *SampleUser) GetChannelMember(channelId, userId string) error {
 	return nil
 }
 
+func (u *SampleUser) UpdateChannelNotifyProps(channelId, userId string, props map[string]string) error {
+	return nil
+}
+
 func (u *SampleUser) GetChannelStats(channelId string) error {
 	return nil
 }
@@ -412,6 +417,30 @@ func (u *SampleUser) GetWebappPlugins() error {
 	return nil
 }
 
+func (u *SampleUser) ExecuteCommand(channelId, command string) (*model.CommandResponse, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) CreateCommand(cmd *model.Command) (string, error) {
+	return "", nil
+}
+
+func (u *SampleUser) CreateIncomingWebhook(hook *model.IncomingWebhook) (string, error) {
+	return "", nil
+}
+
+func (u *SampleUser) PostToIncomingWebhook(hookId string, request *model.IncomingWebhookRequest) error {
+	return nil
+}
+
+func (u *SampleUser) CreateOutgoingWebhook(hook *model.OutgoingWebhook) (string, error) {
+	return "", nil
+}
+
+func (u *SampleUser) CreateBot(bot *model.Bot) (string, error) {
+	return "", nil
+}
+
 // GetClientLicense returns the client license in the old format.
 func (u *SampleUser) GetClientLicense() error {
 	return nil
@@ -475,3 +504,55 @@ func (u *SampleUser) GetPluginStatuses() error {
 func (u *SampleUser) UpdateConfig(*model.Config) error {
 	return nil
 }
+
+func (u *SampleUser) GetPostThread(postId string) ([]string, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) GetUserThreads(teamId string, unreadOnly bool, pageSize int) ([]*store.Thread, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) GetUserThread(teamId, threadId string) (*store.Thread, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) UpdateThreadRead(teamId, threadId string, timestamp int64) error {
+	return nil
+}
+
+func (u *SampleUser) UpdateThreadsReadForUser(teamId string) error {
+	return nil
+}
+
+func (u *SampleUser) UpdateThreadFollow(teamId, threadId string, state bool) error {
+	return nil
+}
+
+func (u *SampleUser) GetFileInfo(fileId string) (*model.FileInfo, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) DownloadFile(fileId string) error {
+	return nil
+}
+
+func (u *SampleUser) SearchFiles(teamId, terms string, isOrSearch bool) ([]*model.FileInfo, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) SearchFilesInChannel(channelId string) ([]*model.FileInfo, error) {
+	return nil, nil
+}
+
+func (u *SampleUser) UpdateUserStatus(status string) error {
+	return nil
+}
+
+func (u *SampleUser) UpdateCustomStatus(customStatus *user.CustomStatus) error {
+	return nil
+}
+
+func (u *SampleUser) RemoveCustomStatus() error {
+	return nil
+}
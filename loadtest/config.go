@@ -5,6 +5,7 @@ package loadtest
 
 import (
 	"errors"
+	"fmt"
 	"math"
 
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
@@ -23,11 +24,12 @@ type userControllerType string
 
 // Available UserController implementations.
 const (
-	UserControllerSimple     userControllerType = "simple"
-	UserControllerSimulative                    = "simulative"
-	UserControllerNoop                          = "noop"
-	UserControllerGenerative                    = "generative"
-	UserControllerCluster                       = "cluster"
+	UserControllerSimple       userControllerType = "simple"
+	UserControllerSimulative                      = "simulative"
+	UserControllerNoop                            = "noop"
+	UserControllerGenerative                      = "generative"
+	UserControllerCluster                         = "cluster"
+	UserControllerIntegrations                    = "integrations"
 )
 
 type RatesDistribution struct {
@@ -44,7 +46,8 @@ type UserControllerConfiguration struct {
 	//   UserControllerSimulative - A more realistic controller.
 	//   UserControllerNoop
 	//   UserControllerGenerative - A controller used to generate data.
-	Type userControllerType `default:"simulative" validate:"oneof:{simple,simulative,noop,cluster,generative}"`
+	//   UserControllerIntegrations - A controller driving traffic through integrations.
+	Type userControllerType `default:"simulative" validate:"oneof:{simple,simulative,noop,cluster,generative,integrations}"`
 	// A distribution of rate multipliers that will affect the speed at which user actions are
 	// executed by the UserController.
 	// A Rate of < 1.0 will run actions at a faster pace.
@@ -109,6 +112,37 @@ type UsersConfiguration struct {
 	InitialActiveUsers int `default:"0" validate:"range:[0,$MaxActiveUsers]"`
 	MaxActiveUsers     int `default:"2000" validate:"range:(0,]"`
 	AvgSessionsPerUser int `default:"1" validate:"range:[1,]"`
+	// The strategy used to pick which users to stop when decrementing the
+	// number of active users.
+	// Possible values:
+	//   RemovalStrategyLIFO - Most recently added users are removed first.
+	//   RemovalStrategyFIFO - Least recently added users are removed first.
+	//   RemovalStrategyRandom - Users are picked at random.
+	//   RemovalStrategyLongestRunning - Users that ran for longer are removed first.
+	//   RemovalStrategyMostErrors - Users that reported more errors are removed first.
+	RemovalStrategy RemovalStrategy `default:"lifo" validate:"oneof:{lifo,fifo,random,longest_running,most_errors}"`
+}
+
+// RampPhase describes a single phase of a RampProfile.
+type RampPhase struct {
+	// The number of active users to reach at the end of the ramp.
+	TargetUsers int `default:"0" validate:"range:[0,]"`
+	// The number of seconds over which the number of active users is linearly
+	// moved towards TargetUsers. A value of 0 reaches the target immediately.
+	RampDurationSec int `default:"0" validate:"range:[0,]"`
+	// The number of seconds to hold TargetUsers before moving to the next phase.
+	HoldDurationSec int `default:"0" validate:"range:[0,]"`
+}
+
+// RampProfile holds a sequence of phases the LoadTester executes right after
+// starting, adding and removing users on its own. Stepped, linear, spike and
+// soak tests can all be described as a combination of phases.
+type RampProfile struct {
+	// The phases to execute, in order. An empty list disables the profile.
+	Phases []RampPhase
+	// The number of milliseconds to wait between each update of the number of
+	// active users during a ramp.
+	UpdateIntervalMs int `default:"1000" validate:"range:(0,]"`
 }
 
 type Config struct {
@@ -116,9 +150,21 @@ type Config struct {
 	UserControllerConfiguration UserControllerConfiguration
 	InstanceConfiguration       InstanceConfiguration
 	UsersConfiguration          UsersConfiguration
+	RampProfile                 RampProfile
 	LogSettings                 logger.Settings
 }
 
+// IsValid reports whether a given Config is valid or not.
+// Returns an error if the validation fails.
+func (c *Config) IsValid() error {
+	for i, phase := range c.RampProfile.Phases {
+		if phase.TargetUsers > c.UsersConfiguration.MaxActiveUsers {
+			return fmt.Errorf("TargetUsers of ramp phase %d should not be greater than MaxActiveUsers", i)
+		}
+	}
+	return nil
+}
+
 // ReadConfig reads the configuration file from the given string. If the string
 // is empty, it will return a config with default values.
 func ReadConfig(configFilePath string) (*Config, error) {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
@@ -14,12 +15,14 @@ import (
 )
 
 type ClusterController struct {
-	id      int
-	user    user.User
-	stop    chan struct{}
-	stopped chan struct{}
-	status  chan<- control.UserStatus
-	rate    float64
+	id         int
+	user       user.User
+	stop       chan struct{}
+	pauseState control.PauseState
+	stopped    chan struct{}
+	status     chan<- control.UserStatus
+	rate       float64
+	rateMut    sync.RWMutex
 }
 
 func New(id int, user user.User, status chan<- control.UserStatus) (*ClusterController, error) {
@@ -132,12 +135,16 @@ func (c *ClusterController) Run() {
 				c.status <- c.newInfoStatus(resp.Info)
 			}
 
-			idleTime := time.Duration(math.Round(float64(1000) * c.rate))
+			idleTime := time.Duration(math.Round(float64(1000) * c.getRate()))
 			select {
 			case <-c.stop:
 				return
 			case <-time.After(time.Millisecond * idleTime):
 			}
+
+			if !c.pauseState.Wait(c.stop) {
+				return
+			}
 		}
 	}
 }
@@ -147,10 +154,28 @@ func (c *ClusterController) SetRate(rate float64) error {
 	if rate < 0 {
 		return errors.New("rate should be a positive value")
 	}
+	c.rateMut.Lock()
+	defer c.rateMut.Unlock()
 	c.rate = rate
 	return nil
 }
 
+func (c *ClusterController) getRate() float64 {
+	c.rateMut.RLock()
+	defer c.rateMut.RUnlock()
+	return c.rate
+}
+
+// Pause suspends the execution of user actions until Resume is called.
+func (c *ClusterController) Pause() {
+	c.pauseState.Pause()
+}
+
+// Resume resumes the execution of user actions.
+func (c *ClusterController) Resume() {
+	c.pauseState.Resume()
+}
+
 // Stop stops the controller.
 func (c *ClusterController) Stop() {
 	close(c.stop)
@@ -12,7 +12,13 @@ type UserController interface {
 	// SetRate determines the relative speed in which user actions are performed
 	// one after the other. A rate of 1.0 will run the actions in their usual
 	// speed. A rate of 2.0 will slow down the actions by a factor of 2.
+	// It's safe to call it while the controller is running.
 	SetRate(rate float64) error
+	// Pause suspends the execution of user actions until Resume is called.
+	// The user's session and WebSocket connection are kept alive.
+	Pause()
+	// Resume resumes the execution of user actions suspended by Pause.
+	Resume()
 	// Stop stops the controller.
 	Stop()
 }
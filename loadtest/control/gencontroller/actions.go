@@ -18,6 +18,7 @@ import (
 )
 
 type userAction struct {
+	name       string
 	run        control.UserAction
 	frequency  int
 	idleTimeMs int
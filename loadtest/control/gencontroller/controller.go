@@ -7,21 +7,26 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+	"github.com/mattermost/mattermost-load-test-ng/performance"
 )
 
 // GenController is an implementation of a UserController used to generate
 // realistic initial data.
 type GenController struct {
-	id     int
-	user   user.User
-	stop   chan struct{}
-	status chan<- control.UserStatus
-	rate   float64
-	config *Config
+	id         int
+	user       user.User
+	stop       chan struct{}
+	pauseState control.PauseState
+	status     chan<- control.UserStatus
+	rate       float64
+	rateMut    sync.RWMutex
+	config     *Config
+	metrics    *performance.ControllerMetrics
 }
 
 // New creates and initializes a new GenController with given parameters.
@@ -69,73 +74,86 @@ func (c *GenController) Run() {
 
 	c.status <- control.UserStatus{ControllerId: c.id, User: c.user, Info: "user started", Code: control.USER_STATUS_STARTED}
 
-	initActions := []control.UserAction{
-		control.SignUp,
-		control.Login,
-		c.createTeam,
-		c.joinTeam,
-		c.joinChannel,
+	initActions := []userAction{
+		{name: "SignUp", run: control.SignUp},
+		{name: "Login", run: control.Login},
+		{name: "CreateTeam", run: c.createTeam},
+		{name: "JoinTeam", run: c.joinTeam},
+		{name: "JoinChannel", run: c.joinChannel},
 	}
 
 	for i := 0; i < len(initActions); i++ {
-		if resp := initActions[i](c.user); resp.Err != nil {
+		if resp := c.runAction(&initActions[i]); resp.Err != nil {
 			c.status <- c.newErrorStatus(resp.Err)
 			i--
 		} else {
 			c.status <- c.newInfoStatus(resp.Info)
 		}
 
-		idleTime := time.Duration(math.Round(100 * c.rate))
+		idleTime := time.Duration(math.Round(100 * c.getRate()))
 
 		select {
 		case <-c.stop:
 			return
 		case <-time.After(idleTime * time.Millisecond):
 		}
+
+		if !c.pauseState.Wait(c.stop) {
+			return
+		}
 	}
 
 	actions := map[string]userAction{
 		"joinTeam": {
+			name:       "JoinTeam",
 			run:        control.JoinTeam,
 			frequency:  100,
 			idleTimeMs: 0,
 		},
 		"joinChannel": {
+			name:       "JoinChannel",
 			run:        c.joinChannel,
 			frequency:  1000,
 			idleTimeMs: 0,
 		},
 		"createPublicChannel": {
+			name:       "CreatePublicChannel",
 			run:        c.createPublicChannel,
 			frequency:  int(math.Round(float64(c.config.NumChannels) * c.config.PercentPublicChannels)),
 			idleTimeMs: 1000,
 		},
 		"createPrivateChannel": {
+			name:       "CreatePrivateChannel",
 			run:        c.createPrivateChannel,
 			frequency:  int(math.Round(float64(c.config.NumChannels) * c.config.PercentPrivateChannels)),
 			idleTimeMs: 1000,
 		},
 		"createDirectChannel": {
+			name:       "CreateDirectChannel",
 			run:        c.createDirectChannel,
 			frequency:  int(math.Round(float64(c.config.NumChannels) * c.config.PercentDirectChannels)),
 			idleTimeMs: 1000,
 		},
 		"createGroupChannel": {
+			name:       "CreateGroupChannel",
 			run:        c.createGroupChannel,
 			frequency:  int(math.Round(float64(c.config.NumChannels) * c.config.PercentGroupChannels)),
 			idleTimeMs: 1000,
 		},
 		"createPost": {
+			name:       "CreatePost",
 			run:        c.createPost,
 			frequency:  int(math.Round(float64(c.config.NumPosts) * (1 - c.config.PercentReplies))),
 			idleTimeMs: 1000,
 		},
 		"createReply": {
+			name:       "CreateReply",
 			run:        c.createReply,
 			frequency:  int(math.Round(float64(c.config.NumPosts) * c.config.PercentReplies)),
 			idleTimeMs: 1000,
 		},
 		"addReaction": {
+			name:       "AddReaction",
 			run:        c.addReaction,
 			frequency:  int(c.config.NumReactions),
 			idleTimeMs: 1000,
@@ -156,7 +174,7 @@ func (c *GenController) Run() {
 			return
 		}
 
-		if resp := action.run(c.user); resp.Err != nil {
+		if resp := c.runAction(action); resp.Err != nil {
 			c.status <- c.newErrorStatus(resp.Err)
 		} else {
 			c.status <- c.newInfoStatus(resp.Info)
@@ -183,25 +201,62 @@ func (c *GenController) Run() {
 			delete(actions, "addReaction")
 		}
 
-		idleTime := time.Duration(math.Round(float64(action.idleTimeMs) * c.rate))
+		idleTime := time.Duration(math.Round(float64(action.idleTimeMs) * c.getRate()))
 
 		select {
 		case <-c.stop:
 			return
 		case <-time.After(idleTime * time.Millisecond):
 		}
+
+		if !c.pauseState.Wait(c.stop) {
+			return
+		}
 	}
 }
 
+// runAction performs the given action, recording its execution time and
+// outcome.
+func (c *GenController) runAction(action *userAction) control.UserActionResponse {
+	start := time.Now()
+	resp := action.run(c.user)
+	c.metrics.ObserveAction(action.name, time.Since(start), resp.Err)
+	return resp
+}
+
+// SetMetrics sets the metrics the controller emits for each user action.
+// It should be called before Run.
+func (c *GenController) SetMetrics(metrics *performance.ControllerMetrics) {
+	c.metrics = metrics
+}
+
 // SetRate sets the relative speed of execution of actions by the user.
 func (c *GenController) SetRate(rate float64) error {
 	if rate < 0 {
 		return errors.New("rate should be a positive value")
 	}
+	c.rateMut.Lock()
+	defer c.rateMut.Unlock()
 	c.rate = rate
 	return nil
 }
 
+func (c *GenController) getRate() float64 {
+	c.rateMut.RLock()
+	defer c.rateMut.RUnlock()
+	return c.rate
+}
+
+// Pause suspends the execution of user actions until Resume is called.
+func (c *GenController) Pause() {
+	c.pauseState.Pause()
+}
+
+// Resume resumes the execution of user actions.
+func (c *GenController) Resume() {
+	c.pauseState.Resume()
+}
+
 // Stop stops the controller.
 func (c *GenController) Stop() {
 	close(c.stop)
@@ -0,0 +1,203 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package integcontroller
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// integrations holds the integrations created by a controlled user.
+type integrations struct {
+	teamId         string
+	channelId      string
+	botUsername    string
+	commandTrigger string
+	incomingHookId string
+	hookTrigger    string
+}
+
+// builtInCommands are the slash commands provided by the server itself.
+var builtInCommands = []string{"/shrug", "/me"}
+
+// getActionList returns the list of all the actions the controller can
+// perform, along with their default frequencies.
+func getActionList(c *IntegController) []control.WeightedAction {
+	return []control.WeightedAction{
+		{Name: "ExecuteCommand", Run: c.executeCommand, Frequency: 30},
+		{Name: "ExecuteBuiltInCommand", Run: c.executeBuiltInCommand, Frequency: 10},
+		{Name: "PostToIncomingWebhook", Run: c.postToIncomingWebhook, Frequency: 40},
+		{Name: "TriggerOutgoingWebhook", Run: c.triggerOutgoingWebhook, Frequency: 20},
+	}
+}
+
+// createActions returns the list of actions to be run by the controller with
+// their frequencies set according to the given definitions.
+// If no definitions are given the default frequencies are used. Otherwise,
+// actions that are not defined will never run.
+func createActions(c *IntegController, definitions []control.ActionDefinition) ([]control.WeightedAction, error) {
+	actions := getActionList(c)
+	if err := control.SetActionFrequencies(actions, definitions); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+func (c *IntegController) login(u user.User) control.UserActionResponse {
+	resp := control.Login(u)
+	if resp.Err != nil {
+		return resp
+	}
+
+	if err := c.connect(); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return resp
+}
+
+func (c *IntegController) logout() control.UserActionResponse {
+	if err := c.disconnect(); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+	ok, err := c.user.Logout()
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+	if !ok {
+		return control.UserActionResponse{Err: control.NewUserError(errors.New("user did not logout"))}
+	}
+	return control.UserActionResponse{Info: "logged out"}
+}
+
+// setupIntegrations creates the integrations the user will drive traffic
+// through: a bot, a custom slash command, an incoming webhook and an outgoing
+// webhook. The slash command and the outgoing webhook point to the receiver.
+func (c *IntegController) setupIntegrations(u user.User) control.UserActionResponse {
+	if c.integrations != nil {
+		return control.UserActionResponse{Info: "integrations already set up"}
+	}
+
+	team, err := u.Store().RandomTeam(store.SelectMemberOf)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	if err := u.GetChannelMembersForUser(u.Store().Id(), team.Id); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	channel, err := u.Store().RandomChannel(team.Id, store.SelectMemberOf|store.SelectNotDirect|store.SelectNotGroup)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	// Triggers must be unique within a team, so a random suffix is used.
+	suffix := model.NewId()[:8]
+	intg := &integrations{
+		teamId:         team.Id,
+		channelId:      channel.Id,
+		commandTrigger: "ltcmd" + suffix,
+		hookTrigger:    "lthook" + suffix,
+	}
+
+	if c.config.CreateBots {
+		bot := &model.Bot{
+			Username:    "ltbot-" + suffix,
+			DisplayName: "Load-test bot",
+			Description: "Bot created by " + u.Store().Username(),
+		}
+		if _, err := u.CreateBot(bot); err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+		intg.botUsername = bot.Username
+	}
+
+	if _, err := u.CreateCommand(&model.Command{
+		TeamId:      team.Id,
+		Trigger:     intg.commandTrigger,
+		Method:      model.COMMAND_METHOD_POST,
+		URL:         c.config.ReceiverURL + commandsPath,
+		DisplayName: "Load-test command",
+		Username:    intg.botUsername,
+	}); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	hookId, err := u.CreateIncomingWebhook(&model.IncomingWebhook{
+		ChannelId:   channel.Id,
+		DisplayName: "Load-test incoming webhook",
+		Username:    intg.botUsername,
+	})
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+	intg.incomingHookId = hookId
+
+	if _, err := u.CreateOutgoingWebhook(&model.OutgoingWebhook{
+		TeamId:       team.Id,
+		ChannelId:    channel.Id,
+		TriggerWords: []string{intg.hookTrigger},
+		CallbackURLs: []string{c.config.ReceiverURL + hooksPath},
+		ContentType:  "application/json",
+		DisplayName:  "Load-test outgoing webhook",
+		Username:     intg.botUsername,
+	}); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	c.integrations = intg
+
+	return control.UserActionResponse{Info: fmt.Sprintf("integrations set up in channel %s", channel.Id)}
+}
+
+func (c *IntegController) executeCommand(u user.User) control.UserActionResponse {
+	command := "/" + c.integrations.commandTrigger + " " + genMessage()
+	if _, err := u.ExecuteCommand(c.integrations.channelId, command); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("executed command /%s", c.integrations.commandTrigger)}
+}
+
+func (c *IntegController) executeBuiltInCommand(u user.User) control.UserActionResponse {
+	trigger := builtInCommands[rand.Intn(len(builtInCommands))]
+	if _, err := u.ExecuteCommand(c.integrations.channelId, trigger+" "+genMessage()); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("executed command %s", trigger)}
+}
+
+func (c *IntegController) postToIncomingWebhook(u user.User) control.UserActionResponse {
+	err := u.PostToIncomingWebhook(c.integrations.incomingHookId, &model.IncomingWebhookRequest{
+		Text:     genMessage(),
+		Username: c.integrations.botUsername,
+	})
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("posted to incoming webhook %s", c.integrations.incomingHookId)}
+}
+
+func (c *IntegController) triggerOutgoingWebhook(u user.User) control.UserActionResponse {
+	postId, err := u.CreatePost(&model.Post{
+		ChannelId: c.integrations.channelId,
+		Message:   c.integrations.hookTrigger + " " + genMessage(),
+	})
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("triggered outgoing webhook with post %s", postId)}
+}
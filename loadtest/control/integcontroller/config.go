@@ -0,0 +1,54 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package integcontroller
+
+import (
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+)
+
+// Config holds information needed to run an IntegController.
+type Config struct {
+	// The minium amount of time (in milliseconds) the controlled users
+	// will wait between actions.
+	MinIdleTimeMs int `default:"1000" validate:"range:[0,]"`
+	// The average amount of time (in milliseconds) the controlled users
+	// will wait between actions.
+	AvgIdleTimeMs int `default:"10000" validate:"range:($MinIdleTimeMs,]"`
+	// The distribution used to pick the amount of time the controlled users
+	// will wait between actions.
+	// Possible values:
+	//   IdleTimeUniform - Uniformly distributed in [MinIdleTimeMs, 2*AvgIdleTimeMs-MinIdleTimeMs).
+	//   IdleTimeExponential - Exponentially distributed, shifted by MinIdleTimeMs.
+	//   IdleTimeLogNormal - Log-normally distributed, shifted by MinIdleTimeMs.
+	//   IdleTimeFixed - Always equal to AvgIdleTimeMs.
+	IdleTimeDistribution control.IdleTimeDistribution `default:"uniform" validate:"oneof:{uniform,exponential,lognormal,fixed}"`
+	// The address the integrations receiver started by the agent listens on.
+	ReceiverListenAddress string `default:"0.0.0.0:4100" validate:"notempty"`
+	// The URL at which the Mattermost server reaches the integrations
+	// receiver. Custom slash commands and outgoing webhooks created by the
+	// controlled users send their requests to it. It has no default since
+	// the server usually runs on a different host than the agent. When the
+	// agents are driven by the coordinator and it's empty, it's derived from
+	// the address of each agent.
+	ReceiverURL string `validate:"url"`
+	// CreateBots makes each controlled user create a bot account, whose
+	// username is used to attribute the messages posted by its integrations.
+	CreateBots bool `default:"true"`
+	// Actions holds the frequencies of the actions the controlled users will
+	// perform. If empty, the default frequencies are used.
+	Actions []control.ActionDefinition
+}
+
+// ReadConfig reads the configuration file from the given string. If the string
+// is empty, it will return a config with default values.
+func ReadConfig(configFilePath string) (*Config, error) {
+	var cfg Config
+
+	if err := defaults.ReadFromJSON(configFilePath, "./config/integcontroller.json", &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
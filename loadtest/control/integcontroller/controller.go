@@ -0,0 +1,243 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package integcontroller
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+	"github.com/mattermost/mattermost-load-test-ng/performance"
+)
+
+// IntegController is an implementation of a UserController that drives
+// traffic through integrations: custom and built-in slash commands, incoming
+// and outgoing webhooks. The requests the server sends to the integrations
+// are answered by a Receiver started by the agent.
+type IntegController struct {
+	id           int
+	user         user.User
+	status       chan<- control.UserStatus
+	rate         float64
+	rateMut      sync.RWMutex
+	config       *Config
+	actions      []control.WeightedAction
+	metrics      *performance.ControllerMetrics
+	integrations *integrations      // the integrations created by the user
+	stopChan     chan struct{}      // this channel coordinates the stop sequence of the controller
+	pauseState   control.PauseState // suspends the execution of actions while paused
+	stoppedChan  chan struct{}      // blocks until controller cleans up everything
+	connected    bool               // indicates that the controller is connected
+	wg           *sync.WaitGroup    // to keep the track of every goroutine created by the controller
+}
+
+// New creates and initializes a new IntegController with given parameters.
+// An id is provided to identify the controller, a User is passed as the entity to be controlled and
+// a UserStatus channel is passed to communicate errors and information about the user's status.
+func New(id int, user user.User, config *Config, status chan<- control.UserStatus) (*IntegController, error) {
+	if config == nil || user == nil {
+		return nil, errors.New("nil params passed")
+	}
+
+	if err := defaults.Validate(config); err != nil {
+		return nil, fmt.Errorf("could not validate configuration: %w", err)
+	}
+
+	controller := &IntegController{
+		id:          id,
+		user:        user,
+		status:      status,
+		rate:        1.0,
+		config:      config,
+		stopChan:    make(chan struct{}),
+		stoppedChan: make(chan struct{}),
+		wg:          &sync.WaitGroup{},
+	}
+
+	actions, err := createActions(controller, config.Actions)
+	if err != nil {
+		return nil, fmt.Errorf("could not create actions: %w", err)
+	}
+	controller.actions = actions
+
+	return controller, nil
+}
+
+// Run begins performing a set of user actions in a loop.
+// It keeps on doing it until Stop() is invoked.
+// This is also a blocking function, so it is recommended to invoke it
+// inside a goroutine.
+func (c *IntegController) Run() {
+	if c.user == nil {
+		c.sendFailStatus("controller was not initialized")
+		return
+	}
+
+	c.status <- control.UserStatus{ControllerId: c.id, User: c.user, Info: "user started", Code: control.USER_STATUS_STARTED}
+
+	defer func() {
+		if resp := c.logout(); resp.Err != nil {
+			c.status <- c.newErrorStatus(resp.Err)
+		}
+		c.user.ClearUserData()
+		c.sendStopStatus()
+		close(c.stoppedChan)
+	}()
+
+	initActions := []control.WeightedAction{
+		{
+			Name: "SignUp",
+			Run:  control.SignUp,
+		},
+		{
+			Name: "Login",
+			Run:  c.login,
+		},
+		{
+			Name: "JoinTeam",
+			Run:  control.JoinTeam,
+		},
+		{
+			Name: "SetupIntegrations",
+			Run:  c.setupIntegrations,
+		},
+	}
+
+	for i := 0; i < len(initActions); i++ {
+		select {
+		case <-c.stopChan:
+			return
+		case <-time.After(control.PickIdleTimeMs(c.config.IdleTimeDistribution, c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, 1.0)):
+		}
+
+		if !c.pauseState.Wait(c.stopChan) {
+			return
+		}
+
+		if resp := c.runAction(&initActions[i]); resp.Err != nil {
+			c.status <- c.newErrorStatus(resp.Err)
+			i--
+		} else {
+			c.status <- c.newInfoStatus(resp.Info)
+		}
+	}
+
+	for {
+		action, err := control.PickAction(c.actions)
+		if err != nil {
+			panic(fmt.Sprintf("integcontroller: failed to pick action %s", err.Error()))
+		}
+
+		if resp := c.runAction(action); resp.Err != nil {
+			c.status <- c.newErrorStatus(resp.Err)
+		} else {
+			c.status <- c.newInfoStatus(resp.Info)
+		}
+
+		select {
+		case <-c.stopChan:
+			return
+		case <-time.After(control.PickIdleTimeMs(c.config.IdleTimeDistribution, c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, c.getRate())):
+		}
+
+		if !c.pauseState.Wait(c.stopChan) {
+			return
+		}
+	}
+}
+
+// runAction performs the given action, recording its execution time and
+// outcome.
+func (c *IntegController) runAction(action *control.WeightedAction) control.UserActionResponse {
+	start := time.Now()
+	resp := action.Run(c.user)
+	c.metrics.ObserveAction(action.Name, time.Since(start), resp.Err)
+	return resp
+}
+
+func (c *IntegController) connect() error {
+	if c.connected {
+		return errors.New("already connected")
+	}
+	errChan, err := c.user.Connect()
+	if err != nil {
+		return fmt.Errorf("connect failed %w", err)
+	}
+	c.connected = true
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		for err := range errChan {
+			c.status <- c.newErrorStatus(err)
+		}
+	}()
+	go c.wsEventHandler(c.wg)
+	return nil
+}
+
+func (c *IntegController) disconnect() error {
+	if !c.connected {
+		return nil
+	}
+	c.connected = false
+	if err := c.user.Disconnect(); err != nil {
+		return fmt.Errorf("disconnect failed %w", err)
+	}
+	c.wg.Wait()
+	return nil
+}
+
+// SetMetrics sets the metrics the controller emits for each user action.
+// It should be called before Run.
+func (c *IntegController) SetMetrics(metrics *performance.ControllerMetrics) {
+	c.metrics = metrics
+}
+
+// SetRate sets the relative speed of execution of actions by the user.
+func (c *IntegController) SetRate(rate float64) error {
+	if rate < 0 {
+		return errors.New("rate should be a positive value")
+	}
+	c.rateMut.Lock()
+	defer c.rateMut.Unlock()
+	c.rate = rate
+	return nil
+}
+
+func (c *IntegController) getRate() float64 {
+	c.rateMut.RLock()
+	defer c.rateMut.RUnlock()
+	return c.rate
+}
+
+// Pause suspends the execution of user actions until Resume is called.
+func (c *IntegController) Pause() {
+	c.pauseState.Pause()
+}
+
+// Resume resumes the execution of user actions.
+func (c *IntegController) Resume() {
+	c.pauseState.Resume()
+}
+
+// Stop stops the controller.
+func (c *IntegController) Stop() {
+	close(c.stopChan)
+	<-c.stoppedChan
+	// re-initialize for the next use
+	c.stopChan = make(chan struct{})
+	c.stoppedChan = make(chan struct{})
+}
+
+func (c *IntegController) sendFailStatus(reason string) {
+	c.status <- control.UserStatus{ControllerId: c.id, User: c.user, Code: control.USER_STATUS_FAILED, Err: errors.New(reason)}
+}
+
+func (c *IntegController) sendStopStatus() {
+	c.status <- control.UserStatus{ControllerId: c.id, User: c.user, Info: "user stopped", Code: control.USER_STATUS_STOPPED}
+}
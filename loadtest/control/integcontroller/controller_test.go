@@ -0,0 +1,83 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package integcontroller
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user/userentity"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRate(t *testing.T) {
+	config, err := ReadConfig("../../../config/integcontroller.sample.json")
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	config.ReceiverURL = "http://localhost:4100"
+
+	c, err := New(1, &userentity.UserEntity{}, config, make(chan control.UserStatus))
+	require.Nil(t, err)
+	require.Equal(t, 1.0, c.rate)
+
+	err = c.SetRate(-1.0)
+	require.NotNil(t, err)
+	require.Equal(t, 1.0, c.rate)
+
+	err = c.SetRate(0.0)
+	require.Nil(t, err)
+	require.Equal(t, 0.0, c.rate)
+
+	err = c.SetRate(1.5)
+	require.Nil(t, err)
+	require.Equal(t, 1.5, c.rate)
+}
+
+func TestCreateActions(t *testing.T) {
+	config, err := ReadConfig("../../../config/integcontroller.sample.json")
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	config.ReceiverURL = "http://localhost:4100"
+
+	t.Run("default frequencies", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = nil
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.NoError(t, err)
+		defaultActions := getActionList(c)
+		require.Len(t, c.actions, len(defaultActions))
+		for i := range defaultActions {
+			require.Equal(t, defaultActions[i].Name, c.actions[i].Name)
+			require.Equal(t, defaultActions[i].Frequency, c.actions[i].Frequency)
+		}
+	})
+
+	t.Run("custom frequencies", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = []control.ActionDefinition{
+			{ActionId: "ExecuteCommand", Frequency: 10},
+		}
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.NoError(t, err)
+		require.Len(t, c.actions, len(getActionList(c)))
+		for _, action := range c.actions {
+			if action.Name == "ExecuteCommand" {
+				require.Equal(t, 10, action.Frequency)
+			} else {
+				require.Zero(t, action.Frequency)
+			}
+		}
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = []control.ActionDefinition{
+			{ActionId: "CreatePost", Frequency: 10},
+		}
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.Error(t, err)
+		require.Nil(t, c)
+	})
+}
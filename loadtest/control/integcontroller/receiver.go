@@ -0,0 +1,124 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package integcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// Paths served by the Receiver.
+const (
+	commandsPath = "/commands"
+	hooksPath    = "/hooks"
+)
+
+// shutdownTimeout is the maximum amount of time the receiver waits for the
+// requests in flight to complete when closed.
+const shutdownTimeout = 10 * time.Second
+
+// Receiver is a stand-in for the external services integrations talk to.
+// It answers the requests the Mattermost server sends when executing the
+// custom slash commands and outgoing webhooks created by the controlled users.
+// A single Receiver is started by the agent and shared by all of its users.
+type Receiver struct {
+	server      *http.Server
+	listener    net.Listener
+	numRequests int64
+}
+
+// NewReceiver creates a Receiver and starts serving requests on the given
+// address.
+func NewReceiver(listenAddress string) (*Receiver, error) {
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("integcontroller: could not start receiver: %w", err)
+	}
+
+	r := &Receiver{listener: listener}
+	mux := http.NewServeMux()
+	mux.HandleFunc(commandsPath, r.commandHandler)
+	mux.HandleFunc(hooksPath, r.hookHandler)
+	r.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := r.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			mlog.Error("integcontroller: receiver stopped unexpectedly", mlog.Err(err))
+		}
+	}()
+
+	mlog.Info("integcontroller: receiver started", mlog.String("address", listener.Addr().String()))
+
+	return r, nil
+}
+
+// Addr returns the address the receiver is listening on.
+func (r *Receiver) Addr() string {
+	return r.listener.Addr().String()
+}
+
+// NumRequests returns the number of requests the receiver has handled.
+func (r *Receiver) NumRequests() int64 {
+	return atomic.LoadInt64(&r.numRequests)
+}
+
+// Close gracefully stops the receiver, waiting up to shutdownTimeout for the
+// requests in flight to complete before closing their connections.
+func (r *Receiver) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := r.server.Shutdown(ctx)
+	if err == context.DeadlineExceeded {
+		err = r.server.Close()
+	}
+	mlog.Info("integcontroller: receiver stopped", mlog.Int64("num_requests", r.NumRequests()))
+	return err
+}
+
+// commandHandler answers the requests sent by custom slash commands, posting
+// a message in the channel the command was executed in.
+func (r *Receiver) commandHandler(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt64(&r.numRequests, 1)
+
+	// The command arguments are sent as form values.
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := &model.CommandResponse{
+		ResponseType: model.COMMAND_RESPONSE_TYPE_IN_CHANNEL,
+		Text:         genMessage(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(resp.ToJson()))
+}
+
+// hookHandler answers the requests sent by outgoing webhooks, replying to the
+// post that triggered them.
+func (r *Receiver) hookHandler(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt64(&r.numRequests, 1)
+
+	var payload model.OutgoingWebhookPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	text := genMessage()
+	resp := &model.OutgoingWebhookResponse{
+		Text:         &text,
+		ResponseType: model.OUTGOING_HOOK_RESPONSE_TYPE_COMMENT,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(resp.ToJson()))
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package integcontroller
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiver(t *testing.T) {
+	r, err := NewReceiver("127.0.0.1:0")
+	require.NoError(t, err)
+	defer r.Close()
+
+	baseURL := "http://" + r.Addr()
+
+	t.Run("command", func(t *testing.T) {
+		resp, err := http.PostForm(baseURL+commandsPath, url.Values{"command": {"/test"}, "text": {"hello"}})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		cmdResp, err := model.CommandResponseFromHTTPBody(resp.Header.Get("Content-Type"), resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, model.COMMAND_RESPONSE_TYPE_IN_CHANNEL, cmdResp.ResponseType)
+		require.NotEmpty(t, cmdResp.Text)
+	})
+
+	t.Run("outgoing webhook", func(t *testing.T) {
+		payload := &model.OutgoingWebhookPayload{Text: "trigger hello", TriggerWord: "trigger"}
+		resp, err := http.Post(baseURL+hooksPath, "application/json", strings.NewReader(payload.ToJSON()))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		hookResp, err := model.OutgoingWebhookResponseFromJson(resp.Body)
+		require.NoError(t, err)
+		require.NotNil(t, hookResp.Text)
+		require.NotEmpty(t, *hookResp.Text)
+		require.Equal(t, model.OUTGOING_HOOK_RESPONSE_TYPE_COMMENT, hookResp.ResponseType)
+	})
+
+	t.Run("invalid outgoing webhook payload", func(t *testing.T) {
+		resp, err := http.Post(baseURL+hooksPath, "application/json", strings.NewReader("{"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	require.Equal(t, int64(3), r.NumRequests())
+}
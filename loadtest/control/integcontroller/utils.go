@@ -0,0 +1,16 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package integcontroller
+
+import (
+	"math/rand"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+)
+
+// genMessage returns a short random message, like the ones exchanged with
+// integrations.
+func genMessage() string {
+	return control.GenerateRandomSentences(rand.Intn(15) + 1)
+}
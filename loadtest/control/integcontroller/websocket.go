@@ -0,0 +1,17 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package integcontroller
+
+import (
+	"sync"
+)
+
+// wsEventHandler consumes the WebSocket events received by the user.
+// Integration traffic doesn't depend on them, but they still need to be read
+// for the user to keep receiving them.
+func (c *IntegController) wsEventHandler(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for range c.user.Events() {
+	}
+}
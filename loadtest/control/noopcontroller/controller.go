@@ -6,6 +6,7 @@ package noopcontroller
 import (
 	"errors"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
@@ -15,12 +16,14 @@ import (
 // NoopController is a very basic implementation of a controller.
 // NoopController, it just performs a pre-defined set of actions in a loop.
 type NoopController struct {
-	id      int
-	user    user.User
-	stop    chan struct{}
-	stopped chan struct{}
-	status  chan<- control.UserStatus
-	rate    float64
+	id         int
+	user       user.User
+	stop       chan struct{}
+	pauseState control.PauseState
+	stopped    chan struct{}
+	status     chan<- control.UserStatus
+	rate       float64
+	rateMut    sync.RWMutex
 }
 
 // New creates and initializes a new SimpleController with given parameters.
@@ -47,9 +50,6 @@ func (c *NoopController) Run() {
 		return
 	}
 
-	// Start listening for websocket events.
-	go c.wsEventHandler()
-
 	c.status <- control.UserStatus{ControllerId: c.id, User: c.user, Info: "user started", Code: control.USER_STATUS_STARTED}
 
 	defer func() {
@@ -80,6 +80,9 @@ func (c *NoopController) Run() {
 				c.status <- c.newErrorStatus(err)
 			}
 		}()
+		// Start listening for websocket events. The channel of events is
+		// only created on connection.
+		go c.wsEventHandler()
 	}
 
 	for {
@@ -90,13 +93,17 @@ func (c *NoopController) Run() {
 			c.status <- c.newInfoStatus(res)
 		}
 
-		idleTime := time.Duration(math.Round(float64(1000) * c.rate))
+		idleTime := time.Duration(math.Round(float64(1000) * c.getRate()))
 
 		select {
 		case <-c.stop:
 			return
 		case <-time.After(time.Millisecond * idleTime):
 		}
+
+		if !c.pauseState.Wait(c.stop) {
+			return
+		}
 	}
 }
 
@@ -105,10 +112,28 @@ func (c *NoopController) SetRate(rate float64) error {
 	if rate < 0 {
 		return errors.New("rate should be a positive value")
 	}
+	c.rateMut.Lock()
+	defer c.rateMut.Unlock()
 	c.rate = rate
 	return nil
 }
 
+func (c *NoopController) getRate() float64 {
+	c.rateMut.RLock()
+	defer c.rateMut.RUnlock()
+	return c.rate
+}
+
+// Pause suspends the execution of user actions until Resume is called.
+func (c *NoopController) Pause() {
+	c.pauseState.Pause()
+}
+
+// Resume resumes the execution of user actions.
+func (c *NoopController) Resume() {
+	c.pauseState.Resume()
+}
+
 // Stop stops the controller.
 func (c *NoopController) Stop() {
 	close(c.stop)
@@ -0,0 +1,62 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package control
+
+import (
+	"sync"
+)
+
+// PauseState is a helper that UserController implementations can use to
+// suspend their action loop. The zero value is ready to use and not paused.
+type PauseState struct {
+	mut        sync.Mutex
+	resumeChan chan struct{} // non-nil while paused, closed on resume.
+}
+
+// Pause marks the state as paused. Calling it while already paused has no
+// effect.
+func (p *PauseState) Pause() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.resumeChan == nil {
+		p.resumeChan = make(chan struct{})
+	}
+}
+
+// Resume marks the state as not paused, unblocking any pending call to Wait.
+// Calling it while not paused has no effect.
+func (p *PauseState) Resume() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.resumeChan != nil {
+		close(p.resumeChan)
+		p.resumeChan = nil
+	}
+}
+
+// IsPaused reports whether the state is currently paused.
+func (p *PauseState) IsPaused() bool {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.resumeChan != nil
+}
+
+// Wait blocks for as long as the state is paused. It returns false if
+// stopChan gets closed while waiting, true otherwise.
+func (p *PauseState) Wait(stopChan <-chan struct{}) bool {
+	p.mut.Lock()
+	resumeChan := p.resumeChan
+	p.mut.Unlock()
+
+	if resumeChan == nil {
+		return true
+	}
+
+	select {
+	case <-stopChan:
+		return false
+	case <-resumeChan:
+		return true
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseState(t *testing.T) {
+	t.Run("NotPaused", func(t *testing.T) {
+		var p PauseState
+		require.False(t, p.IsPaused())
+		require.True(t, p.Wait(make(chan struct{})))
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		var p PauseState
+		p.Pause()
+		p.Pause()
+		require.True(t, p.IsPaused())
+
+		done := make(chan bool)
+		go func() {
+			done <- p.Wait(make(chan struct{}))
+		}()
+
+		select {
+		case <-done:
+			require.FailNow(t, "Wait should block while paused")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		p.Resume()
+		p.Resume()
+		require.False(t, p.IsPaused())
+		require.True(t, <-done)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		var p PauseState
+		p.Pause()
+		stopChan := make(chan struct{})
+		close(stopChan)
+		require.False(t, p.Wait(stopChan))
+	})
+}
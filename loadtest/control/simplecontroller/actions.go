@@ -17,6 +17,7 @@ import (
 )
 
 type UserAction struct {
+	name      string
 	run       control.UserAction
 	waitAfter time.Duration
 	runPeriod int
@@ -81,7 +82,7 @@ func (c *SimpleController) scrollChannel(u user.User) control.UserActionResponse
 			return control.UserActionResponse{Err: control.NewUserError(err)}
 		}
 		postId = posts[0].Id // get the newest post
-		idleTime := time.Duration(math.Round(float64(SLEEP_BETWEEN_SCROLL) * c.rate))
+		idleTime := time.Duration(math.Round(float64(SLEEP_BETWEEN_SCROLL) * c.getRate()))
 		time.Sleep(time.Millisecond * idleTime)
 	}
 	return control.UserActionResponse{Info: fmt.Sprintf("scrolled channel %v %d times", channel.Id, NUM_OF_SCROLLS)}
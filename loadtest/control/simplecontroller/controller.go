@@ -12,6 +12,7 @@ import (
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+	"github.com/mattermost/mattermost-load-test-ng/performance"
 )
 
 // SimpleController is a very basic implementation of a controller.
@@ -21,11 +22,14 @@ type SimpleController struct {
 	user          user.User
 	status        chan<- control.UserStatus
 	rate          float64
+	rateMut       sync.RWMutex
 	actions       []*UserAction
-	stopChan      chan struct{}   // this channel coordinates the stop sequence of the controller
-	stoppedChan   chan struct{}   // blocks until controller cleans up everything
-	connectedFlag int32           // indicates that the controller is connected
-	wg            *sync.WaitGroup // to keep the track of every goroutine created by the controller
+	metrics       *performance.ControllerMetrics
+	stopChan      chan struct{}      // this channel coordinates the stop sequence of the controller
+	pauseState    control.PauseState // suspends the execution of actions while paused
+	stoppedChan   chan struct{}      // blocks until controller cleans up everything
+	connectedFlag int32              // indicates that the controller is connected
+	wg            *sync.WaitGroup    // to keep the track of every goroutine created by the controller
 }
 
 // New creates and initializes a new SimpleController with given parameters.
@@ -74,9 +78,11 @@ func (c *SimpleController) Run() {
 
 	initActions := []UserAction{
 		{
-			run: control.SignUp,
+			name: "SignUp",
+			run:  control.SignUp,
 		},
 		{
+			name: "Login",
 			run: func(u user.User) control.UserActionResponse {
 				resp := control.Login(u)
 				if resp.Err != nil {
@@ -88,8 +94,8 @@ func (c *SimpleController) Run() {
 		},
 	}
 
-	for _, action := range initActions {
-		if resp := action.run(c.user); resp.Err != nil {
+	for i := range initActions {
+		if resp := c.runAction(&initActions[i]); resp.Err != nil {
 			c.status <- c.newErrorStatus(resp.Err)
 		} else {
 			c.status <- c.newInfoStatus(resp.Info)
@@ -113,34 +119,71 @@ func (c *SimpleController) Run() {
 			if cycleCount%c.actions[i].runPeriod == 0 {
 				// run the action if runPeriod is not set, or else it's set and it's a multiple
 				// of the cycle count.
-				if resp := c.actions[i].run(c.user); resp.Err != nil {
+				if resp := c.runAction(c.actions[i]); resp.Err != nil {
 					c.status <- c.newErrorStatus(resp.Err)
 				} else {
 					c.status <- c.newInfoStatus(resp.Info)
 				}
 
-				idleTime := time.Duration(math.Round(float64(c.actions[i].waitAfter) * c.rate))
+				idleTime := time.Duration(math.Round(float64(c.actions[i].waitAfter) * c.getRate()))
 
 				select {
 				case <-c.stopChan:
 					return
 				case <-time.After(time.Millisecond * idleTime):
 				}
+
+				if !c.pauseState.Wait(c.stopChan) {
+					return
+				}
 			}
 		}
 		cycleCount++
 	}
 }
 
+// runAction performs the given action, recording its execution time and
+// outcome.
+func (c *SimpleController) runAction(action *UserAction) control.UserActionResponse {
+	start := time.Now()
+	resp := action.run(c.user)
+	c.metrics.ObserveAction(action.name, time.Since(start), resp.Err)
+	return resp
+}
+
+// SetMetrics sets the metrics the controller emits for each user action.
+// It should be called before Run.
+func (c *SimpleController) SetMetrics(metrics *performance.ControllerMetrics) {
+	c.metrics = metrics
+}
+
 // SetRate sets the relative speed of execution of actions by the user.
 func (c *SimpleController) SetRate(rate float64) error {
 	if rate < 0 {
 		return errors.New("rate should be a positive value")
 	}
+	c.rateMut.Lock()
+	defer c.rateMut.Unlock()
 	c.rate = rate
 	return nil
 }
 
+func (c *SimpleController) getRate() float64 {
+	c.rateMut.RLock()
+	defer c.rateMut.RUnlock()
+	return c.rate
+}
+
+// Pause suspends the execution of user actions until Resume is called.
+func (c *SimpleController) Pause() {
+	c.pauseState.Pause()
+}
+
+// Resume resumes the execution of user actions.
+func (c *SimpleController) Resume() {
+	c.pauseState.Resume()
+}
+
 // Stop stops the controller.
 func (c *SimpleController) Stop() {
 	close(c.stopChan)
@@ -226,6 +269,7 @@ func (c *SimpleController) createActions(definitions []actionDefinition) error {
 		}
 
 		actions = append(actions, &UserAction{
+			name:      def.ActionId,
 			run:       run,
 			waitAfter: time.Duration(def.WaitAfterMs),
 			runPeriod: def.RunPeriod,
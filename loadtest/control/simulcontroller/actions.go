@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,9 +21,58 @@ import (
 	"github.com/mattermost/mattermost-server/v5/model"
 )
 
-type userAction struct {
-	run       control.UserAction
-	frequency int
+// getActionList returns the list of all the actions the controller can
+// perform, along with their default frequencies.
+func getActionList(c *SimulController) []control.WeightedAction {
+	return []control.WeightedAction{
+		{Name: "SwitchChannel", Run: switchChannel, Frequency: 70},
+		{Name: "SwitchTeam", Run: c.switchTeam, Frequency: 30},
+		{Name: "OpenDirectOrGroupChannel", Run: openDirectOrGroupChannel, Frequency: 30},
+		{Name: "UnreadCheck", Run: unreadCheck, Frequency: 25},
+		{Name: "CreatePost", Run: c.createPost, Frequency: 25},
+		{Name: "CreatePostReply", Run: c.createPostReply, Frequency: 15},
+		{Name: "JoinChannel", Run: c.joinChannel, Frequency: 8},
+		{Name: "EditPost", Run: editPost, Frequency: 8},
+		{Name: "SearchChannels", Run: searchChannels, Frequency: 5},
+		{Name: "AddReaction", Run: c.addReaction, Frequency: 5},
+		{Name: "FullReload", Run: c.fullReload, Frequency: 4},
+		{Name: "CreateDirectChannel", Run: c.createDirectChannel, Frequency: 2},
+		{Name: "CreateGroupChannel", Run: c.createGroupChannel, Frequency: 1},
+		{Name: "LogoutLogin", Run: c.logoutLogin, Frequency: 1},
+		// ViewThread requires Collaborative Threads to be enabled on the
+		// server, so it only runs when given a frequency in the config.
+		{Name: "ViewThread", Run: viewThread, Frequency: 0},
+		// UploadAttachment and BrowseFiles add file traffic on top of the
+		// default workload, so they only run when given a frequency in the config.
+		{Name: "UploadAttachment", Run: c.uploadAttachment, Frequency: 0},
+		{Name: "BrowseFiles", Run: browseFiles, Frequency: 0},
+		// SearchFiles requires file search support on the server, so it only
+		// runs when given a frequency in the config.
+		{Name: "SearchFiles", Run: searchFiles, Frequency: 0},
+		// UpdateStatus changes the user's presence and triggers status_change
+		// events, so it only runs when given a frequency in the config.
+		{Name: "UpdateStatus", Run: updateStatus, Frequency: 0},
+		// SetCustomStatus requires custom status support on the server, so it
+		// only runs when given a frequency in the config.
+		{Name: "SetCustomStatus", Run: setCustomStatus, Frequency: 0},
+		// MuteChannel and UpdateChannelNotifyProps change the notifications
+		// received by the user, so they only run when given a frequency in the config.
+		{Name: "MuteChannel", Run: muteChannel, Frequency: 0},
+		{Name: "UpdateChannelNotifyProps", Run: updateChannelNotifyProps, Frequency: 0},
+	}
+}
+
+// createActions returns the list of actions to be run by the controller with
+// their frequencies set according to the given definitions.
+// If no definitions are given the default frequencies are used. Otherwise,
+// actions that are not defined will never run.
+func createActions(c *SimulController, definitions []control.ActionDefinition) ([]control.WeightedAction, error) {
+	actions := getActionList(c)
+	if err := control.SetActionFrequencies(actions, definitions); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
 }
 
 func (c *SimulController) connect() error {
@@ -122,7 +173,7 @@ func (c *SimulController) login(u user.User) control.UserActionResponse {
 		select {
 		case <-c.stopChan:
 			return control.UserActionResponse{Info: "login canceled"}
-		case <-time.After(pickIdleTimeMs(c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, 1.0)):
+		case <-time.After(control.PickIdleTimeMs(c.config.IdleTimeDistribution, c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, 1.0)):
 		}
 	}
 }
@@ -870,3 +921,281 @@ func searchChannels(u user.User) control.UserActionResponse {
 		return control.UserActionResponse{Info: fmt.Sprintf("found %d channels", len(channels))}
 	})
 }
+
+func viewThread(u user.User) control.UserActionResponse {
+	team, err := u.Store().CurrentTeam()
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	} else if team == nil {
+		return control.UserActionResponse{Err: control.NewUserError(fmt.Errorf("current team should be set"))}
+	}
+
+	if _, err := u.GetUserThreads(team.Id, false, 25); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	thread, err := u.Store().RandomThread()
+	if errors.Is(err, memstore.ErrThreadNotFound) {
+		return control.UserActionResponse{Info: "no thread to view"}
+	} else if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	if _, err := u.GetPostThread(thread.PostId); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	if err := u.UpdateThreadRead(team.Id, thread.PostId, model.GetMillis()); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	// Occasionally, the user is no longer interested in the thread.
+	if rand.Float64() < 0.05 {
+		if err := u.UpdateThreadFollow(team.Id, thread.PostId, false); err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+		return control.UserActionResponse{Info: fmt.Sprintf("viewed and unfollowed thread %s", thread.PostId)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("viewed thread %s", thread.PostId)}
+}
+
+func (c *SimulController) uploadAttachment(u user.User) control.UserActionResponse {
+	channel, err := u.Store().CurrentChannel()
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	sizeKB, err := pickFileSizeKB(c.config.FileSizeDistribution)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	filename, data := genFile(sizeKB)
+	resp, err := u.UploadFile(data, channel.Id, filename)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	message, err := createMessage(u, channel, false)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	post := &model.Post{
+		Message:   message,
+		ChannelId: channel.Id,
+		CreateAt:  time.Now().Unix() * 1000,
+	}
+	for _, info := range resp.FileInfos {
+		post.FileIds = append(post.FileIds, info.Id)
+	}
+
+	postId, err := u.CreatePost(post)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("post created with a %d KB attachment, id %v", sizeKB, postId)}
+}
+
+func browseFiles(u user.User) control.UserActionResponse {
+	info, err := u.Store().RandomFileInfo()
+	if errors.Is(err, memstore.ErrFileInfoNotFound) {
+		return control.UserActionResponse{Info: "no file to browse"}
+	} else if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	if _, err := u.GetFileInfo(info.Id); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	// Images are opened in the previewer, and only some of them are
+	// downloaded afterwards. Other files are always downloaded.
+	if info.HasPreviewImage {
+		if err := u.GetFilePreview(info.Id); err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+		if rand.Float64() > 0.2 {
+			return control.UserActionResponse{Info: fmt.Sprintf("previewed file %s", info.Id)}
+		}
+	}
+
+	if err := u.DownloadFile(info.Id); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("downloaded file %s", info.Id)}
+}
+
+func searchFiles(u user.User) control.UserActionResponse {
+	team, err := u.Store().CurrentTeam()
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	} else if team == nil {
+		return control.UserActionResponse{Err: control.NewUserError(fmt.Errorf("current team should be set"))}
+	}
+
+	// Half of the times the user searches the files shared in a channel.
+	if rand.Float64() < 0.5 {
+		channel, err := u.Store().RandomChannel(team.Id, store.SelectMemberOf)
+		if errors.Is(err, memstore.ErrChannelStoreEmpty) {
+			return control.UserActionResponse{Info: "no channel to list files from"}
+		} else if err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+
+		// File searches are scoped to a team, so direct and group channels
+		// can't be searched.
+		if channel.TeamId == "" {
+			return control.UserActionResponse{Info: "skipping search in non-team channel"}
+		}
+
+		infos, err := u.SearchFilesInChannel(channel.Id)
+		if err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+		return control.UserActionResponse{Info: fmt.Sprintf("found %d files in channel %s", len(infos), channel.Id)}
+	}
+
+	term := "attachment"
+	if info, err := u.Store().RandomFileInfo(); err == nil {
+		term = strings.TrimSuffix(info.Name, filepath.Ext(info.Name))
+	}
+
+	infos, err := u.SearchFiles(team.Id, term, false)
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("found %d files", len(infos))}
+}
+
+func updateStatus(u user.User) control.UserActionResponse {
+	status, err := u.Store().Status(u.Store().Id())
+	if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	// Users who have stepped away come back online. Otherwise, they mostly
+	// go away or ask not to be disturbed, and less often appear offline.
+	newStatus := model.STATUS_ONLINE
+	if status.Status == "" || status.Status == model.STATUS_ONLINE {
+		statuses := []string{model.STATUS_AWAY, model.STATUS_DND, model.STATUS_OFFLINE}
+		idx, err := control.SelectWeighted([]int{50, 40, 10})
+		if err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+		newStatus = statuses[idx]
+	}
+
+	if err := u.UpdateUserStatus(newStatus); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("status set to %s", newStatus)}
+}
+
+func setCustomStatus(u user.User) control.UserActionResponse {
+	// 30% of the times the user clears the custom status.
+	if rand.Float64() < 0.3 {
+		if err := u.RemoveCustomStatus(); err != nil {
+			return control.UserActionResponse{Err: control.NewUserError(err)}
+		}
+		return control.UserActionResponse{Info: "custom status removed"}
+	}
+
+	customStatuses := []user.CustomStatus{
+		{Emoji: "calendar", Text: "In a meeting"},
+		{Emoji: "hamburger", Text: "Out for lunch"},
+		{Emoji: "house", Text: "Working from home"},
+		{Emoji: "car", Text: "Commuting"},
+		{Emoji: "palm_tree", Text: "On vacation"},
+		{Emoji: "sneezing_face", Text: "Out sick"},
+	}
+	customStatus := customStatuses[rand.Intn(len(customStatuses))]
+	if err := u.UpdateCustomStatus(&customStatus); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("custom status set to %q", customStatus.Text)}
+}
+
+// randomChannelMember returns the user's membership of a random channel in the
+// current team, fetching it from the server if it isn't stored yet.
+func randomChannelMember(u user.User) (model.ChannelMember, error) {
+	team, err := u.Store().CurrentTeam()
+	if err != nil {
+		return model.ChannelMember{}, err
+	} else if team == nil {
+		return model.ChannelMember{}, fmt.Errorf("current team should be set")
+	}
+
+	channel, err := u.Store().RandomChannel(team.Id, store.SelectMemberOf)
+	if err != nil {
+		return model.ChannelMember{}, err
+	}
+
+	cm, err := u.Store().ChannelMember(channel.Id, u.Store().Id())
+	if err != nil {
+		return model.ChannelMember{}, err
+	} else if cm.UserId != "" {
+		return cm, nil
+	}
+
+	if err := u.GetChannelMember(channel.Id, u.Store().Id()); err != nil {
+		return model.ChannelMember{}, err
+	}
+
+	return u.Store().ChannelMember(channel.Id, u.Store().Id())
+}
+
+func muteChannel(u user.User) control.UserActionResponse {
+	cm, err := randomChannelMember(u)
+	if errors.Is(err, memstore.ErrChannelStoreEmpty) {
+		return control.UserActionResponse{Info: "no channel to mute"}
+	} else if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	// Muted channels only get marked as unread on mentions, so muting a
+	// channel that already is unmutes it.
+	markUnread, info := model.CHANNEL_MARK_UNREAD_MENTION, "muted"
+	if cm.NotifyProps[model.MARK_UNREAD_NOTIFY_PROP] == model.CHANNEL_MARK_UNREAD_MENTION {
+		markUnread, info = model.CHANNEL_MARK_UNREAD_ALL, "unmuted"
+	}
+
+	props := map[string]string{model.MARK_UNREAD_NOTIFY_PROP: markUnread}
+	if err := u.UpdateChannelNotifyProps(cm.ChannelId, cm.UserId, props); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("channel %s %s", cm.ChannelId, info)}
+}
+
+func updateChannelNotifyProps(u user.User) control.UserActionResponse {
+	cm, err := randomChannelMember(u)
+	if errors.Is(err, memstore.ErrChannelStoreEmpty) {
+		return control.UserActionResponse{Info: "no channel to update notification preferences for"}
+	} else if err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	levels := []string{
+		model.CHANNEL_NOTIFY_DEFAULT,
+		model.CHANNEL_NOTIFY_ALL,
+		model.CHANNEL_NOTIFY_MENTION,
+		model.CHANNEL_NOTIFY_NONE,
+	}
+	props := map[string]string{
+		model.DESKTOP_NOTIFY_PROP: levels[rand.Intn(len(levels))],
+		model.PUSH_NOTIFY_PROP:    levels[rand.Intn(len(levels))],
+	}
+	if err := u.UpdateChannelNotifyProps(cm.ChannelId, cm.UserId, props); err != nil {
+		return control.UserActionResponse{Err: control.NewUserError(err)}
+	}
+
+	return control.UserActionResponse{Info: fmt.Sprintf("channel %s notifications set to desktop %s, push %s",
+		cm.ChannelId, props[model.DESKTOP_NOTIFY_PROP], props[model.PUSH_NOTIFY_PROP])}
+}
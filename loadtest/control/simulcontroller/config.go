@@ -5,6 +5,7 @@ package simulcontroller
 
 import (
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 )
 
 // Config holds information needed to run a SimulController.
@@ -15,6 +16,30 @@ type Config struct {
 	// The average amount of time (in milliseconds) the controlled users
 	// will wait between actions.
 	AvgIdleTimeMs int `default:"20000" validate:"range:($MinIdleTimeMs,]"`
+	// The distribution used to pick the amount of time the controlled users
+	// will wait between actions.
+	// Possible values:
+	//   IdleTimeUniform - Uniformly distributed in [MinIdleTimeMs, 2*AvgIdleTimeMs-MinIdleTimeMs).
+	//   IdleTimeExponential - Exponentially distributed, shifted by MinIdleTimeMs.
+	//   IdleTimeLogNormal - Log-normally distributed, shifted by MinIdleTimeMs.
+	//   IdleTimeFixed - Always equal to AvgIdleTimeMs.
+	IdleTimeDistribution control.IdleTimeDistribution `default:"uniform" validate:"oneof:{uniform,exponential,lognormal,fixed}"`
+	// Actions holds the frequencies of the actions the controlled users will
+	// perform. If empty, the default frequencies are used.
+	Actions []control.ActionDefinition
+	// FileSizeDistribution holds the sizes of the files uploaded by the
+	// controlled users as attachments, along with their frequencies.
+	// If empty, a default distribution skewed towards small files is used.
+	FileSizeDistribution []FileSizeDefinition
+}
+
+// FileSizeDefinition holds the size of the files uploaded as attachments
+// along with the frequency with which it gets picked.
+type FileSizeDefinition struct {
+	// SizeKB is the size of the file in kilobytes.
+	SizeKB int `default:"100" validate:"range:(0,]"`
+	// Frequency is the relative weight with which the size gets picked.
+	Frequency int `default:"1" validate:"range:[0,]"`
 }
 
 // ReadConfig reads the configuration file from the given string. If the string
@@ -12,6 +12,7 @@ import (
 	"github.com/mattermost/mattermost-load-test-ng/defaults"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+	"github.com/mattermost/mattermost-load-test-ng/performance"
 )
 
 // SimulController is a simulative implementation of a UserController.
@@ -20,12 +21,16 @@ type SimulController struct {
 	user           user.User
 	status         chan<- control.UserStatus
 	rate           float64
+	rateMut        sync.RWMutex
 	config         *Config
-	stopChan       chan struct{}   // this channel coordinates the stop sequence of the controller
-	stoppedChan    chan struct{}   // blocks until controller cleans up everything
-	disconnectChan chan struct{}   // notifies disconnection to the ws and periodic goroutines
-	connectedFlag  int32           // indicates that the controller is connected
-	wg             *sync.WaitGroup // to keep the track of every goroutine created by the controller
+	actions        []control.WeightedAction
+	metrics        *performance.ControllerMetrics
+	stopChan       chan struct{}      // this channel coordinates the stop sequence of the controller
+	pauseState     control.PauseState // suspends the execution of actions while paused
+	stoppedChan    chan struct{}      // blocks until controller cleans up everything
+	disconnectChan chan struct{}      // notifies disconnection to the ws and periodic goroutines
+	connectedFlag  int32              // indicates that the controller is connected
+	wg             *sync.WaitGroup    // to keep the track of every goroutine created by the controller
 }
 
 // New creates and initializes a new SimulController with given parameters.
@@ -40,7 +45,7 @@ func New(id int, user user.User, config *Config, status chan<- control.UserStatu
 		return nil, fmt.Errorf("could not validate configuration: %w", err)
 	}
 
-	return &SimulController{
+	controller := &SimulController{
 		id:             id,
 		user:           user,
 		status:         status,
@@ -50,7 +55,15 @@ func New(id int, user user.User, config *Config, status chan<- control.UserStatu
 		stopChan:       make(chan struct{}),
 		stoppedChan:    make(chan struct{}),
 		wg:             &sync.WaitGroup{},
-	}, nil
+	}
+
+	actions, err := createActions(controller, config.Actions)
+	if err != nil {
+		return nil, fmt.Errorf("could not create actions: %w", err)
+	}
+	controller.actions = actions
+
+	return controller, nil
 }
 
 // Run begins performing a set of user actions in a loop.
@@ -74,18 +87,22 @@ func (c *SimulController) Run() {
 		close(c.stoppedChan)
 	}()
 
-	initActions := []userAction{
+	initActions := []control.WeightedAction{
 		{
-			run: control.SignUp,
+			Name: "SignUp",
+			Run:  control.SignUp,
 		},
 		{
-			run: c.login,
+			Name: "Login",
+			Run:  c.login,
 		},
 		{
-			run: c.joinTeam,
+			Name: "JoinTeam",
+			Run:  c.joinTeam,
 		},
 		{
-			run: c.joinChannel,
+			Name: "JoinChannel",
+			Run:  c.joinChannel,
 		},
 	}
 
@@ -93,10 +110,14 @@ func (c *SimulController) Run() {
 		select {
 		case <-c.stopChan:
 			return
-		case <-time.After(pickIdleTimeMs(c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, 1.0)):
+		case <-time.After(control.PickIdleTimeMs(c.config.IdleTimeDistribution, c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, 1.0)):
+		}
+
+		if !c.pauseState.Wait(c.stopChan) {
+			return
 		}
 
-		if resp := initActions[i].run(c.user); resp.Err != nil {
+		if resp := c.runAction(&initActions[i]); resp.Err != nil {
 			c.status <- c.newErrorStatus(resp.Err)
 			i--
 		} else {
@@ -104,72 +125,13 @@ func (c *SimulController) Run() {
 		}
 	}
 
-	actions := []userAction{
-		{
-			run:       switchChannel,
-			frequency: 70,
-		},
-		{
-			run:       c.switchTeam,
-			frequency: 30,
-		},
-		{
-			run:       openDirectOrGroupChannel,
-			frequency: 30,
-		},
-		{
-			run:       unreadCheck,
-			frequency: 25,
-		},
-		{
-			run:       c.createPost,
-			frequency: 25,
-		},
-		{
-			run:       c.createPostReply,
-			frequency: 15,
-		},
-		{
-			run:       c.joinChannel,
-			frequency: 8,
-		},
-		{
-			run:       editPost,
-			frequency: 8,
-		},
-		{
-			run:       searchChannels,
-			frequency: 5,
-		},
-		{
-			run:       c.addReaction,
-			frequency: 5,
-		},
-		{
-			run:       c.fullReload,
-			frequency: 4,
-		},
-		{
-			run:       c.createDirectChannel,
-			frequency: 2,
-		},
-		{
-			run:       c.createGroupChannel,
-			frequency: 1,
-		},
-		{
-			run:       c.logoutLogin,
-			frequency: 1,
-		},
-	}
-
 	for {
-		action, err := pickAction(actions)
+		action, err := control.PickAction(c.actions)
 		if err != nil {
 			panic(fmt.Sprintf("simulcontroller: failed to pick action %s", err.Error()))
 		}
 
-		if resp := action.run(c.user); resp.Err != nil {
+		if resp := c.runAction(action); resp.Err != nil {
 			c.status <- c.newErrorStatus(resp.Err)
 		} else {
 			c.status <- c.newInfoStatus(resp.Info)
@@ -178,21 +140,58 @@ func (c *SimulController) Run() {
 		select {
 		case <-c.stopChan:
 			return
-		case <-time.After(pickIdleTimeMs(c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, c.rate)):
+		case <-time.After(control.PickIdleTimeMs(c.config.IdleTimeDistribution, c.config.MinIdleTimeMs, c.config.AvgIdleTimeMs, c.getRate())):
+		}
+
+		if !c.pauseState.Wait(c.stopChan) {
+			return
 		}
 	}
 
 }
 
+// runAction performs the given action, recording its execution time and
+// outcome.
+func (c *SimulController) runAction(action *control.WeightedAction) control.UserActionResponse {
+	start := time.Now()
+	resp := action.Run(c.user)
+	c.metrics.ObserveAction(action.Name, time.Since(start), resp.Err)
+	return resp
+}
+
+// SetMetrics sets the metrics the controller emits for each user action.
+// It should be called before Run.
+func (c *SimulController) SetMetrics(metrics *performance.ControllerMetrics) {
+	c.metrics = metrics
+}
+
 // SetRate sets the relative speed of execution of actions by the user.
 func (c *SimulController) SetRate(rate float64) error {
 	if rate < 0 {
 		return errors.New("rate should be a positive value")
 	}
+	c.rateMut.Lock()
+	defer c.rateMut.Unlock()
 	c.rate = rate
 	return nil
 }
 
+func (c *SimulController) getRate() float64 {
+	c.rateMut.RLock()
+	defer c.rateMut.RUnlock()
+	return c.rate
+}
+
+// Pause suspends the execution of user actions until Resume is called.
+func (c *SimulController) Pause() {
+	c.pauseState.Pause()
+}
+
+// Resume resumes the execution of user actions.
+func (c *SimulController) Resume() {
+	c.pauseState.Resume()
+}
+
 // Stop stops the controller.
 func (c *SimulController) Stop() {
 	close(c.stopChan)
@@ -4,11 +4,16 @@
 package simulcontroller
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/store/memstore"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/user/userentity"
+	"github.com/mattermost/mattermost-load-test-ng/performance"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,6 +39,102 @@ func TestSetRate(t *testing.T) {
 	require.Equal(t, 1.5, c.rate)
 }
 
+func TestCreateActions(t *testing.T) {
+	config, err := ReadConfig("../../../config/simulcontroller.sample.json")
+	require.NoError(t, err)
+	require.NotNil(t, config)
+
+	t.Run("default frequencies", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = nil
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.NoError(t, err)
+		defaultActions := getActionList(c)
+		require.Len(t, c.actions, len(defaultActions))
+		for i := range defaultActions {
+			require.Equal(t, defaultActions[i].Name, c.actions[i].Name)
+			require.Equal(t, defaultActions[i].Frequency, c.actions[i].Frequency)
+		}
+	})
+
+	t.Run("custom frequencies", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = []control.ActionDefinition{
+			{ActionId: "CreatePost", Frequency: 10},
+			{ActionId: "SwitchChannel", Frequency: 5},
+		}
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.NoError(t, err)
+		require.Len(t, c.actions, len(getActionList(c)))
+		for _, action := range c.actions {
+			switch action.Name {
+			case "CreatePost":
+				require.Equal(t, 10, action.Frequency)
+			case "SwitchChannel":
+				require.Equal(t, 5, action.Frequency)
+			default:
+				require.Zero(t, action.Frequency)
+			}
+		}
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = []control.ActionDefinition{
+			{ActionId: "DoesNotExist", Frequency: 10},
+		}
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.Error(t, err)
+		require.Nil(t, c)
+	})
+
+	t.Run("zero frequencies", func(t *testing.T) {
+		cfg := *config
+		cfg.Actions = []control.ActionDefinition{
+			{ActionId: "CreatePost", Frequency: 0},
+		}
+		c, err := New(1, &userentity.UserEntity{}, &cfg, make(chan control.UserStatus))
+		require.Error(t, err)
+		require.Nil(t, c)
+	})
+}
+
+func TestRunActionMetrics(t *testing.T) {
+	config, err := ReadConfig("../../../config/simulcontroller.sample.json")
+	require.NoError(t, err)
+
+	c, err := New(1, &userentity.UserEntity{}, config, make(chan control.UserStatus))
+	require.NoError(t, err)
+
+	success := &control.WeightedAction{
+		Name: "TestSuccess",
+		Run: func(u user.User) control.UserActionResponse {
+			return control.UserActionResponse{Info: "done"}
+		},
+	}
+	failure := &control.WeightedAction{
+		Name: "TestFailure",
+		Run: func(u user.User) control.UserActionResponse {
+			return control.UserActionResponse{Err: errors.New("failed")}
+		},
+	}
+
+	// Running without metrics should not fail.
+	require.NoError(t, c.runAction(success).Err)
+
+	metrics := performance.NewMetrics().ControllerMetrics()
+	c.SetMetrics(metrics)
+
+	require.NoError(t, c.runAction(success).Err)
+	require.NoError(t, c.runAction(success).Err)
+	require.Error(t, c.runAction(failure).Err)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.ActionSuccess.WithLabelValues("TestSuccess")))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.ActionFailures.WithLabelValues("TestSuccess")))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.ActionFailures.WithLabelValues("TestFailure")))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.ActionSuccess.WithLabelValues("TestFailure")))
+}
+
 func TestRunStop(t *testing.T) {
 	store, err := memstore.New(nil)
 	require.NotNil(t, store)
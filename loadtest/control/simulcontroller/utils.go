@@ -5,31 +5,14 @@ package simulcontroller
 
 import (
 	"errors"
-	"math"
+	"fmt"
 	"math/rand"
 	"strings"
-	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
 )
 
-// pickAction randomly selects an action from a slice of userAction with
-// probability proportional to the action's frequency.
-func pickAction(actions []userAction) (*userAction, error) {
-	weights := make([]int, len(actions))
-	for i := range actions {
-		weights[i] = actions[i].frequency
-	}
-
-	idx, err := control.SelectWeighted(weights)
-	if err != nil {
-		return nil, err
-	}
-
-	return &actions[idx], nil
-}
-
 func genMessage(isReply bool) string {
 	// This is an estimate that comes from stats on community servers.
 	// The average length (in words) for a reply.
@@ -104,14 +87,42 @@ func emulateMention(teamId, channelId, name string, auto func(teamId, channelId,
 	return errors.New("could not match username")
 }
 
-func pickIdleTimeMs(minIdleTimeMs, avgIdleTimeMs int, rate float64) time.Duration {
-	// Randomly selecting a value in the interval
-	// [minIdleTimeMs, avgIdleTimeMs*2 - minIdleTimeMs).
-	// This will give us an expected value equal to avgIdleTimeMs.
-	// TODO: consider if it makes more sense to select this value using
-	// a truncated normal distribution.
-	idleMs := rand.Intn(avgIdleTimeMs*2-minIdleTimeMs*2) + minIdleTimeMs
-	idleTimeMs := time.Duration(math.Round(float64(idleMs) * rate))
+// defaultFileSizeDistribution is used when no file size distribution is
+// configured. Most attachments are small, with a long tail of large ones.
+var defaultFileSizeDistribution = []FileSizeDefinition{
+	{SizeKB: 16, Frequency: 40},
+	{SizeKB: 256, Frequency: 35},
+	{SizeKB: 1024, Frequency: 15},
+	{SizeKB: 5 * 1024, Frequency: 8},
+	{SizeKB: 20 * 1024, Frequency: 2},
+}
+
+// pickFileSizeKB randomly selects a file size from the given distribution
+// with probability proportional to the frequency of each size.
+func pickFileSizeKB(dist []FileSizeDefinition) (int, error) {
+	if len(dist) == 0 {
+		dist = defaultFileSizeDistribution
+	}
+
+	weights := make([]int, len(dist))
+	for i := range dist {
+		weights[i] = dist[i].Frequency
+	}
+
+	idx, err := control.SelectWeighted(weights)
+	if err != nil {
+		return 0, err
+	}
+
+	return dist[idx].SizeKB, nil
+}
 
-	return idleTimeMs * time.Millisecond
+// genFile returns a file of the given size filled with random data, along
+// with a name for it.
+func genFile(sizeKB int) (string, []byte) {
+	extensions := []string{"txt", "pdf", "zip", "log"}
+	name := fmt.Sprintf("attachment_%dkb.%s", sizeKB, extensions[rand.Intn(len(extensions))])
+	data := make([]byte, sizeKB*1024)
+	_, _ = rand.Read(data)
+	return name, data
 }
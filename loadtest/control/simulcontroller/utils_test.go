@@ -19,99 +19,6 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func TestPickAction(t *testing.T) {
-	t.Run("Empty slice", func(t *testing.T) {
-		actions := []userAction{}
-		action, err := pickAction(actions)
-		require.Nil(t, action)
-		require.Error(t, err)
-	})
-
-	t.Run("Zero frequency sum", func(t *testing.T) {
-		actions := []userAction{
-			{
-				frequency: 0,
-			},
-			{
-				frequency: 0,
-			},
-		}
-		action, err := pickAction(actions)
-		require.Nil(t, action)
-		require.Error(t, err)
-	})
-
-	t.Run("Zero frequency action", func(t *testing.T) {
-		actions := []userAction{
-			{
-				frequency: 1,
-			},
-			{
-				frequency: 0,
-			},
-			{
-				frequency: 1,
-			},
-		}
-		action, err := pickAction(actions)
-		require.NotNil(t, action)
-		require.NoError(t, err)
-		require.Condition(t, func() bool {
-			switch action {
-			case &actions[0], &actions[2]:
-				return true
-			default:
-				return false
-			}
-		})
-	})
-
-	t.Run("Different frequencies", func(t *testing.T) {
-		actions := []userAction{
-			{
-				frequency: 1,
-			},
-			{
-				frequency: 100,
-			},
-			{
-				frequency: 0,
-			},
-			{
-				frequency: 10,
-			},
-		}
-
-		res := map[int]int{
-			0: 0,
-			1: 0,
-			2: 0,
-			3: 0,
-		}
-
-		for i := 0; i < 1000; i++ {
-			action, err := pickAction(actions)
-			require.NotNil(t, action)
-			require.NoError(t, err)
-
-			switch action {
-			case &actions[0]:
-				res[0]++
-			case &actions[1]:
-				res[1]++
-			case &actions[2]:
-				res[2]++
-			case &actions[3]:
-				res[3]++
-			}
-		}
-
-		require.Zero(t, res[2])
-		require.Greater(t, res[3], res[0])
-		require.Greater(t, res[1], res[3])
-	})
-}
-
 func TestSplitName(t *testing.T) {
 	testCases := []struct {
 		input, prefix, typed string
@@ -187,3 +94,41 @@ func TestGetCutoff(t *testing.T) {
 		require.Equal(t, tc.cutoff, getCutoff(tc.prefix, tc.typed, newRand))
 	}
 }
+
+func TestPickFileSizeKB(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		sizeKB, err := pickFileSizeKB(nil)
+		require.NoError(t, err)
+		require.Condition(t, func() bool {
+			for _, def := range defaultFileSizeDistribution {
+				if def.SizeKB == sizeKB {
+					return true
+				}
+			}
+			return false
+		})
+	})
+
+	t.Run("Zero frequency size", func(t *testing.T) {
+		dist := []FileSizeDefinition{
+			{SizeKB: 1, Frequency: 0},
+			{SizeKB: 2, Frequency: 1},
+		}
+		for i := 0; i < 100; i++ {
+			sizeKB, err := pickFileSizeKB(dist)
+			require.NoError(t, err)
+			require.Equal(t, 2, sizeKB)
+		}
+	})
+
+	t.Run("Zero frequency sum", func(t *testing.T) {
+		_, err := pickFileSizeKB([]FileSizeDefinition{{SizeKB: 1}})
+		require.Error(t, err)
+	})
+}
+
+func TestGenFile(t *testing.T) {
+	name, data := genFile(16)
+	require.Len(t, data, 16*1024)
+	require.Contains(t, name, "16kb")
+}
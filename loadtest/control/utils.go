@@ -7,6 +7,7 @@ package control
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
 	"regexp"
@@ -145,3 +146,114 @@ func SelectWeighted(weights []int) (int, error) {
 	}
 	return -1, errors.New("should not be able to reach this point")
 }
+
+// WeightedAction is a UserAction along with the relative frequency with
+// which it gets picked.
+type WeightedAction struct {
+	Name      string
+	Run       UserAction
+	Frequency int
+}
+
+// ActionDefinition holds the configured frequency of an action.
+type ActionDefinition struct {
+	// ActionId is the name of the action. It must match one of the actions
+	// implemented by the controller.
+	ActionId string `validate:"notempty"`
+	// Frequency is the relative weight with which the action gets picked.
+	Frequency int `default:"1" validate:"range:[0,]"`
+}
+
+// PickAction randomly selects an action from a slice of WeightedAction with
+// probability proportional to the action's frequency.
+func PickAction(actions []WeightedAction) (*WeightedAction, error) {
+	weights := make([]int, len(actions))
+	for i := range actions {
+		weights[i] = actions[i].Frequency
+	}
+
+	idx, err := SelectWeighted(weights)
+	if err != nil {
+		return nil, err
+	}
+
+	return &actions[idx], nil
+}
+
+// SetActionFrequencies sets the frequencies of the given actions according
+// to the given definitions.
+// If no definitions are given the frequencies are left untouched. Otherwise,
+// actions that are not defined will never run.
+func SetActionFrequencies(actions []WeightedAction, definitions []ActionDefinition) error {
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	actionsByName := make(map[string]*WeightedAction, len(actions))
+	for i := range actions {
+		actions[i].Frequency = 0
+		actionsByName[actions[i].Name] = &actions[i]
+	}
+
+	var sum int
+	for _, def := range definitions {
+		action, ok := actionsByName[def.ActionId]
+		if !ok {
+			return fmt.Errorf("could not find action %q", def.ActionId)
+		}
+		action.Frequency = def.Frequency
+		sum += def.Frequency
+	}
+
+	if sum == 0 {
+		return errors.New("at least one action should have a frequency > 0")
+	}
+
+	return nil
+}
+
+// IdleTimeDistribution describes the probability distribution used to pick
+// the amount of time users wait between actions.
+type IdleTimeDistribution string
+
+// Available idle time distributions.
+const (
+	IdleTimeUniform     IdleTimeDistribution = "uniform"
+	IdleTimeExponential IdleTimeDistribution = "exponential"
+	IdleTimeLogNormal   IdleTimeDistribution = "lognormal"
+	IdleTimeFixed       IdleTimeDistribution = "fixed"
+)
+
+// logNormalSigma is the standard deviation of the natural logarithm of the
+// idle time when using IdleTimeLogNormal.
+const logNormalSigma = 1.0
+
+// PickIdleTimeMs returns the amount of time a user should wait before
+// performing the next action, following the given distribution and scaled
+// by rate. The expected value is equal to avgIdleTimeMs.
+func PickIdleTimeMs(dist IdleTimeDistribution, minIdleTimeMs, avgIdleTimeMs int, rate float64) time.Duration {
+	var idleMs float64
+	switch dist {
+	case IdleTimeExponential:
+		// Exponentially distributed values with mean (avgIdleTimeMs - minIdleTimeMs),
+		// shifted by minIdleTimeMs. This will give us an expected value
+		// equal to avgIdleTimeMs.
+		idleMs = float64(minIdleTimeMs) + rand.ExpFloat64()*float64(avgIdleTimeMs-minIdleTimeMs)
+	case IdleTimeLogNormal:
+		// Log-normally distributed values with mean (avgIdleTimeMs - minIdleTimeMs),
+		// shifted by minIdleTimeMs. The location parameter is derived from
+		// the expected value exp(mu + sigma^2/2).
+		mu := math.Log(float64(avgIdleTimeMs-minIdleTimeMs)) - logNormalSigma*logNormalSigma/2
+		idleMs = float64(minIdleTimeMs) + math.Exp(mu+logNormalSigma*rand.NormFloat64())
+	case IdleTimeFixed:
+		idleMs = float64(avgIdleTimeMs)
+	default:
+		// Randomly selecting a value in the interval
+		// [minIdleTimeMs, avgIdleTimeMs*2 - minIdleTimeMs).
+		// This will give us an expected value equal to avgIdleTimeMs.
+		idleMs = float64(rand.Intn(avgIdleTimeMs*2-minIdleTimeMs*2) + minIdleTimeMs)
+	}
+	idleTimeMs := time.Duration(math.Round(idleMs * rate))
+
+	return idleTimeMs * time.Millisecond
+}
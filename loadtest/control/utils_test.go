@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/store/memstore"
 	"github.com/stretchr/testify/assert"
@@ -124,3 +125,122 @@ func TestSelectWeighted(t *testing.T) {
 		require.Greater(t, distribution[1], distribution[2])
 	})
 }
+
+func TestPickAction(t *testing.T) {
+	t.Run("Empty slice", func(t *testing.T) {
+		actions := []WeightedAction{}
+		action, err := PickAction(actions)
+		require.Nil(t, action)
+		require.Error(t, err)
+	})
+
+	t.Run("Zero frequency sum", func(t *testing.T) {
+		actions := []WeightedAction{
+			{
+				Frequency: 0,
+			},
+			{
+				Frequency: 0,
+			},
+		}
+		action, err := PickAction(actions)
+		require.Nil(t, action)
+		require.Error(t, err)
+	})
+
+	t.Run("Zero frequency action", func(t *testing.T) {
+		actions := []WeightedAction{
+			{
+				Frequency: 1,
+			},
+			{
+				Frequency: 0,
+			},
+			{
+				Frequency: 1,
+			},
+		}
+		action, err := PickAction(actions)
+		require.NotNil(t, action)
+		require.NoError(t, err)
+		require.Condition(t, func() bool {
+			switch action {
+			case &actions[0], &actions[2]:
+				return true
+			default:
+				return false
+			}
+		})
+	})
+
+	t.Run("Different frequencies", func(t *testing.T) {
+		actions := []WeightedAction{
+			{
+				Frequency: 1,
+			},
+			{
+				Frequency: 100,
+			},
+			{
+				Frequency: 0,
+			},
+			{
+				Frequency: 10,
+			},
+		}
+
+		res := map[int]int{
+			0: 0,
+			1: 0,
+			2: 0,
+			3: 0,
+		}
+
+		for i := 0; i < 1000; i++ {
+			action, err := PickAction(actions)
+			require.NotNil(t, action)
+			require.NoError(t, err)
+
+			switch action {
+			case &actions[0]:
+				res[0]++
+			case &actions[1]:
+				res[1]++
+			case &actions[2]:
+				res[2]++
+			case &actions[3]:
+				res[3]++
+			}
+		}
+
+		require.Zero(t, res[2])
+		require.Greater(t, res[3], res[0])
+		require.Greater(t, res[1], res[3])
+	})
+}
+
+func TestPickIdleTimeMs(t *testing.T) {
+	minIdleTimeMs := 1000
+	avgIdleTimeMs := 5000
+
+	t.Run("Fixed", func(t *testing.T) {
+		idleTime := PickIdleTimeMs(IdleTimeFixed, minIdleTimeMs, avgIdleTimeMs, 1.0)
+		require.Equal(t, 5*time.Second, idleTime)
+		idleTime = PickIdleTimeMs(IdleTimeFixed, minIdleTimeMs, avgIdleTimeMs, 2.0)
+		require.Equal(t, 10*time.Second, idleTime)
+	})
+
+	for _, dist := range []IdleTimeDistribution{IdleTimeUniform, IdleTimeExponential, IdleTimeLogNormal} {
+		t.Run(string(dist), func(t *testing.T) {
+			n := 10000
+			var sum time.Duration
+			for i := 0; i < n; i++ {
+				idleTime := PickIdleTimeMs(dist, minIdleTimeMs, avgIdleTimeMs, 1.0)
+				require.GreaterOrEqual(t, int64(idleTime), int64(time.Duration(minIdleTimeMs)*time.Millisecond))
+				sum += idleTime
+			}
+			avg := sum / time.Duration(n)
+			require.InDelta(t, float64(avgIdleTimeMs), float64(avg.Milliseconds()), float64(avgIdleTimeMs)*0.1)
+		})
+	}
+}
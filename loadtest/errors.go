@@ -10,7 +10,10 @@ import (
 var (
 	ErrNotRunning      = errors.New("LoadTester is not running")
 	ErrNotStopped      = errors.New("LoadTester has not stopped")
+	ErrNotPaused       = errors.New("LoadTester is not paused")
 	ErrNoUsersLeft     = errors.New("no active users left")
 	ErrMaxUsersReached = errors.New("max active users limit reached")
 	ErrInvalidNumUsers = errors.New("numUsers should be > 0")
+
+	ErrInvalidRemovalStrategy = errors.New("invalid removal strategy")
 )
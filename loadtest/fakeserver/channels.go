@@ -0,0 +1,461 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// createChannel stores a new channel.
+// It must be called with s.mut held.
+func (s *Server) createChannel(channel *model.Channel) *model.Channel {
+	channel.Id = ""
+	channel.PreSave()
+	s.channels[channel.Id] = channel
+	s.channelMembers[channel.Id] = map[string]*model.ChannelMember{}
+	return channel
+}
+
+// channelByName returns the channel with the given name in the team, if any.
+// Direct and group channels have an empty teamId.
+// It must be called with s.mut held.
+func (s *Server) channelByName(teamId, name string) *model.Channel {
+	for _, channel := range s.channels {
+		if channel.TeamId == teamId && channel.Name == name {
+			return channel
+		}
+	}
+	return nil
+}
+
+// addChannelMember adds the given user to the channel.
+// It must be called with s.mut held.
+func (s *Server) addChannelMember(channelId, userId string) *model.ChannelMember {
+	if cm, ok := s.channelMembers[channelId][userId]; ok {
+		return cm
+	}
+
+	cm := &model.ChannelMember{
+		ChannelId:   channelId,
+		UserId:      userId,
+		Roles:       "channel_user",
+		NotifyProps: model.GetDefaultChannelNotifyProps(),
+	}
+	s.channelMembers[channelId][userId] = cm
+	return cm
+}
+
+// isChannelMember reports whether the given user is a member of the channel.
+// It must be called with s.mut held.
+func (s *Server) isChannelMember(channelId, userId string) bool {
+	_, ok := s.channelMembers[channelId][userId]
+	return ok
+}
+
+// channelMemberIds returns the ids of the members of the given channel.
+// It must be called with s.mut held.
+func (s *Server) channelMemberIds(channelId string) []string {
+	ids := make([]string, 0, len(s.channelMembers[channelId]))
+	for id := range s.channelMembers[channelId] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// sortedChannels returns the channels matching filter, sorted by name.
+// It must be called with s.mut held.
+func (s *Server) sortedChannels(filter func(c *model.Channel) bool) []*model.Channel {
+	channels := []*model.Channel{}
+	for _, c := range s.channels {
+		if filter == nil || filter(c) {
+			channels = append(channels, c)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].Name < channels[j].Name
+	})
+	return channels
+}
+
+// createGroupOrDirectChannel returns the channel of the given type between
+// the given users, creating it if it doesn't exist yet.
+// It must be called with s.mut held.
+func (s *Server) createGroupOrDirectChannel(channelType, name string, userIds []string) *model.Channel {
+	if channel := s.channelByName("", name); channel != nil {
+		return channel
+	}
+
+	channel := s.createChannel(&model.Channel{
+		Name: name,
+		Type: channelType,
+	})
+	for _, id := range userIds {
+		s.addChannelMember(channel.Id, id)
+	}
+	return channel
+}
+
+func (s *Server) createChannelHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channel := model.ChannelFromJson(r.Body)
+	if channel == nil || channel.Name == "" || (channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE) {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing channel in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.teamMembers[channel.TeamId][userId]; !ok {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	if s.channelByName(channel.TeamId, channel.Name) != nil {
+		writeError(w, http.StatusBadRequest, "store.sql_channel.save_channel.exists.app_error", "A channel with that name already exists on the same team.")
+		return
+	}
+
+	channel.CreatorId = userId
+	s.createChannel(channel)
+	s.addChannelMember(channel.Id, userId)
+
+	writeJSON(w, http.StatusCreated, channel)
+}
+
+func (s *Server) createDirectChannelHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	userIds := model.ArrayFromJson(r.Body)
+	if len(userIds) != 2 {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing user_ids in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, id := range userIds {
+		if _, ok := s.users[id]; !ok {
+			writeError(w, http.StatusBadRequest, "api.channel.create_direct_channel.invalid_user.app_error", "Invalid user ID for direct channel creation.")
+			return
+		}
+	}
+
+	name := model.GetDMNameFromIds(userIds[0], userIds[1])
+	writeJSON(w, http.StatusCreated, s.createGroupOrDirectChannel(model.CHANNEL_DIRECT, name, userIds))
+}
+
+func (s *Server) createGroupChannelHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	ids := map[string]bool{userId: true}
+	for _, id := range model.ArrayFromJson(r.Body) {
+		ids[id] = true
+	}
+	userIds := make([]string, 0, len(ids))
+	for id := range ids {
+		userIds = append(userIds, id)
+	}
+	if len(userIds) < model.CHANNEL_GROUP_MIN_USERS || len(userIds) > model.CHANNEL_GROUP_MAX_USERS {
+		writeError(w, http.StatusBadRequest, "api.channel.create_group.bad_size.app_error", "Group message channels must contain at least 3 and no more than 8 users.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, id := range userIds {
+		if _, ok := s.users[id]; !ok {
+			writeError(w, http.StatusBadRequest, "api.channel.create_group.bad_user.app_error", "One of the provided users does not exist.")
+			return
+		}
+	}
+
+	name := model.GetGroupNameFromUserIds(userIds)
+	writeJSON(w, http.StatusCreated, s.createGroupOrDirectChannel(model.CHANNEL_GROUP, name, userIds))
+}
+
+func (s *Server) getChannelHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	channel, ok := s.channels[mux.Vars(r)["channel_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get.existing.app_error", "Unable to find the existing channel.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, channel)
+}
+
+func (s *Server) getChannelStatsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	if _, ok := s.channels[channelId]; !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get.existing.app_error", "Unable to find the existing channel.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &model.ChannelStats{
+		ChannelId:   channelId,
+		MemberCount: int64(len(s.channelMembers[channelId])),
+	})
+}
+
+func (s *Server) addChannelMemberHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+	memberId := model.MapFromJson(r.Body)["user_id"]
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	channel, ok := s.channels[channelId]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get.existing.app_error", "Unable to find the existing channel.")
+		return
+	}
+	if channel.IsGroupOrDirect() {
+		writeError(w, http.StatusBadRequest, "api.channel.add_user_to_channel.type.app_error", "Can not add user to this channel type.")
+		return
+	}
+	if _, ok := s.teamMembers[channel.TeamId][memberId]; !ok {
+		writeError(w, http.StatusForbidden, "api.channel.add_user.to.channel.failed.deleted.app_error", "Failed to add user to channel because they have been removed from the team.")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.addChannelMember(channelId, memberId))
+}
+
+func (s *Server) getChannelMembersHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+	page, perPage := pageParams(r)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	members := make(model.ChannelMembers, 0, len(s.channelMembers[channelId]))
+	for _, cm := range s.channelMembers[channelId] {
+		members = append(members, *cm)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].UserId < members[j].UserId
+	})
+	start, end := paginate(len(members), page, perPage)
+
+	writeJSON(w, http.StatusOK, members[start:end])
+}
+
+func (s *Server) getChannelMemberHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	cm, ok := s.channelMembers[mux.Vars(r)["channel_id"]][mux.Vars(r)["user_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get_member.missing.app_error", "No channel member found for that user ID and channel ID.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cm)
+}
+
+// updateChannelNotifyPropsHandler merges the given notification preferences
+// into the ones of the channel member, notifying the user.
+func (s *Server) updateChannelNotifyPropsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	if mux.Vars(r)["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	props := model.MapFromJson(r.Body)
+	if len(props) == 0 {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing notify_props in request body.")
+		return
+	}
+
+	s.mut.Lock()
+
+	cm, ok := s.channelMembers[mux.Vars(r)["channel_id"]][userId]
+	if !ok {
+		s.mut.Unlock()
+		writeError(w, http.StatusNotFound, "app.channel.get_member.missing.app_error", "No channel member found for that user ID and channel ID.")
+		return
+	}
+
+	// Members are shallow copied when sent to clients, so the props are
+	// replaced rather than modified.
+	notifyProps := model.StringMap{}
+	for k, v := range cm.NotifyProps {
+		notifyProps[k] = v
+	}
+	for k, v := range props {
+		notifyProps[k] = v
+	}
+	cm.NotifyProps = notifyProps
+	cm.LastUpdateAt = model.GetMillis()
+
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_MEMBER_UPDATED, "", "", userId, nil)
+	ev.Add("channelMember", cm.ToJson())
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, []string{userId})
+	writeStatusOK(w)
+}
+
+func (s *Server) removeChannelMemberHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	channel, ok := s.channels[channelId]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get.existing.app_error", "Unable to find the existing channel.")
+		return
+	}
+	if channel.IsGroupOrDirect() || channel.Name == model.DEFAULT_CHANNEL {
+		writeError(w, http.StatusBadRequest, "api.channel.remove.default.app_error", "Unable to remove user from this channel.")
+		return
+	}
+
+	delete(s.channelMembers[channelId], memberId)
+
+	writeStatusOK(w)
+}
+
+func (s *Server) viewChannelHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	view := model.ChannelViewFromJson(r.Body)
+	if view == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing channel view in request body.")
+		return
+	}
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	now := model.GetMillis()
+	times := map[string]int64{}
+	for _, channelId := range []string{view.ChannelId, view.PrevChannelId} {
+		cm, ok := s.channelMembers[channelId][memberId]
+		if !ok {
+			continue
+		}
+		cm.LastViewedAt = now
+		cm.MsgCount = s.channels[channelId].TotalMsgCount
+		cm.MentionCount = 0
+		times[channelId] = now
+	}
+
+	writeJSON(w, http.StatusOK, &model.ChannelViewResponse{
+		Status:            model.STATUS_OK,
+		LastViewedAtTimes: times,
+	})
+}
+
+func (s *Server) getChannelUnreadHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	cm, ok := s.channelMembers[channelId][mux.Vars(r)["user_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get_member.missing.app_error", "No channel member found for that user ID and channel ID.")
+		return
+	}
+	channel := s.channels[channelId]
+
+	writeJSON(w, http.StatusOK, &model.ChannelUnread{
+		TeamId:       channel.TeamId,
+		ChannelId:    channelId,
+		MsgCount:     channel.TotalMsgCount - cm.MsgCount,
+		MentionCount: cm.MentionCount,
+		NotifyProps:  cm.NotifyProps,
+	})
+}
+
+func (s *Server) getChannelsForTeamForUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	writeJSON(w, http.StatusOK, s.sortedChannels(func(c *model.Channel) bool {
+		return (c.TeamId == teamId || c.IsGroupOrDirect()) && s.isChannelMember(c.Id, memberId)
+	}))
+}
+
+func (s *Server) getChannelMembersForUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	members := model.ChannelMembers{}
+	for _, c := range s.sortedChannels(func(c *model.Channel) bool {
+		return c.TeamId == teamId || c.IsGroupOrDirect()
+	}) {
+		if cm, ok := s.channelMembers[c.Id][memberId]; ok {
+			members = append(members, *cm)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (s *Server) getPublicChannelsForTeamHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+	page, perPage := pageParams(r)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	channels := s.sortedChannels(func(c *model.Channel) bool {
+		return c.TeamId == teamId && c.Type == model.CHANNEL_OPEN
+	})
+	start, end := paginate(len(channels), page, perPage)
+
+	writeJSON(w, http.StatusOK, channels[start:end])
+}
+
+func (s *Server) searchChannelsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	search := model.ChannelSearchFromJson(r.Body)
+	if search == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing channel search in request body.")
+		return
+	}
+	teamId := mux.Vars(r)["team_id"]
+	term := strings.ToLower(search.Term)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	writeJSON(w, http.StatusOK, s.sortedChannels(func(c *model.Channel) bool {
+		return c.TeamId == teamId && c.Type == model.CHANNEL_OPEN &&
+			(strings.Contains(c.Name, term) || strings.Contains(strings.ToLower(c.DisplayName), term))
+	}))
+}
+
+func (s *Server) autocompleteChannelsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+	name := strings.ToLower(r.URL.Query().Get("name"))
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	channels := s.sortedChannels(func(c *model.Channel) bool {
+		return c.TeamId == teamId && c.Type == model.CHANNEL_OPEN && strings.HasPrefix(c.Name, name)
+	})
+	if len(channels) > 50 {
+		channels = channels[:50]
+	}
+
+	writeJSON(w, http.StatusOK, channels)
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+const maxUploadSize = 50 * 1024 * 1024
+
+func (s *Server) uploadFileHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		writeError(w, http.StatusBadRequest, "api.file.upload_file.read_request.app_error", "Unable to read the upload request.")
+		return
+	}
+	channelId := r.FormValue("channel_id")
+
+	var infos []*model.FileInfo
+	for _, header := range r.MultipartForm.File["files"] {
+		file, err := header.Open()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "api.file.upload_file.read_request.app_error", "Unable to read the upload request.")
+			return
+		}
+		data, err := ioutil.ReadAll(file)
+		file.Close()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "api.file.upload_file.read_request.app_error", "Unable to read the upload request.")
+			return
+		}
+
+		info := &model.FileInfo{
+			CreatorId: userId,
+			Name:      header.Filename,
+			Extension: strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), ".")),
+			Size:      int64(len(data)),
+			MimeType:  http.DetectContentType(data),
+		}
+		info.PreSave()
+		infos = append(infos, info)
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if !s.isChannelMember(channelId, userId) {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	for _, info := range infos {
+		s.files[info.Id] = info
+	}
+
+	writeJSON(w, http.StatusCreated, &model.FileUploadResponse{
+		FileInfos: infos,
+		ClientIds: []string{},
+	})
+}
+
+func (s *Server) getFileInfosForPostHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	post, ok := s.posts[mux.Vars(r)["post_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.post.get.app_error", "Unable to get post.")
+		return
+	}
+
+	infos := []*model.FileInfo{}
+	for _, id := range post.FileIds {
+		if info, ok := s.files[id]; ok {
+			infos = append(infos, info)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// getFileHandler serves both thumbnails and previews. The file contents
+// are not kept, so a placeholder image is returned instead.
+func (s *Server) getFileHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	_, ok := s.files[mux.Vars(r)["file_id"]]
+	s.mut.RUnlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.file_info.get.app_error", "Unable to get the file info.")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(defaultProfileImage)
+}
+
+// canAccessFile returns whether the given user can access the file, either
+// because it was attached to a post in one of their channels or because they
+// uploaded it.
+// It must be called with s.mut held.
+func (s *Server) canAccessFile(info *model.FileInfo, userId string) bool {
+	if post, ok := s.posts[info.PostId]; ok {
+		return s.isChannelMember(post.ChannelId, userId)
+	}
+	return info.CreatorId == userId
+}
+
+func (s *Server) getFileInfoHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	info, ok := s.files[mux.Vars(r)["file_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.file_info.get.app_error", "Unable to get the file info.")
+		return
+	}
+	if !s.canAccessFile(info, userId) {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// downloadFileHandler serves the contents of a file. The file contents are
+// not kept, so a zero-filled body of the original size is returned instead.
+func (s *Server) downloadFileHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	info, ok := s.files[mux.Vars(r)["file_id"]]
+	canAccess := ok && s.canAccessFile(info, userId)
+	s.mut.RUnlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.file_info.get.app_error", "Unable to get the file info.")
+		return
+	}
+	if !canAccess {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	w.Header().Set("Content-Type", info.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	if r.URL.Query().Get("download") == "true" {
+		w.Header().Set("Content-Disposition", "attachment;filename=\""+info.Name+"\"")
+	}
+	_, _ = w.Write(bytes.Repeat([]byte{0}, int(info.Size)))
+}
+
+// searchFilesHandler matches the terms against the names of the files
+// attached to posts. The in: modifier restricts the search to a channel.
+func (s *Server) searchFilesHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	params := model.SearchParameterFromJson(r.Body)
+	if params == nil || params.Terms == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing search parameters in request body.")
+		return
+	}
+	teamId := mux.Vars(r)["team_id"]
+	isOrSearch := params.IsOrSearch != nil && *params.IsOrSearch
+
+	var inChannel string
+	var terms []string
+	for _, term := range strings.Fields(strings.ToLower(*params.Terms)) {
+		if strings.HasPrefix(term, "in:") {
+			inChannel = strings.TrimPrefix(term, "in:")
+			continue
+		}
+		terms = append(terms, term)
+	}
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	var matches []*model.FileInfo
+	for _, info := range s.files {
+		post, ok := s.posts[info.PostId]
+		if !ok || info.DeleteAt > 0 {
+			continue
+		}
+		channel := s.channels[post.ChannelId]
+		if (channel.TeamId != teamId && !channel.IsGroupOrDirect()) || !s.isChannelMember(channel.Id, userId) {
+			continue
+		}
+		if inChannel != "" && channel.Name != inChannel {
+			continue
+		}
+		name := strings.ToLower(info.Name)
+		found := !isOrSearch || len(terms) == 0
+		for _, term := range terms {
+			if isOrSearch {
+				found = found || strings.Contains(name, term)
+			} else {
+				found = found && strings.Contains(name, term)
+			}
+		}
+		if found && (len(terms) > 0 || inChannel != "") {
+			matches = append(matches, info)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].CreateAt > matches[j].CreateAt
+	})
+	if len(matches) > 100 {
+		matches = matches[:100]
+	}
+
+	res := struct {
+		Order     []string                   `json:"order"`
+		FileInfos map[string]*model.FileInfo `json:"file_infos"`
+	}{
+		Order:     []string{},
+		FileInfos: map[string]*model.FileInfo{},
+	}
+	for _, info := range matches {
+		res.Order = append(res.Order, info.Id)
+		res.FileInfos[info.Id] = info
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
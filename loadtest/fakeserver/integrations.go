@@ -0,0 +1,340 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// integrationClient is used to send requests to the services behind custom
+// slash commands and outgoing webhooks.
+var integrationClient = &http.Client{Timeout: 5 * time.Second}
+
+// integrationPost returns a post in the given channel as created by an
+// integration, optionally overriding the displayed username.
+func integrationPost(channelId, message, username string) *model.Post {
+	post := &model.Post{ChannelId: channelId, Message: message}
+	post.AddProp("from_webhook", "true")
+	if username != "" {
+		post.AddProp("override_username", username)
+	}
+	return post
+}
+
+func (s *Server) createBotHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	bot := model.BotFromJson(r.Body)
+	if bot == nil || bot.Username == "" {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing bot in request body.")
+		return
+	}
+	bot.Username = strings.ToLower(bot.Username)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == bot.Username {
+			writeError(w, http.StatusBadRequest, "app.user.save.username_exists.app_error", "An account with that username already exists.")
+			return
+		}
+	}
+
+	bot.UserId = model.NewId()
+	bot.OwnerId = userId
+	bot.PreSave()
+	s.bots[bot.UserId] = bot
+	s.users[bot.UserId] = &model.User{
+		Id:       bot.UserId,
+		Username: bot.Username,
+		Nickname: bot.DisplayName,
+		Roles:    model.SYSTEM_USER_ROLE_ID,
+		CreateAt: bot.CreateAt,
+		UpdateAt: bot.UpdateAt,
+		IsBot:    true,
+	}
+
+	writeJSON(w, http.StatusCreated, bot)
+}
+
+func (s *Server) createCommandHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	cmd := model.CommandFromJson(r.Body)
+	if cmd == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing command in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.teamMembers[cmd.TeamId][userId]; !ok {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	for _, c := range s.commands {
+		if c.TeamId == cmd.TeamId && c.Trigger == cmd.Trigger {
+			writeError(w, http.StatusBadRequest, "api.command.duplicate_trigger.app_error", "This trigger word is already in use. Please choose another word.")
+			return
+		}
+	}
+
+	cmd.Id = ""
+	cmd.Token = ""
+	cmd.CreatorId = userId
+	cmd.PreSave()
+	if appErr := cmd.IsValid(); appErr != nil {
+		writeError(w, appErr.StatusCode, appErr.Id, appErr.Error())
+		return
+	}
+	s.commands[cmd.Id] = cmd
+
+	writeJSON(w, http.StatusCreated, cmd)
+}
+
+func (s *Server) createIncomingHookHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	hook := model.IncomingWebhookFromJson(r.Body)
+	if hook == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing webhook in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	channel, ok := s.channels[hook.ChannelId]
+	if !ok || !s.isChannelMember(channel.Id, userId) {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	hook.Id = ""
+	hook.UserId = userId
+	hook.TeamId = channel.TeamId
+	hook.PreSave()
+	s.incomingHooks[hook.Id] = hook
+
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func (s *Server) createOutgoingHookHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	hook := model.OutgoingWebhookFromJson(r.Body)
+	if hook == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing webhook in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.teamMembers[hook.TeamId][userId]; !ok {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	if channel, ok := s.channels[hook.ChannelId]; hook.ChannelId != "" && (!ok || channel.TeamId != hook.TeamId) {
+		writeError(w, http.StatusBadRequest, "api.webhook.create_outgoing.not_open.app_error", "Outgoing webhooks can only be created to public channels.")
+		return
+	}
+
+	hook.Id = ""
+	hook.Token = ""
+	hook.CreatorId = userId
+	hook.PreSave()
+	s.outgoingHooks[hook.Id] = hook
+
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func (s *Server) executeCommandHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	args := model.CommandArgsFromJson(r.Body)
+	if args == nil || !strings.HasPrefix(args.Command, "/") {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing command in request body.")
+		return
+	}
+	parts := strings.SplitN(strings.TrimPrefix(args.Command, "/"), " ", 2)
+	trigger := parts[0]
+	var text string
+	if len(parts) > 1 {
+		text = parts[1]
+	}
+
+	s.mut.RLock()
+	channel, ok := s.channels[args.ChannelId]
+	if !ok || !s.isChannelMember(channel.Id, userId) {
+		s.mut.RUnlock()
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	var cmd *model.Command
+	for _, c := range s.commands {
+		if c.TeamId == channel.TeamId && c.Trigger == trigger {
+			cmd = c
+			break
+		}
+	}
+	s.mut.RUnlock()
+
+	var resp *model.CommandResponse
+	switch {
+	case cmd != nil:
+		var err error
+		resp, err = callCommand(cmd, channel, userId, text)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "api.command.execute_command.failed.app_error", fmt.Sprintf("Command with a trigger of '%s' failed: %s", trigger, err))
+			return
+		}
+	case trigger == "shrug":
+		resp = &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_IN_CHANNEL, Text: text + ` ¯\\\_(ツ)\_/¯`}
+	case trigger == "me":
+		resp = &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_IN_CHANNEL, Text: "*" + text + "*"}
+	default:
+		writeError(w, http.StatusNotFound, "api.command.execute_command.not_found.app_error", fmt.Sprintf("Command with a trigger of '%s' not found.", trigger))
+		return
+	}
+
+	if resp.ResponseType == model.COMMAND_RESPONSE_TYPE_IN_CHANNEL && resp.Text != "" {
+		post := &model.Post{ChannelId: channel.Id, Message: resp.Text}
+		if cmd != nil {
+			post = integrationPost(channel.Id, resp.Text, cmd.Username)
+		}
+		s.savePost(post, userId)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// callCommand sends the request for the given custom slash command to the
+// service behind it and returns its response.
+func callCommand(cmd *model.Command, channel *model.Channel, userId, text string) (*model.CommandResponse, error) {
+	values := url.Values{
+		"token":      {cmd.Token},
+		"team_id":    {cmd.TeamId},
+		"channel_id": {channel.Id},
+		"user_id":    {userId},
+		"command":    {"/" + cmd.Trigger},
+		"text":       {text},
+	}
+	r, err := integrationClient.PostForm(cmd.URL, values)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		_ = r.Body.Close()
+	}()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", r.StatusCode)
+	}
+
+	return model.CommandResponseFromHTTPBody(r.Header.Get("Content-Type"), r.Body)
+}
+
+// incomingHookHandler posts the message sent through an incoming webhook.
+// Like in the real server, it doesn't require a session.
+func (s *Server) incomingHookHandler(w http.ResponseWriter, r *http.Request) {
+	req, appErr := model.IncomingWebhookRequestFromJson(r.Body)
+	if appErr != nil || req == nil || req.Text == "" {
+		writeError(w, http.StatusBadRequest, "web.incoming_webhook.parse.app_error", "Unable to parse incoming data.")
+		return
+	}
+
+	s.mut.RLock()
+	hook, ok := s.incomingHooks[mux.Vars(r)["hook_id"]]
+	s.mut.RUnlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, "web.incoming_webhook.invalid.app_error", "Invalid webhook.")
+		return
+	}
+
+	username := req.Username
+	if username == "" {
+		username = hook.Username
+	}
+	s.savePost(integrationPost(hook.ChannelId, req.Text, username), hook.UserId)
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// triggerOutgoingHooks sends the given post to the outgoing webhooks triggered
+// by its first word and posts their responses. Unlike the real server, which
+// does it asynchronously, the hooks are called before returning.
+func (s *Server) triggerOutgoingHooks(post *model.Post) {
+	fields := strings.Fields(post.Message)
+	if len(fields) == 0 {
+		return
+	}
+
+	s.mut.RLock()
+	channel := s.channels[post.ChannelId]
+	var hooks []*model.OutgoingWebhook
+	for _, hook := range s.outgoingHooks {
+		if hook.TeamId == channel.TeamId && (hook.ChannelId == "" || hook.ChannelId == channel.Id) && hook.TriggerWordExactMatch(fields[0]) {
+			hooks = append(hooks, hook)
+		}
+	}
+	username := s.users[post.UserId].Username
+	s.mut.RUnlock()
+
+	for _, hook := range hooks {
+		payload := &model.OutgoingWebhookPayload{
+			Token:       hook.Token,
+			TeamId:      hook.TeamId,
+			ChannelId:   channel.Id,
+			ChannelName: channel.Name,
+			Timestamp:   post.CreateAt,
+			UserId:      post.UserId,
+			UserName:    username,
+			PostId:      post.Id,
+			Text:        post.Message,
+			TriggerWord: fields[0],
+		}
+		for _, callbackURL := range hook.CallbackURLs {
+			resp, err := callOutgoingHook(callbackURL, payload)
+			if err != nil || resp.Text == nil || *resp.Text == "" {
+				continue
+			}
+			reply := integrationPost(channel.Id, *resp.Text, hook.Username)
+			if resp.ResponseType == model.OUTGOING_HOOK_RESPONSE_TYPE_COMMENT {
+				reply.RootId = post.Id
+				if post.RootId != "" {
+					reply.RootId = post.RootId
+				}
+			}
+			s.savePost(reply, hook.CreatorId)
+		}
+	}
+}
+
+// callOutgoingHook sends the given payload to the service behind an outgoing
+// webhook and returns its response.
+func callOutgoingHook(callbackURL string, payload *model.OutgoingWebhookPayload) (*model.OutgoingWebhookResponse, error) {
+	r, err := integrationClient.Post(callbackURL, "application/json", bytes.NewReader([]byte(payload.ToJSON())))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		_ = r.Body.Close()
+	}()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", r.StatusCode)
+	}
+
+	var resp model.OutgoingWebhookResponse
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &resp, nil
+}
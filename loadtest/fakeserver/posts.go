@@ -0,0 +1,412 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// preparePost returns a copy of the given post with its metadata populated.
+// It must be called with s.mut held.
+func (s *Server) preparePost(post *model.Post) *model.Post {
+	p := post.Clone()
+	p.Metadata = &model.PostMetadata{}
+	for _, id := range p.FileIds {
+		if info, ok := s.files[id]; ok {
+			p.Metadata.Files = append(p.Metadata.Files, info)
+		}
+	}
+	p.Metadata.Reactions = append(p.Metadata.Reactions, s.reactions[p.Id]...)
+	return p
+}
+
+// postList builds a PostList out of the given post ids, which are expected
+// to be sorted from the newest to the oldest.
+// It must be called with s.mut held.
+func (s *Server) postList(ids []string) *model.PostList {
+	list := model.NewPostList()
+	for _, id := range ids {
+		list.AddPost(s.preparePost(s.posts[id]))
+		list.AddOrder(id)
+	}
+	return list
+}
+
+// reversed returns a copy of ids in reverse order.
+func reversed(ids []string) []string {
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[len(ids)-1-i] = id
+	}
+	return res
+}
+
+// postIndex returns the position of postId in the given channel, or -1.
+// It must be called with s.mut held.
+func (s *Server) postIndex(channelId, postId string) int {
+	for i, id := range s.channelPosts[channelId] {
+		if id == postId {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) createPostHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	post := model.PostFromJson(r.Body)
+	if post == nil || post.ChannelId == "" {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing post in request body.")
+		return
+	}
+
+	s.mut.RLock()
+
+	channel, ok := s.channels[post.ChannelId]
+	if !ok || !s.isChannelMember(channel.Id, userId) {
+		s.mut.RUnlock()
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	if root, ok := s.posts[post.RootId]; post.RootId != "" && (!ok || root.ChannelId != post.ChannelId) {
+		s.mut.RUnlock()
+		writeError(w, http.StatusBadRequest, "api.post.create_post.root_id.app_error", "Invalid RootId parameter.")
+		return
+	}
+
+	s.mut.RUnlock()
+
+	rpost := s.savePost(post, userId)
+	s.triggerOutgoingHooks(rpost)
+	writeJSON(w, http.StatusCreated, rpost)
+}
+
+// savePost stores the given post as created by userId and notifies the
+// members of its channel. Permissions must have been checked by the caller.
+func (s *Server) savePost(post *model.Post, userId string) *model.Post {
+	s.mut.Lock()
+
+	channel := s.channels[post.ChannelId]
+	post.Id = ""
+	post.UserId = userId
+	post.CreateAt = 0
+	post.PreSave()
+	for _, id := range post.FileIds {
+		if info, ok := s.files[id]; ok && info.CreatorId == userId {
+			info.PostId = post.Id
+		}
+	}
+	s.posts[post.Id] = post
+	s.channelPosts[channel.Id] = append(s.channelPosts[channel.Id], post.Id)
+
+	channel.TotalMsgCount++
+	channel.LastPostAt = post.CreateAt
+	if cm, ok := s.channelMembers[channel.Id][userId]; ok {
+		cm.MsgCount = channel.TotalMsgCount
+		cm.LastViewedAt = post.CreateAt
+	}
+
+	rpost := s.preparePost(post)
+	recipients := s.channelMemberIds(channel.Id)
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", channel.Id, "", nil)
+	ev.Add("post", rpost.ToJson())
+	ev.Add("channel_type", channel.Type)
+	ev.Add("channel_display_name", channel.DisplayName)
+	ev.Add("channel_name", channel.Name)
+	ev.Add("sender_name", s.users[userId].Username)
+	ev.Add("team_id", channel.TeamId)
+
+	var threadEvents []*model.WebSocketEvent
+	if post.RootId != "" {
+		for _, id := range s.addReply(post) {
+			threadEvents = append(threadEvents, s.threadUpdatedEvent(post.RootId, id))
+		}
+	}
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, recipients)
+	for _, tev := range threadEvents {
+		s.hub.broadcast(tev, []string{tev.GetBroadcast().UserId})
+	}
+
+	return rpost
+}
+
+func (s *Server) patchPostHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	patch := model.PostPatchFromJson(r.Body)
+	if patch == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing patch in request body.")
+		return
+	}
+
+	s.mut.Lock()
+
+	post, ok := s.posts[mux.Vars(r)["post_id"]]
+	if !ok || post.DeleteAt > 0 {
+		s.mut.Unlock()
+		writeError(w, http.StatusNotFound, "app.post.get.app_error", "Unable to get post.")
+		return
+	}
+	if post.UserId != userId {
+		s.mut.Unlock()
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	post.Patch(patch)
+	post.UpdateAt = model.GetMillis()
+	post.EditAt = post.UpdateAt
+
+	rpost := s.preparePost(post)
+	recipients := s.channelMemberIds(post.ChannelId)
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_EDITED, "", post.ChannelId, "", nil)
+	ev.Add("post", rpost.ToJson())
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, recipients)
+	writeJSON(w, http.StatusOK, rpost)
+}
+
+func (s *Server) searchPostsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	params := model.SearchParameterFromJson(r.Body)
+	if params == nil || params.Terms == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing search parameters in request body.")
+		return
+	}
+	teamId := mux.Vars(r)["team_id"]
+	terms := strings.Fields(strings.ToLower(*params.Terms))
+	isOrSearch := params.IsOrSearch != nil && *params.IsOrSearch
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	var matches []*model.Post
+	for _, post := range s.posts {
+		channel := s.channels[post.ChannelId]
+		if (channel.TeamId != teamId && !channel.IsGroupOrDirect()) || !s.isChannelMember(channel.Id, userId) {
+			continue
+		}
+		message := strings.ToLower(post.Message)
+		found := !isOrSearch
+		for _, term := range terms {
+			if isOrSearch {
+				found = found || strings.Contains(message, term)
+			} else {
+				found = found && strings.Contains(message, term)
+			}
+		}
+		if found && len(terms) > 0 {
+			matches = append(matches, post)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].CreateAt > matches[j].CreateAt
+	})
+	if len(matches) > 100 {
+		matches = matches[:100]
+	}
+
+	ids := make([]string, len(matches))
+	for i, post := range matches {
+		ids[i] = post.Id
+	}
+
+	writeJSON(w, http.StatusOK, s.postList(ids))
+}
+
+func (s *Server) getPostsForChannelHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+	query := r.URL.Query()
+	page, perPage := pageParams(r)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	if _, ok := s.channels[channelId]; !ok {
+		writeError(w, http.StatusNotFound, "app.channel.get.existing.app_error", "Unable to find the existing channel.")
+		return
+	}
+
+	posts := s.channelPosts[channelId]
+	var ids []string
+	switch {
+	case query.Get("since") != "":
+		since, err := strconv.ParseInt(query.Get("since"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "api.context.invalid_url_param.app_error", "Invalid since parameter.")
+			return
+		}
+		for _, id := range posts {
+			if s.posts[id].UpdateAt >= since {
+				ids = append(ids, id)
+			}
+		}
+		ids = reversed(ids)
+	case query.Get("before") != "":
+		idx := s.postIndex(channelId, query.Get("before"))
+		if idx < 0 {
+			idx = 0
+		}
+		ids = reversed(posts[:idx])
+		start, end := paginate(len(ids), page, perPage)
+		ids = ids[start:end]
+	case query.Get("after") != "":
+		idx := s.postIndex(channelId, query.Get("after"))
+		ids = posts[idx+1:]
+		start, end := paginate(len(ids), page, perPage)
+		ids = reversed(ids[start:end])
+	default:
+		ids = reversed(posts)
+		start, end := paginate(len(ids), page, perPage)
+		ids = ids[start:end]
+	}
+
+	writeJSON(w, http.StatusOK, s.postList(ids))
+}
+
+func (s *Server) getPinnedPostsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	var ids []string
+	for _, id := range s.channelPosts[channelId] {
+		if s.posts[id].IsPinned {
+			ids = append(ids, id)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, s.postList(reversed(ids)))
+}
+
+func (s *Server) getPostsAroundLastUnreadHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	channelId := mux.Vars(r)["channel_id"]
+	limitBefore, _ := strconv.Atoi(r.URL.Query().Get("limit_before"))
+	limitAfter, _ := strconv.Atoi(r.URL.Query().Get("limit_after"))
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	cm, ok := s.channelMembers[channelId][mux.Vars(r)["user_id"]]
+	if !ok {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	// Posts are split around the first one created after the last view.
+	posts := s.channelPosts[channelId]
+	idx := sort.Search(len(posts), func(i int) bool {
+		return s.posts[posts[i]].CreateAt > cm.LastViewedAt
+	})
+	start := idx - limitBefore
+	if start < 0 {
+		start = 0
+	}
+	end := idx + limitAfter
+	if end > len(posts) {
+		end = len(posts)
+	}
+
+	writeJSON(w, http.StatusOK, s.postList(reversed(posts[start:end])))
+}
+
+func (s *Server) getReactionsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	reactions := append([]*model.Reaction{}, s.reactions[mux.Vars(r)["post_id"]]...)
+
+	writeJSON(w, http.StatusOK, reactions)
+}
+
+func (s *Server) saveReactionHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	reaction := model.ReactionFromJson(r.Body)
+	if reaction == nil || reaction.EmojiName == "" || reaction.UserId != userId {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing reaction in request body.")
+		return
+	}
+
+	s.mut.Lock()
+
+	post, ok := s.posts[reaction.PostId]
+	if !ok || !s.isChannelMember(post.ChannelId, userId) {
+		s.mut.Unlock()
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	for _, re := range s.reactions[post.Id] {
+		if re.UserId == reaction.UserId && re.EmojiName == reaction.EmojiName {
+			s.mut.Unlock()
+			writeJSON(w, http.StatusOK, re)
+			return
+		}
+	}
+
+	reaction.CreateAt = 0
+	reaction.PreSave()
+	s.reactions[post.Id] = append(s.reactions[post.Id], reaction)
+	post.HasReactions = true
+
+	recipients := s.channelMemberIds(post.ChannelId)
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_REACTION_ADDED, "", post.ChannelId, "", nil)
+	ev.Add("reaction", reaction.ToJson())
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, recipients)
+	writeJSON(w, http.StatusOK, reaction)
+}
+
+func (s *Server) deleteReactionHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	vars := mux.Vars(r)
+	if vars["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	s.mut.Lock()
+
+	post, ok := s.posts[vars["post_id"]]
+	if !ok {
+		s.mut.Unlock()
+		writeError(w, http.StatusNotFound, "app.post.get.app_error", "Unable to get post.")
+		return
+	}
+
+	var removed *model.Reaction
+	reactions := s.reactions[post.Id][:0]
+	for _, re := range s.reactions[post.Id] {
+		if re.UserId == userId && re.EmojiName == vars["emoji_name"] {
+			removed = re
+			continue
+		}
+		reactions = append(reactions, re)
+	}
+	s.reactions[post.Id] = reactions
+	post.HasReactions = len(reactions) > 0
+
+	if removed == nil {
+		s.mut.Unlock()
+		writeStatusOK(w)
+		return
+	}
+
+	recipients := s.channelMemberIds(post.ChannelId)
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_REACTION_REMOVED, "", post.ChannelId, "", nil)
+	ev.Add("reaction", removed.ToJson())
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, recipients)
+	writeStatusOK(w)
+}
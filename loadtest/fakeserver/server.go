@@ -0,0 +1,309 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Package fakeserver provides an in-memory stand-in for a Mattermost server.
+// It implements the subset of the v4 REST API and of the WebSocket API used
+// by user.User implementations, so that full load-tests can run hermetically
+// as part of go test.
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// Server is a fake Mattermost server backed by in-memory state.
+type Server struct {
+	srv    *httptest.Server
+	router *mux.Router
+
+	mut            sync.RWMutex
+	config         *model.Config
+	sessions       map[string]string // token -> user id
+	users          map[string]*model.User
+	passwords      map[string]string // user id -> password
+	profileImages  map[string][]byte
+	preferences    map[string]map[string]model.Preference
+	teams          map[string]*model.Team
+	teamMembers    map[string]map[string]*model.TeamMember // team id -> user id -> member
+	channels       map[string]*model.Channel
+	channelMembers map[string]map[string]*model.ChannelMember // channel id -> user id -> member
+	posts          map[string]*model.Post
+	channelPosts   map[string][]string // channel id -> post ids in creation order
+	reactions      map[string][]*model.Reaction
+	files          map[string]*model.FileInfo
+	statuses       map[string]*model.Status            // user id -> status set manually
+	threadReplies  map[string][]string                 // root post id -> reply ids in creation order
+	threadMembers  map[string]map[string]*threadMember // root post id -> user id -> member
+	bots           map[string]*model.Bot               // bot user id -> bot
+	commands       map[string]*model.Command
+	incomingHooks  map[string]*model.IncomingWebhook
+	outgoingHooks  map[string]*model.OutgoingWebhook
+
+	hub *hub
+}
+
+// New creates and starts a new Server listening on a local random port.
+// Close should be called once the server is not needed anymore.
+func New() *Server {
+	config := &model.Config{}
+	config.SetDefaults()
+
+	s := &Server{
+		config:         config,
+		sessions:       map[string]string{},
+		users:          map[string]*model.User{},
+		passwords:      map[string]string{},
+		profileImages:  map[string][]byte{},
+		preferences:    map[string]map[string]model.Preference{},
+		teams:          map[string]*model.Team{},
+		teamMembers:    map[string]map[string]*model.TeamMember{},
+		channels:       map[string]*model.Channel{},
+		channelMembers: map[string]map[string]*model.ChannelMember{},
+		posts:          map[string]*model.Post{},
+		channelPosts:   map[string][]string{},
+		reactions:      map[string][]*model.Reaction{},
+		files:          map[string]*model.FileInfo{},
+		statuses:       map[string]*model.Status{},
+		threadReplies:  map[string][]string{},
+		threadMembers:  map[string]map[string]*threadMember{},
+		bots:           map[string]*model.Bot{},
+		commands:       map[string]*model.Command{},
+		incomingHooks:  map[string]*model.IncomingWebhook{},
+		outgoingHooks:  map[string]*model.OutgoingWebhook{},
+		hub:            newHub(),
+	}
+
+	s.router = s.setupRouter()
+	s.srv = httptest.NewServer(s.router)
+
+	return s
+}
+
+// URL returns the base URL of the server, to be used as ServerURL.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// WebSocketURL returns the base URL of the server, to be used as
+// WebSocketURL.
+func (s *Server) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+// Close closes all the active WebSocket connections and shuts down the server.
+func (s *Server) Close() {
+	s.hub.closeAll()
+	s.srv.Close()
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, userId string)
+
+func (s *Server) setupRouter() *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/", s.indexHandler).Methods("GET")
+	router.HandleFunc("/hooks/{hook_id}", s.incomingHookHandler).Methods("POST")
+
+	api := router.PathPrefix(model.API_URL_SUFFIX).Subrouter()
+	api.HandleFunc("/websocket", s.websocketHandler).Methods("GET")
+	api.HandleFunc("/users", s.createUserHandler).Methods("POST")
+	api.HandleFunc("/users/login", s.loginHandler).Methods("POST")
+
+	handle := func(path string, h handlerFunc, methods ...string) {
+		api.HandleFunc(path, s.requireSession(h)).Methods(methods...)
+	}
+
+	// users
+	handle("/users/logout", s.logoutHandler, "POST")
+	handle("/users", s.getUsersHandler, "GET")
+	handle("/users/me", s.getMeHandler, "GET")
+	handle("/users/ids", s.getUsersByIdsHandler, "POST")
+	handle("/users/usernames", s.getUsersByUsernamesHandler, "POST")
+	handle("/users/search", s.searchUsersHandler, "POST")
+	handle("/users/autocomplete", s.autocompleteUsersHandler, "GET")
+	handle("/users/status/ids", s.getStatusesByIdsHandler, "POST")
+	handle("/users/{user_id}", s.updateUserHandler, "PUT")
+	handle("/users/{user_id}/patch", s.patchUserHandler, "PUT")
+	handle("/users/{user_id}/roles", s.updateUserRolesHandler, "PUT")
+	handle("/users/{user_id}/image", s.getProfileImageHandler, "GET")
+	handle("/users/{user_id}/image", s.setProfileImageHandler, "POST")
+	handle("/users/{user_id}/status", s.getStatusHandler, "GET")
+	handle("/users/{user_id}/status", s.updateStatusHandler, "PUT")
+	handle("/users/{user_id}/status/custom", s.updateCustomStatusHandler, "PUT", "DELETE")
+	handle("/users/{user_id}/preferences", s.getPreferencesHandler, "GET")
+	handle("/users/{user_id}/preferences", s.updatePreferencesHandler, "PUT")
+	handle("/users/{user_id}/teams", s.getTeamsForUserHandler, "GET")
+	handle("/users/{user_id}/teams/members", s.getTeamMembersForUserHandler, "GET")
+	handle("/users/{user_id}/teams/unread", s.getTeamsUnreadHandler, "GET")
+	handle("/users/{user_id}/teams/{team_id}/channels", s.getChannelsForTeamForUserHandler, "GET")
+	handle("/users/{user_id}/teams/{team_id}/channels/members", s.getChannelMembersForUserHandler, "GET")
+	handle("/users/{user_id}/channels/{channel_id}/unread", s.getChannelUnreadHandler, "GET")
+	handle("/users/{user_id}/channels/{channel_id}/posts/unread", s.getPostsAroundLastUnreadHandler, "GET")
+	handle("/users/{user_id}/posts/{post_id}/reactions/{emoji_name}", s.deleteReactionHandler, "DELETE")
+	handle("/users/{user_id}/teams/{team_id}/threads", s.getUserThreadsHandler, "GET")
+	handle("/users/{user_id}/teams/{team_id}/threads/read", s.updateThreadsReadForUserHandler, "PUT")
+	handle("/users/{user_id}/teams/{team_id}/threads/{thread_id}", s.getUserThreadHandler, "GET")
+	handle("/users/{user_id}/teams/{team_id}/threads/{thread_id}/read/{timestamp}", s.updateThreadReadHandler, "PUT")
+	handle("/users/{user_id}/teams/{team_id}/threads/{thread_id}/following", s.updateThreadFollowHandler, "PUT", "DELETE")
+
+	// teams
+	handle("/teams", s.createTeamHandler, "POST")
+	handle("/teams", s.getAllTeamsHandler, "GET")
+	handle("/teams/members/invite", s.addTeamMemberFromInviteHandler, "POST")
+	handle("/teams/{team_id}", s.getTeamHandler, "GET")
+	handle("/teams/{team_id}", s.updateTeamHandler, "PUT")
+	handle("/teams/{team_id}/stats", s.getTeamStatsHandler, "GET")
+	handle("/teams/{team_id}/members", s.addTeamMemberHandler, "POST")
+	handle("/teams/{team_id}/members", s.getTeamMembersHandler, "GET")
+	handle("/teams/{team_id}/members/{user_id}", s.removeTeamMemberHandler, "DELETE")
+	handle("/teams/{team_id}/channels", s.getPublicChannelsForTeamHandler, "GET")
+	handle("/teams/{team_id}/channels/search", s.searchChannelsHandler, "POST")
+	handle("/teams/{team_id}/channels/autocomplete", s.autocompleteChannelsHandler, "GET")
+	handle("/teams/{team_id}/posts/search", s.searchPostsHandler, "POST")
+	handle("/teams/{team_id}/files/search", s.searchFilesHandler, "POST")
+
+	// channels
+	handle("/channels", s.createChannelHandler, "POST")
+	handle("/channels/direct", s.createDirectChannelHandler, "POST")
+	handle("/channels/group", s.createGroupChannelHandler, "POST")
+	handle("/channels/members/{user_id}/view", s.viewChannelHandler, "POST")
+	handle("/channels/{channel_id}", s.getChannelHandler, "GET")
+	handle("/channels/{channel_id}/stats", s.getChannelStatsHandler, "GET")
+	handle("/channels/{channel_id}/members", s.addChannelMemberHandler, "POST")
+	handle("/channels/{channel_id}/members", s.getChannelMembersHandler, "GET")
+	handle("/channels/{channel_id}/members/{user_id}", s.getChannelMemberHandler, "GET")
+	handle("/channels/{channel_id}/members/{user_id}", s.removeChannelMemberHandler, "DELETE")
+	handle("/channels/{channel_id}/members/{user_id}/notify_props", s.updateChannelNotifyPropsHandler, "PUT")
+	handle("/channels/{channel_id}/posts", s.getPostsForChannelHandler, "GET")
+	handle("/channels/{channel_id}/pinned", s.getPinnedPostsHandler, "GET")
+
+	// posts and reactions
+	handle("/posts", s.createPostHandler, "POST")
+	handle("/posts/{post_id}/patch", s.patchPostHandler, "PUT")
+	handle("/posts/{post_id}/thread", s.getPostThreadHandler, "GET")
+	handle("/posts/{post_id}/files/info", s.getFileInfosForPostHandler, "GET")
+	handle("/posts/{post_id}/reactions", s.getReactionsHandler, "GET")
+	handle("/reactions", s.saveReactionHandler, "POST")
+
+	// files and emojis
+	handle("/files", s.uploadFileHandler, "POST")
+	handle("/files/{file_id}", s.downloadFileHandler, "GET")
+	handle("/files/{file_id}/info", s.getFileInfoHandler, "GET")
+	handle("/files/{file_id}/thumbnail", s.getFileHandler, "GET")
+	handle("/files/{file_id}/preview", s.getFileHandler, "GET")
+	handle("/emoji", s.getEmojiListHandler, "GET")
+	handle("/emoji/{emoji_id}/image", s.getEmojiImageHandler, "GET")
+
+	// integrations
+	handle("/bots", s.createBotHandler, "POST")
+	handle("/commands", s.createCommandHandler, "POST")
+	handle("/commands/execute", s.executeCommandHandler, "POST")
+	handle("/hooks/incoming", s.createIncomingHookHandler, "POST")
+	handle("/hooks/outgoing", s.createOutgoingHookHandler, "POST")
+
+	// system
+	handle("/config", s.getConfigHandler, "GET")
+	handle("/config", s.updateConfigHandler, "PUT")
+	handle("/license/client", s.getClientLicenseHandler, "GET")
+	handle("/roles/names", s.getRolesByNamesHandler, "POST")
+	handle("/plugins/webapp", s.emptyListHandler, "GET")
+	handle("/plugins/statuses", s.emptyListHandler, "GET")
+	handle("/logs", s.emptyListHandler, "GET")
+	handle("/analytics/old", s.emptyListHandler, "GET")
+	handle("/cluster/status", s.emptyListHandler, "GET")
+
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "api.context.404.app_error", "Sorry, we could not find the page.")
+	})
+
+	return router
+}
+
+// requireSession wraps h so that it is only called for requests carrying
+// a valid session token. The id of the authenticated user is passed to h.
+func (s *Server) requireSession(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := s.sessionUserId(tokenFromRequest(r))
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "api.context.session_expired.app_error", "Invalid or expired session, please login again.")
+			return
+		}
+		h(w, r, userId)
+	}
+}
+
+func (s *Server) sessionUserId(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	userId, ok := s.sessions[token]
+	return userId, ok
+}
+
+func tokenFromRequest(r *http.Request) string {
+	fields := strings.Fields(r.Header.Get(model.HEADER_AUTH))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], model.HEADER_BEARER) {
+		return ""
+	}
+	return fields[1]
+}
+
+func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintln(w, "<html><head><title>Mattermost</title></head><body></body></html>")
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, id, message string) {
+	appErr := model.NewAppError("fakeserver", id, nil, "", status)
+	appErr.Message = message
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprint(w, appErr.ToJson())
+}
+
+func writeStatusOK(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]string{model.STATUS: model.STATUS_OK})
+}
+
+// pageParams returns the page and per_page query parameters of r,
+// applying the same defaults as the real server.
+func pageParams(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 0 {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 60
+	}
+	return page, perPage
+}
+
+// paginate returns the bounds of the given page of a list of n elements.
+func paginate(n, page, perPage int) (int, int) {
+	start := page * perPage
+	if start > n {
+		start = n
+	}
+	end := start + perPage
+	if end > n {
+		end = n
+	}
+	return start, end
+}
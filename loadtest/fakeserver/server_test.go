@@ -0,0 +1,464 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/clustercontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/gencontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/integcontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/noopcontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simplecontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control/simulcontroller"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store/memstore"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user/userentity"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newUser(t *testing.T, s *Server, id int) *userentity.UserEntity {
+	t.Helper()
+	store, err := memstore.New(nil)
+	require.NoError(t, err)
+	ue := userentity.New(userentity.Setup{Store: store}, userentity.Config{
+		ServerURL:    s.URL(),
+		WebSocketURL: s.WebSocketURL(),
+		Username:     fmt.Sprintf("testuser-%d", id),
+		Email:        fmt.Sprintf("testuser-%d@example.com", id),
+		Password:     "testPass123$",
+	})
+	require.NotNil(t, ue)
+	return ue
+}
+
+func signUpAndLogin(t *testing.T, ue *userentity.UserEntity) {
+	t.Helper()
+	require.NoError(t, ue.SignUp(ue.Store().Email(), ue.Store().Username(), ue.Store().Password()))
+	require.NoError(t, ue.Login())
+}
+
+func TestUserEntity(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	user1 := newUser(t, s, 1)
+	user2 := newUser(t, s, 2)
+	signUpAndLogin(t, user1)
+	signUpAndLogin(t, user2)
+
+	t.Run("SignUpTwice", func(t *testing.T) {
+		err := user1.SignUp(user1.Store().Email(), user1.Store().Username(), "otherPass")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already exists")
+	})
+
+	teamId, err := user1.CreateTeam(&model.Team{
+		Name:        "team",
+		DisplayName: "Team",
+		Type:        model.TEAM_OPEN,
+	})
+	require.NoError(t, err)
+	require.NoError(t, user2.AddTeamMember(teamId, user2.Store().Id()))
+
+	require.NoError(t, user1.GetChannelsForTeam(teamId, false))
+	channels, err := user1.Store().Channels(teamId)
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+
+	require.NoError(t, user2.GetChannelsForTeam(teamId, false))
+	channel, err := user2.Store().Channels(teamId)
+	require.NoError(t, err)
+	require.Len(t, channel, 2)
+	townSquare := channels[0]
+	if townSquare.Name != model.DEFAULT_CHANNEL {
+		townSquare = channels[1]
+	}
+
+	_, err = user1.Connect()
+	require.NoError(t, err)
+	defer user1.Disconnect()
+	require.NoError(t, user1.SetCurrentChannel(&townSquare))
+
+	// Wait for the connection to be registered before posting.
+	require.Eventually(t, func() bool {
+		return s.hub.isConnected(user1.Store().Id())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	postId, err := user2.CreatePost(&model.Post{
+		ChannelId: townSquare.Id,
+		Message:   "hello world",
+	})
+	require.NoError(t, err)
+
+	ev := waitForEvent(t, user1, model.WEBSOCKET_EVENT_POSTED)
+	require.Equal(t, townSquare.Id, ev.GetBroadcast().ChannelId)
+	require.Eventually(t, func() bool {
+		posts, err := user1.Store().ChannelPosts(townSquare.Id)
+		return err == nil && len(posts) == 1 && posts[0].Id == postId
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, user2.SaveReaction(&model.Reaction{
+		UserId:    user2.Store().Id(),
+		PostId:    postId,
+		EmojiName: "smile",
+	}))
+	waitForEvent(t, user1, model.WEBSOCKET_EVENT_REACTION_ADDED)
+
+	list, err := user1.SearchPosts(teamId, "hello", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{postId}, list.Order)
+
+	unread, err := user1.GetChannelUnread(townSquare.Id)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, unread.MsgCount)
+
+	_, err = user1.ViewChannel(&model.ChannelView{ChannelId: townSquare.Id})
+	require.NoError(t, err)
+	unread, err = user1.GetChannelUnread(townSquare.Id)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, unread.MsgCount)
+
+	_, err = user1.CreatePost(&model.Post{
+		ChannelId: townSquare.Id,
+		RootId:    postId,
+		Message:   "hello back",
+	})
+	require.NoError(t, err)
+	waitForEvent(t, user1, user.WebSocketEventThreadUpdated)
+
+	threads, err := user1.GetUserThreads(teamId, false, 25)
+	require.NoError(t, err)
+	require.Len(t, threads, 1)
+	require.Equal(t, postId, threads[0].PostId)
+	require.EqualValues(t, 1, threads[0].ReplyCount)
+
+	order, err := user1.GetPostThread(postId)
+	require.NoError(t, err)
+	require.Len(t, order, 2)
+
+	require.NoError(t, user1.UpdateThreadRead(teamId, postId, model.GetMillis()))
+	waitForEvent(t, user1, user.WebSocketEventThreadReadChanged)
+	require.NoError(t, user1.UpdateThreadFollow(teamId, postId, false))
+	waitForEvent(t, user1, user.WebSocketEventThreadFollowChanged)
+	_, err = user1.Store().Thread(postId)
+	require.True(t, errors.Is(err, memstore.ErrThreadNotFound))
+	threads, err = user1.GetUserThreads(teamId, false, 25)
+	require.NoError(t, err)
+	require.Empty(t, threads)
+
+	resp, err := user1.UploadFile([]byte("some data"), townSquare.Id, "report.txt")
+	require.NoError(t, err)
+	require.Len(t, resp.FileInfos, 1)
+	fileId := resp.FileInfos[0].Id
+	_, err = user1.CreatePost(&model.Post{
+		ChannelId: townSquare.Id,
+		Message:   "see attached",
+		FileIds:   []string{fileId},
+	})
+	require.NoError(t, err)
+	waitForEvent(t, user1, model.WEBSOCKET_EVENT_POSTED)
+
+	info, err := user2.GetFileInfo(fileId)
+	require.NoError(t, err)
+	require.Equal(t, "report.txt", info.Name)
+	require.NoError(t, user2.DownloadFile(fileId))
+
+	infos, err := user2.SearchFiles(teamId, "report", false)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	infos, err = user2.SearchFilesInChannel(townSquare.Id)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	infos, err = user2.SearchFiles(teamId, "missing", false)
+	require.NoError(t, err)
+	require.Empty(t, infos)
+
+	require.NoError(t, user2.UpdateUserStatus(model.STATUS_DND))
+	waitForEvent(t, user1, model.WEBSOCKET_EVENT_STATUS_CHANGE)
+	status, err := user1.Store().Status(user2.Store().Id())
+	require.NoError(t, err)
+	require.Equal(t, model.STATUS_DND, status.Status)
+	require.Error(t, user2.UpdateUserStatus("busy"))
+
+	require.NoError(t, user2.UpdateCustomStatus(&user.CustomStatus{Emoji: "calendar", Text: "In a meeting"}))
+	waitForEvent(t, user1, model.WEBSOCKET_EVENT_USER_UPDATED)
+	s.mut.RLock()
+	require.Contains(t, s.users[user2.Store().Id()].Props[user.CustomStatusPropKey], "In a meeting")
+	s.mut.RUnlock()
+	require.NoError(t, user2.RemoveCustomStatus())
+	waitForEvent(t, user1, model.WEBSOCKET_EVENT_USER_UPDATED)
+	s.mut.RLock()
+	require.NotContains(t, s.users[user2.Store().Id()].Props, user.CustomStatusPropKey)
+	s.mut.RUnlock()
+
+	require.NoError(t, user1.GetChannelMember(townSquare.Id, user1.Store().Id()))
+	require.NoError(t, user1.UpdateChannelNotifyProps(townSquare.Id, user1.Store().Id(), map[string]string{
+		model.MARK_UNREAD_NOTIFY_PROP: model.CHANNEL_MARK_UNREAD_MENTION,
+		model.PUSH_NOTIFY_PROP:        model.CHANNEL_NOTIFY_NONE,
+	}))
+	waitForEvent(t, user1, model.WEBSOCKET_EVENT_CHANNEL_MEMBER_UPDATED)
+	member, err := user1.Store().ChannelMember(townSquare.Id, user1.Store().Id())
+	require.NoError(t, err)
+	require.Equal(t, model.CHANNEL_MARK_UNREAD_MENTION, member.NotifyProps[model.MARK_UNREAD_NOTIFY_PROP])
+	require.Equal(t, model.CHANNEL_NOTIFY_NONE, member.NotifyProps[model.PUSH_NOTIFY_PROP])
+	require.Equal(t, model.CHANNEL_NOTIFY_DEFAULT, member.NotifyProps[model.DESKTOP_NOTIFY_PROP])
+	require.Error(t, user2.UpdateChannelNotifyProps(townSquare.Id, user1.Store().Id(), map[string]string{
+		model.PUSH_NOTIFY_PROP: model.CHANNEL_NOTIFY_ALL,
+	}))
+
+	ok, err := user2.Logout()
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, err = user2.GetMe()
+	require.Error(t, err)
+}
+
+func waitForEvent(t *testing.T, ue *userentity.UserEntity, eventType string) *model.WebSocketEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-ue.Events():
+			if ev.EventType() == eventType {
+				return ev
+			}
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for event", eventType)
+		}
+	}
+}
+
+func newConfig(t *testing.T, s *Server, controllerType string, numUsers int) *loadtest.Config {
+	var config loadtest.Config
+	require.NoError(t, defaults.Set(&config))
+	config.ConnectionConfiguration.ServerURL = s.URL()
+	config.ConnectionConfiguration.WebSocketURL = s.WebSocketURL()
+	switch controllerType {
+	case loadtest.UserControllerGenerative:
+		config.UserControllerConfiguration.Type = loadtest.UserControllerGenerative
+	case loadtest.UserControllerSimulative:
+		config.UserControllerConfiguration.Type = loadtest.UserControllerSimulative
+	case loadtest.UserControllerIntegrations:
+		config.UserControllerConfiguration.Type = loadtest.UserControllerIntegrations
+	case loadtest.UserControllerNoop:
+		config.UserControllerConfiguration.Type = loadtest.UserControllerNoop
+	case loadtest.UserControllerCluster:
+		config.UserControllerConfiguration.Type = loadtest.UserControllerCluster
+	default:
+		config.UserControllerConfiguration.Type = loadtest.UserControllerSimple
+	}
+	config.UsersConfiguration.InitialActiveUsers = numUsers
+	config.UsersConfiguration.MaxActiveUsers = numUsers
+	config.LogSettings.EnableConsole = false
+	config.LogSettings.EnableFile = false
+	return &config
+}
+
+func newControllerFn(t *testing.T, s *Server, controllerType string) loadtest.NewController {
+	return func(id int, status chan<- control.UserStatus) (control.UserController, error) {
+		ue := newUser(t, s, id)
+		switch controllerType {
+		case loadtest.UserControllerGenerative:
+			return gencontroller.New(id, ue, &gencontroller.Config{
+				NumTeams:               1,
+				NumChannels:            5,
+				NumPosts:               20,
+				NumReactions:           5,
+				PercentReplies:         0.5,
+				PercentPublicChannels:  0.4,
+				PercentPrivateChannels: 0.2,
+				PercentDirectChannels:  0.2,
+				PercentGroupChannels:   0.2,
+			}, status)
+		case loadtest.UserControllerSimulative:
+			config, err := simulcontroller.ReadConfig("../../config/simulcontroller.sample.json")
+			require.NoError(t, err)
+			config.MinIdleTimeMs = 10
+			config.AvgIdleTimeMs = 50
+			// The fake server supports Collaborative Threads, file search and
+			// custom statuses.
+			for i := range config.Actions {
+				switch config.Actions[i].ActionId {
+				case "ViewThread", "SearchFiles", "SetCustomStatus":
+					config.Actions[i].Frequency = 15
+				}
+			}
+			for i := range config.FileSizeDistribution {
+				config.FileSizeDistribution[i].SizeKB = 1
+			}
+			return simulcontroller.New(id, ue, config, status)
+		case loadtest.UserControllerNoop:
+			return noopcontroller.New(id, ue, status)
+		case loadtest.UserControllerCluster:
+			return clustercontroller.New(id, ue, status)
+		default:
+			config, err := simplecontroller.ReadConfig("../../config/simplecontroller.sample.json")
+			require.NoError(t, err)
+			for i := range config.Actions {
+				config.Actions[i].WaitAfterMs = 10
+			}
+			return simplecontroller.New(id, ue, config, status)
+		}
+	}
+}
+
+func TestLoadTest(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	numUsers := 4
+
+	// Data generation.
+	config := newConfig(t, s, loadtest.UserControllerGenerative, numUsers)
+	config.UserControllerConfiguration.RatesDistribution = []loadtest.RatesDistribution{
+		{Rate: 0.01, Percentage: 1.0},
+	}
+	lt, err := loadtest.New(config, newControllerFn(t, s, loadtest.UserControllerGenerative))
+	require.NoError(t, err)
+	require.NoError(t, lt.Run())
+	require.Eventually(t, func() bool {
+		return lt.Status().NumUsersStopped == int64(numUsers)
+	}, 30*time.Second, 100*time.Millisecond)
+	require.NoError(t, lt.Stop())
+
+	s.mut.RLock()
+	require.Len(t, s.teams, 1)
+	require.Len(t, s.users, numUsers)
+	numPosts := len(s.posts)
+	s.mut.RUnlock()
+	require.GreaterOrEqual(t, numPosts, 20)
+
+	// Users should interact with the generated data.
+	for _, controllerType := range []string{loadtest.UserControllerSimulative, string(loadtest.UserControllerSimple)} {
+		t.Run(controllerType, func(t *testing.T) {
+			config := newConfig(t, s, controllerType, numUsers)
+			lt, err := loadtest.New(config, newControllerFn(t, s, controllerType))
+			require.NoError(t, err)
+			require.NoError(t, lt.Run())
+			require.Eventually(t, func() bool {
+				return lt.Status().NumUsers == int64(numUsers)
+			}, 5*time.Second, 10*time.Millisecond)
+			require.Eventually(t, func() bool {
+				s.mut.RLock()
+				defer s.mut.RUnlock()
+				return len(s.posts) > numPosts
+			}, 10*time.Second, 10*time.Millisecond)
+			require.NoError(t, lt.Stop())
+			require.Zero(t, lt.Status().NumUsers)
+
+			s.mut.RLock()
+			numPosts = len(s.posts)
+			s.mut.RUnlock()
+		})
+	}
+	// The noop and cluster controllers act at a fixed pace, which the rate
+	// speeds up.
+	fastRates := []loadtest.RatesDistribution{
+		{Rate: 0.01, Percentage: 1.0},
+	}
+
+	t.Run(loadtest.UserControllerNoop, func(t *testing.T) {
+		config := newConfig(t, s, loadtest.UserControllerNoop, numUsers)
+		config.UserControllerConfiguration.RatesDistribution = fastRates
+		lt, err := loadtest.New(config, newControllerFn(t, s, loadtest.UserControllerNoop))
+		require.NoError(t, err)
+		require.NoError(t, lt.Run())
+		require.Eventually(t, func() bool {
+			return lt.Status().NumUsers == int64(numUsers)
+		}, 5*time.Second, 10*time.Millisecond)
+		// Users should log in and connect through the WebSocket.
+		require.Eventually(t, func() bool {
+			s.mut.RLock()
+			defer s.mut.RUnlock()
+			for _, user := range s.users {
+				if !s.hub.isConnected(user.Id) {
+					return false
+				}
+			}
+			return true
+		}, 10*time.Second, 10*time.Millisecond)
+		require.NoError(t, lt.Stop())
+		require.Zero(t, lt.Status().NumUsers)
+	})
+
+	t.Run(loadtest.UserControllerCluster, func(t *testing.T) {
+		// The cluster controller is meant to be run by system admins.
+		s.mut.Lock()
+		for _, user := range s.users {
+			user.Roles = model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID
+		}
+		enableDeveloper := *s.config.ServiceSettings.EnableDeveloper
+		s.mut.Unlock()
+
+		config := newConfig(t, s, loadtest.UserControllerCluster, 1)
+		config.UserControllerConfiguration.RatesDistribution = fastRates
+		lt, err := loadtest.New(config, newControllerFn(t, s, loadtest.UserControllerCluster))
+		require.NoError(t, err)
+		require.NoError(t, lt.Run())
+		// Users should go through all the actions, the last one updating
+		// the config.
+		require.Eventually(t, func() bool {
+			s.mut.RLock()
+			defer s.mut.RUnlock()
+			return *s.config.ServiceSettings.EnableDeveloper != enableDeveloper
+		}, 10*time.Second, 10*time.Millisecond)
+		require.NoError(t, lt.Stop())
+		require.Zero(t, lt.Status().NumErrors)
+	})
+
+	t.Run(loadtest.UserControllerIntegrations, func(t *testing.T) {
+		receiver, err := integcontroller.NewReceiver("127.0.0.1:0")
+		require.NoError(t, err)
+		defer receiver.Close()
+
+		icConfig, err := integcontroller.ReadConfig("../../config/integcontroller.sample.json")
+		require.NoError(t, err)
+		icConfig.MinIdleTimeMs = 10
+		icConfig.AvgIdleTimeMs = 50
+		icConfig.ReceiverURL = "http://" + receiver.Addr()
+
+		config := newConfig(t, s, loadtest.UserControllerIntegrations, numUsers)
+		lt, err := loadtest.New(config, func(id int, status chan<- control.UserStatus) (control.UserController, error) {
+			return integcontroller.New(id, newUser(t, s, id), icConfig, status)
+		})
+		require.NoError(t, err)
+		require.NoError(t, lt.Run())
+		require.Eventually(t, func() bool {
+			return lt.Status().NumUsers == int64(numUsers)
+		}, 5*time.Second, 10*time.Millisecond)
+		// Custom slash commands and outgoing webhooks should reach the
+		// receiver, and integrations should create posts.
+		require.Eventually(t, func() bool {
+			return receiver.NumRequests() > int64(2*numUsers)
+		}, 10*time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool {
+			s.mut.RLock()
+			defer s.mut.RUnlock()
+			for _, post := range s.posts {
+				if post.GetProp("from_webhook") == "true" && post.RootId == "" {
+					return true
+				}
+			}
+			return false
+		}, 10*time.Second, 10*time.Millisecond)
+		require.NoError(t, lt.Stop())
+		require.Zero(t, lt.Status().NumUsers)
+
+		s.mut.RLock()
+		defer s.mut.RUnlock()
+		require.Len(t, s.bots, numUsers)
+		require.Len(t, s.commands, numUsers)
+		require.Len(t, s.incomingHooks, numUsers)
+		require.Len(t, s.outgoingHooks, numUsers)
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// isSystemAdmin reports whether the given user has the system admin role.
+func (s *Server) isSystemAdmin(userId string) bool {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	user, ok := s.users[userId]
+	return ok && user.IsSystemAdmin()
+}
+
+func (s *Server) getConfigHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	if !s.isSystemAdmin(userId) {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	writeJSON(w, http.StatusOK, s.config)
+}
+
+func (s *Server) updateConfigHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	if !s.isSystemAdmin(userId) {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	cfg := model.ConfigFromJson(r.Body)
+	if cfg == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing config in request body.")
+		return
+	}
+	cfg.SetDefaults()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.config = cfg
+
+	writeJSON(w, http.StatusOK, s.config)
+}
+
+func (s *Server) getClientLicenseHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	writeJSON(w, http.StatusOK, map[string]string{"IsLicensed": "false"})
+}
+
+func (s *Server) getRolesByNamesHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	roles := []*model.Role{}
+	for _, name := range model.ArrayFromJson(r.Body) {
+		roles = append(roles, &model.Role{
+			Id:            name,
+			Name:          name,
+			DisplayName:   name,
+			Permissions:   []string{},
+			BuiltIn:       true,
+			SchemeManaged: true,
+		})
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+func (s *Server) getEmojiListHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	writeJSON(w, http.StatusOK, []*model.Emoji{})
+}
+
+func (s *Server) getEmojiImageHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	writeError(w, http.StatusNotFound, "app.emoji.get.no_result", "Unable to find the emoji.")
+}
+
+// emptyListHandler serves the endpoints for which the fake server has no
+// meaningful data to return.
+func (s *Server) emptyListHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	writeJSON(w, http.StatusOK, []interface{}{})
+}
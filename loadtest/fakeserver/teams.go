@@ -0,0 +1,314 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// defaultChannels lists the channels every team is created with and every
+// new team member gets added to.
+var defaultChannels = []struct {
+	name        string
+	displayName string
+}{
+	{model.DEFAULT_CHANNEL, "Town Square"},
+	{"off-topic", "Off-Topic"},
+}
+
+// sortedTeams returns the teams matching filter, sorted by name.
+// It must be called with s.mut held.
+func (s *Server) sortedTeams(filter func(t *model.Team) bool) []*model.Team {
+	teams := make([]*model.Team, 0, len(s.teams))
+	for _, t := range s.teams {
+		if filter == nil || filter(t) {
+			teams = append(teams, t)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+	return teams
+}
+
+// addTeamMember adds the given user to the team and to its default channels.
+// It must be called with s.mut held.
+func (s *Server) addTeamMember(teamId, userId, roles string) *model.TeamMember {
+	if tm, ok := s.teamMembers[teamId][userId]; ok {
+		return tm
+	}
+
+	tm := &model.TeamMember{
+		TeamId: teamId,
+		UserId: userId,
+		Roles:  roles,
+	}
+	s.teamMembers[teamId][userId] = tm
+
+	for _, dc := range defaultChannels {
+		if channel := s.channelByName(teamId, dc.name); channel != nil {
+			s.addChannelMember(channel.Id, userId)
+		}
+	}
+
+	return tm
+}
+
+// teamMsgCount returns the number of unread messages for the given user in
+// the channels of the team.
+// It must be called with s.mut held.
+func (s *Server) teamMsgCount(teamId, userId string) int64 {
+	var count int64
+	for _, channel := range s.channels {
+		if channel.TeamId != teamId {
+			continue
+		}
+		if cm, ok := s.channelMembers[channel.Id][userId]; ok {
+			count += channel.TotalMsgCount - cm.MsgCount
+		}
+	}
+	return count
+}
+
+func (s *Server) createTeamHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	team := model.TeamFromJson(r.Body)
+	if team == nil || team.Name == "" || team.DisplayName == "" {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing team in request body.")
+		return
+	}
+	if team.Type == "" {
+		team.Type = model.TEAM_OPEN
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, t := range s.teams {
+		if t.Name == team.Name {
+			writeError(w, http.StatusBadRequest, "store.sql_team.save.domain_exists.app_error", "A team with that name already exists.")
+			return
+		}
+	}
+
+	team.Id = ""
+	team.PreSave()
+	s.teams[team.Id] = team
+	s.teamMembers[team.Id] = map[string]*model.TeamMember{}
+
+	for _, dc := range defaultChannels {
+		s.createChannel(&model.Channel{
+			TeamId:      team.Id,
+			Name:        dc.name,
+			DisplayName: dc.displayName,
+			Type:        model.CHANNEL_OPEN,
+		})
+	}
+
+	s.addTeamMember(team.Id, userId, "team_user team_admin")
+
+	writeJSON(w, http.StatusCreated, team)
+}
+
+func (s *Server) getAllTeamsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	page, perPage := pageParams(r)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	teams := s.sortedTeams(nil)
+	start, end := paginate(len(teams), page, perPage)
+
+	writeJSON(w, http.StatusOK, teams[start:end])
+}
+
+func (s *Server) getTeamHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	team, ok := s.teams[mux.Vars(r)["team_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.team.get.find.app_error", "Unable to find the existing team.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, team)
+}
+
+func (s *Server) updateTeamHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	team := model.TeamFromJson(r.Body)
+	if team == nil || team.Id != mux.Vars(r)["team_id"] {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing team in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	existing, ok := s.teams[team.Id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.team.get.find.app_error", "Unable to find the existing team.")
+		return
+	}
+
+	existing.DisplayName = team.DisplayName
+	existing.Description = team.Description
+	existing.CompanyName = team.CompanyName
+	existing.AllowedDomains = team.AllowedDomains
+	existing.AllowOpenInvite = team.AllowOpenInvite
+	existing.PreUpdate()
+
+	writeJSON(w, http.StatusOK, existing)
+}
+
+func (s *Server) getTeamStatsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	if _, ok := s.teams[teamId]; !ok {
+		writeError(w, http.StatusNotFound, "app.team.get.find.app_error", "Unable to find the existing team.")
+		return
+	}
+
+	count := int64(len(s.teamMembers[teamId]))
+	writeJSON(w, http.StatusOK, &model.TeamStats{
+		TeamId:            teamId,
+		TotalMemberCount:  count,
+		ActiveMemberCount: count,
+	})
+}
+
+func (s *Server) addTeamMemberHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	member := model.TeamMemberFromJson(r.Body)
+	teamId := mux.Vars(r)["team_id"]
+	if member == nil || member.TeamId != teamId {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing team member in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.teams[teamId]; !ok {
+		writeError(w, http.StatusNotFound, "app.team.get.find.app_error", "Unable to find the existing team.")
+		return
+	}
+	if _, ok := s.users[member.UserId]; !ok {
+		writeError(w, http.StatusNotFound, "app.user.missing_account.const", "Unable to find the user.")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.addTeamMember(teamId, member.UserId, "team_user"))
+}
+
+func (s *Server) addTeamMemberFromInviteHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	inviteId := r.URL.Query().Get("invite_id")
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, team := range s.teams {
+		if inviteId != "" && team.InviteId == inviteId {
+			writeJSON(w, http.StatusCreated, s.addTeamMember(team.Id, userId, "team_user"))
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "api.team.add_user_to_team_from_invite.invalid.app_error", "Invalid invite.")
+}
+
+func (s *Server) removeTeamMemberHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.teamMembers[teamId][memberId]; !ok {
+		writeError(w, http.StatusNotFound, "app.team.get_member.missing.app_error", "No team member found for that user ID and team ID.")
+		return
+	}
+
+	delete(s.teamMembers[teamId], memberId)
+	for _, channel := range s.channels {
+		if channel.TeamId == teamId {
+			delete(s.channelMembers[channel.Id], memberId)
+		}
+	}
+
+	writeStatusOK(w)
+}
+
+func (s *Server) getTeamMembersHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	teamId := mux.Vars(r)["team_id"]
+	page, perPage := pageParams(r)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	members := make([]*model.TeamMember, 0, len(s.teamMembers[teamId]))
+	for _, tm := range s.teamMembers[teamId] {
+		members = append(members, tm)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].UserId < members[j].UserId
+	})
+	start, end := paginate(len(members), page, perPage)
+
+	writeJSON(w, http.StatusOK, members[start:end])
+}
+
+func (s *Server) getTeamsForUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	writeJSON(w, http.StatusOK, s.sortedTeams(func(t *model.Team) bool {
+		_, ok := s.teamMembers[t.Id][memberId]
+		return ok
+	}))
+}
+
+func (s *Server) getTeamMembersForUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	memberId := mux.Vars(r)["user_id"]
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	members := []*model.TeamMember{}
+	for _, team := range s.sortedTeams(nil) {
+		if tm, ok := s.teamMembers[team.Id][memberId]; ok {
+			members = append(members, tm)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (s *Server) getTeamsUnreadHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	memberId := mux.Vars(r)["user_id"]
+	excludeTeamId := r.URL.Query().Get("exclude_team")
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	unreads := []*model.TeamUnread{}
+	for _, team := range s.sortedTeams(nil) {
+		if _, ok := s.teamMembers[team.Id][memberId]; !ok || team.Id == excludeTeamId {
+			continue
+		}
+		unreads = append(unreads, &model.TeamUnread{
+			TeamId:   team.Id,
+			MsgCount: s.teamMsgCount(team.Id, memberId),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, unreads)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// threadMember holds the state of a thread for one of its participants.
+type threadMember struct {
+	following  bool
+	lastViewed int64
+}
+
+// addReply records the given reply to its thread, making both its author
+// and the author of the root post follow it. It returns the ids of the users
+// following the thread.
+// It must be called with s.mut held.
+func (s *Server) addReply(reply *model.Post) []string {
+	rootId := reply.RootId
+	s.threadReplies[rootId] = append(s.threadReplies[rootId], reply.Id)
+
+	members, ok := s.threadMembers[rootId]
+	if !ok {
+		members = map[string]*threadMember{}
+		s.threadMembers[rootId] = members
+	}
+	for _, id := range []string{s.posts[rootId].UserId, reply.UserId} {
+		if _, ok := members[id]; !ok {
+			members[id] = &threadMember{following: true}
+		}
+	}
+	members[reply.UserId].lastViewed = reply.CreateAt
+
+	var followers []string
+	for id, m := range members {
+		if m.following && s.isChannelMember(reply.ChannelId, id) {
+			followers = append(followers, id)
+		}
+	}
+	return followers
+}
+
+// userThread builds the thread of the given root post as seen by userId.
+// It must be called with s.mut held.
+func (s *Server) userThread(rootId, userId string) *store.Thread {
+	root := s.posts[rootId]
+	m := s.threadMembers[rootId][userId]
+	thread := &store.Thread{
+		PostId:       rootId,
+		LastViewedAt: m.lastViewed,
+		Post:         s.preparePost(root),
+	}
+
+	seen := map[string]bool{}
+	for _, id := range s.threadReplies[rootId] {
+		reply := s.posts[id]
+		thread.ReplyCount++
+		thread.LastReplyAt = reply.CreateAt
+		if reply.CreateAt > m.lastViewed && reply.UserId != userId {
+			thread.UnreadReplies++
+		}
+		if !seen[reply.UserId] {
+			seen[reply.UserId] = true
+			thread.Participants = append(thread.Participants, s.users[reply.UserId])
+		}
+	}
+	return thread
+}
+
+// threadInTeam returns whether the thread of the given root post belongs to
+// the given team, including threads in direct and group channels the user is
+// a member of.
+// It must be called with s.mut held.
+func (s *Server) threadInTeam(rootId, teamId, userId string) bool {
+	channel := s.channels[s.posts[rootId].ChannelId]
+	return (channel.TeamId == teamId || channel.IsGroupOrDirect()) && s.isChannelMember(channel.Id, userId)
+}
+
+// threadUpdatedEvent returns a thread_updated event for the given user.
+// It must be called with s.mut held.
+func (s *Server) threadUpdatedEvent(rootId, userId string) *model.WebSocketEvent {
+	thread := s.userThread(rootId, userId)
+	data, _ := json.Marshal(thread)
+	ev := model.NewWebSocketEvent(user.WebSocketEventThreadUpdated, s.channels[thread.Post.ChannelId].TeamId, "", userId, nil)
+	ev.Add("thread", string(data))
+	return ev
+}
+
+func (s *Server) getUserThreadsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	vars := mux.Vars(r)
+	if vars["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	query := r.URL.Query()
+	unreadOnly := query.Get("unread") == "true"
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 30
+	}
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	res := &store.Threads{Threads: []*store.Thread{}}
+	var threads []*store.Thread
+	for rootId, members := range s.threadMembers {
+		if m, ok := members[userId]; !ok || !m.following || !s.threadInTeam(rootId, vars["team_id"], userId) {
+			continue
+		}
+		thread := s.userThread(rootId, userId)
+		res.Total++
+		if thread.UnreadReplies > 0 {
+			res.TotalUnreadThreads++
+		} else if unreadOnly {
+			continue
+		}
+		threads = append(threads, thread)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].LastReplyAt > threads[j].LastReplyAt
+	})
+	if len(threads) > pageSize {
+		threads = threads[:pageSize]
+	}
+	res.Threads = append(res.Threads, threads...)
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) getUserThreadHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	vars := mux.Vars(r)
+	if vars["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	if _, ok := s.threadMembers[vars["thread_id"]][userId]; !ok {
+		writeError(w, http.StatusNotFound, "app.thread.get_membership.app_error", "Unable to get the thread membership.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.userThread(vars["thread_id"], userId))
+}
+
+func (s *Server) updateThreadReadHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	vars := mux.Vars(r)
+	if vars["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	timestamp, err := strconv.ParseInt(vars["timestamp"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_url_param.app_error", "Invalid timestamp parameter.")
+		return
+	}
+
+	s.mut.Lock()
+
+	m, ok := s.threadMembers[vars["thread_id"]][userId]
+	if !ok {
+		s.mut.Unlock()
+		writeError(w, http.StatusNotFound, "app.thread.get_membership.app_error", "Unable to get the thread membership.")
+		return
+	}
+	m.lastViewed = timestamp
+
+	thread := s.userThread(vars["thread_id"], userId)
+	ev := model.NewWebSocketEvent(user.WebSocketEventThreadReadChanged, vars["team_id"], "", userId, nil)
+	ev.Add("thread_id", vars["thread_id"])
+	ev.Add("timestamp", timestamp)
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, []string{userId})
+	writeJSON(w, http.StatusOK, thread)
+}
+
+func (s *Server) updateThreadsReadForUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	vars := mux.Vars(r)
+	if vars["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	s.mut.Lock()
+
+	now := model.GetMillis()
+	for rootId, members := range s.threadMembers {
+		if m, ok := members[userId]; ok && s.threadInTeam(rootId, vars["team_id"], userId) {
+			m.lastViewed = now
+		}
+	}
+
+	ev := model.NewWebSocketEvent(user.WebSocketEventThreadReadChanged, vars["team_id"], "", userId, nil)
+	ev.Add("timestamp", now)
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, []string{userId})
+	writeStatusOK(w)
+}
+
+func (s *Server) updateThreadFollowHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	vars := mux.Vars(r)
+	if vars["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+	rootId := vars["thread_id"]
+	state := r.Method == http.MethodPut
+
+	s.mut.Lock()
+
+	root, ok := s.posts[rootId]
+	if !ok || root.RootId != "" || !s.isChannelMember(root.ChannelId, userId) {
+		s.mut.Unlock()
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	members, ok := s.threadMembers[rootId]
+	if !ok {
+		members = map[string]*threadMember{}
+		s.threadMembers[rootId] = members
+	}
+	if m, ok := members[userId]; ok {
+		m.following = state
+	} else {
+		members[userId] = &threadMember{following: state}
+	}
+
+	ev := model.NewWebSocketEvent(user.WebSocketEventThreadFollowChanged, vars["team_id"], "", userId, nil)
+	ev.Add("thread_id", rootId)
+	ev.Add("state", state)
+	ev.Add("reply_count", len(s.threadReplies[rootId]))
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, []string{userId})
+	writeStatusOK(w)
+}
+
+func (s *Server) getPostThreadHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	post, ok := s.posts[mux.Vars(r)["post_id"]]
+	if !ok || post.DeleteAt > 0 {
+		writeError(w, http.StatusNotFound, "app.post.get.app_error", "Unable to get post.")
+		return
+	}
+	if !s.isChannelMember(post.ChannelId, userId) {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	rootId := post.Id
+	if post.RootId != "" {
+		rootId = post.RootId
+	}
+	ids := append([]string{rootId}, s.threadReplies[rootId]...)
+
+	writeJSON(w, http.StatusOK, s.postList(reversed(ids)))
+}
@@ -0,0 +1,511 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// defaultProfileImage is returned for users that never set a profile image.
+var defaultProfileImage = []byte("\x89PNG\r\n\x1a\n")
+
+// sanitizeUser returns a copy of u which is safe to be sent to clients.
+func sanitizeUser(u *model.User) *model.User {
+	user := *u
+	user.Password = ""
+	return &user
+}
+
+// sortedUsers returns the users matching filter, sorted by username.
+// It must be called with s.mut held.
+func (s *Server) sortedUsers(filter func(u *model.User) bool) []*model.User {
+	users := make([]*model.User, 0, len(s.users))
+	for _, u := range s.users {
+		if filter == nil || filter(u) {
+			users = append(users, sanitizeUser(u))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users
+}
+
+// userByLoginId returns the user with the given email or username.
+// It must be called with s.mut held.
+func (s *Server) userByLoginId(loginId string) *model.User {
+	loginId = strings.ToLower(loginId)
+	for _, u := range s.users {
+		if u.Email == loginId || u.Username == loginId {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) createUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := model.UserFromJson(r.Body)
+	if user == nil || user.Username == "" || user.Email == "" || user.Password == "" {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing user in request body.")
+		return
+	}
+	user.Username = strings.ToLower(user.Username)
+	user.Email = strings.ToLower(user.Email)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == user.Username {
+			writeError(w, http.StatusBadRequest, "app.user.save.username_exists.app_error", "An account with that username already exists.")
+			return
+		}
+		if u.Email == user.Email {
+			writeError(w, http.StatusBadRequest, "app.user.save.email_exists.app_error", "An account with that email already exists.")
+			return
+		}
+	}
+
+	user.Id = model.NewId()
+	user.CreateAt = model.GetMillis()
+	user.UpdateAt = user.CreateAt
+	user.Roles = model.SYSTEM_USER_ROLE_ID
+	user.Locale = model.DEFAULT_LOCALE
+	user.SetDefaultNotifications()
+
+	s.passwords[user.Id] = user.Password
+	s.users[user.Id] = sanitizeUser(user)
+
+	writeJSON(w, http.StatusCreated, s.users[user.Id])
+}
+
+func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
+	props := model.MapFromJson(r.Body)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	user := s.userByLoginId(props["login_id"])
+	if user == nil || s.passwords[user.Id] != props["password"] {
+		writeError(w, http.StatusUnauthorized, "api.user.login.invalid_credentials_email_username", "Enter a valid email or username and/or password.")
+		return
+	}
+
+	token := model.NewId()
+	s.sessions[token] = user.Id
+
+	w.Header().Set(model.HEADER_TOKEN, token)
+	writeJSON(w, http.StatusOK, sanitizeUser(user))
+}
+
+func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.Lock()
+	delete(s.sessions, tokenFromRequest(r))
+	s.mut.Unlock()
+
+	writeStatusOK(w)
+}
+
+func (s *Server) getMeHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	writeJSON(w, http.StatusOK, sanitizeUser(s.users[userId]))
+}
+
+func (s *Server) getUsersHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	page, perPage := pageParams(r)
+	channelId := r.URL.Query().Get("in_channel")
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	users := s.sortedUsers(func(u *model.User) bool {
+		if channelId == "" {
+			return true
+		}
+		_, ok := s.channelMembers[channelId][u.Id]
+		return ok
+	})
+	start, end := paginate(len(users), page, perPage)
+
+	writeJSON(w, http.StatusOK, users[start:end])
+}
+
+func (s *Server) getUsersByIdsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	ids := model.ArrayFromJson(r.Body)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	users := []*model.User{}
+	for _, id := range ids {
+		if u, ok := s.users[id]; ok {
+			users = append(users, sanitizeUser(u))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) getUsersByUsernamesHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	usernames := map[string]bool{}
+	for _, username := range model.ArrayFromJson(r.Body) {
+		usernames[strings.ToLower(username)] = true
+	}
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	writeJSON(w, http.StatusOK, s.sortedUsers(func(u *model.User) bool {
+		return usernames[u.Username]
+	}))
+}
+
+func (s *Server) searchUsersHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	search := model.UserSearchFromJson(r.Body)
+	if search == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing search in request body.")
+		return
+	}
+	if search.Limit <= 0 {
+		search.Limit = model.USER_SEARCH_DEFAULT_LIMIT
+	}
+	term := strings.ToLower(search.Term)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	users := s.sortedUsers(func(u *model.User) bool {
+		if search.TeamId != "" {
+			if _, ok := s.teamMembers[search.TeamId][u.Id]; !ok {
+				return false
+			}
+		}
+		if search.InChannelId != "" {
+			if _, ok := s.channelMembers[search.InChannelId][u.Id]; !ok {
+				return false
+			}
+		}
+		if search.NotInChannelId != "" {
+			if _, ok := s.channelMembers[search.NotInChannelId][u.Id]; ok {
+				return false
+			}
+		}
+		return strings.Contains(u.Username, term)
+	})
+	if len(users) > search.Limit {
+		users = users[:search.Limit]
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) autocompleteUsersHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	query := r.URL.Query()
+	teamId := query.Get("in_team")
+	channelId := query.Get("in_channel")
+	name := strings.ToLower(query.Get("name"))
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	autocomplete := model.UserAutocomplete{
+		Users:        []*model.User{},
+		OutOfChannel: []*model.User{},
+	}
+	for _, u := range s.sortedUsers(func(u *model.User) bool {
+		return strings.HasPrefix(u.Username, name)
+	}) {
+		if _, ok := s.channelMembers[channelId][u.Id]; ok {
+			autocomplete.Users = append(autocomplete.Users, u)
+		} else if _, ok := s.teamMembers[teamId][u.Id]; ok || teamId == "" {
+			autocomplete.OutOfChannel = append(autocomplete.OutOfChannel, u)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, autocomplete)
+}
+
+func (s *Server) updateUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	user := model.UserFromJson(r.Body)
+	if user == nil || user.Id != mux.Vars(r)["user_id"] {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing user in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	existing, ok := s.users[user.Id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.user.missing_account.const", "Unable to find the user.")
+		return
+	}
+
+	user.Roles = existing.Roles
+	user.CreateAt = existing.CreateAt
+	user.UpdateAt = model.GetMillis()
+	s.users[user.Id] = sanitizeUser(user)
+
+	writeJSON(w, http.StatusOK, s.users[user.Id])
+}
+
+func (s *Server) patchUserHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	patch := model.UserPatchFromJson(r.Body)
+	if patch == nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing patch in request body.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	user, ok := s.users[mux.Vars(r)["user_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.user.missing_account.const", "Unable to find the user.")
+		return
+	}
+
+	if patch.Password != nil {
+		s.passwords[user.Id] = *patch.Password
+	}
+	user.Patch(patch)
+	user.Password = ""
+	user.UpdateAt = model.GetMillis()
+
+	writeJSON(w, http.StatusOK, sanitizeUser(user))
+}
+
+func (s *Server) updateUserRolesHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	props := model.MapFromJson(r.Body)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	user, ok := s.users[mux.Vars(r)["user_id"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.user.missing_account.const", "Unable to find the user.")
+		return
+	}
+	user.Roles = props["roles"]
+
+	writeStatusOK(w)
+}
+
+func (s *Server) getProfileImageHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	id := mux.Vars(r)["user_id"]
+
+	s.mut.RLock()
+	_, ok := s.users[id]
+	data := s.profileImages[id]
+	s.mut.RUnlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "app.user.missing_account.const", "Unable to find the user.")
+		return
+	}
+	if data == nil {
+		data = defaultProfileImage
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(data)
+}
+
+func (s *Server) setProfileImageHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "api.user.upload_profile_user.no_file.app_error", "No file under 'image' in request.")
+		return
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "api.user.upload_profile_user.open.app_error", "Unable to read the image file.")
+		return
+	}
+
+	s.mut.Lock()
+	s.profileImages[mux.Vars(r)["user_id"]] = data
+	s.mut.Unlock()
+
+	writeStatusOK(w)
+}
+
+// userStatus returns the current status of the given user. Users are
+// considered online as long as they hold a WebSocket connection.
+// userStatus returns the status of the given user. Statuses set manually
+// take precedence over the one derived from the WebSocket connection.
+// It must be called with s.mut held.
+func (s *Server) userStatus(userId string) *model.Status {
+	if status, ok := s.statuses[userId]; ok && status.Status != model.STATUS_ONLINE {
+		st := *status
+		return &st
+	}
+
+	status := &model.Status{
+		UserId: userId,
+		Status: model.STATUS_OFFLINE,
+	}
+	if s.hub.isConnected(userId) {
+		status.Status = model.STATUS_ONLINE
+		status.LastActivityAt = model.GetMillis()
+	}
+	return status
+}
+
+func (s *Server) getStatusHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	writeJSON(w, http.StatusOK, s.userStatus(mux.Vars(r)["user_id"]))
+}
+
+func (s *Server) getStatusesByIdsHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	ids := model.ArrayFromJson(r.Body)
+
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	statuses := []*model.Status{}
+	for _, id := range ids {
+		statuses = append(statuses, s.userStatus(id))
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) updateStatusHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	status := model.StatusFromJson(r.Body)
+	if status == nil || status.UserId != userId || mux.Vars(r)["user_id"] != userId {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing status in request body.")
+		return
+	}
+	switch status.Status {
+	case model.STATUS_ONLINE, model.STATUS_AWAY, model.STATUS_DND, model.STATUS_OFFLINE:
+	default:
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing status in request body.")
+		return
+	}
+
+	s.mut.Lock()
+
+	status.Manual = true
+	status.LastActivityAt = model.GetMillis()
+	s.statuses[userId] = status
+	recipients := make([]string, 0, len(s.users))
+	for id := range s.users {
+		recipients = append(recipients, id)
+	}
+
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", userId, nil)
+	ev.Add("status", status.Status)
+	ev.Add("user_id", userId)
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, recipients)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// updateCustomStatusHandler sets or, for DELETE requests, clears the custom
+// status of the user, which is kept as a user prop.
+func (s *Server) updateCustomStatusHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	if mux.Vars(r)["user_id"] != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	var data []byte
+	if r.Method == http.MethodPut {
+		var customStatus user.CustomStatus
+		if err := json.NewDecoder(r.Body).Decode(&customStatus); err != nil || customStatus.Text == "" {
+			writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing custom status in request body.")
+			return
+		}
+		data, _ = json.Marshal(customStatus)
+	}
+
+	s.mut.Lock()
+
+	u := s.users[userId]
+	// Users are shallow copied when sent to clients, so the props are
+	// replaced rather than modified.
+	props := model.StringMap{}
+	for k, v := range u.Props {
+		props[k] = v
+	}
+	if data == nil {
+		delete(props, user.CustomStatusPropKey)
+	} else {
+		props[user.CustomStatusPropKey] = string(data)
+	}
+	u.Props = props
+	u.UpdateAt = model.GetMillis()
+
+	recipients := make([]string, 0, len(s.users))
+	for id := range s.users {
+		recipients = append(recipients, id)
+	}
+	ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_USER_UPDATED, "", "", "", nil)
+	ev.Add("user", sanitizeUser(u))
+
+	s.mut.Unlock()
+
+	s.hub.broadcast(ev, recipients)
+	writeStatusOK(w)
+}
+
+func (s *Server) getPreferencesHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	prefs := model.Preferences{}
+	for _, p := range s.preferences[mux.Vars(r)["user_id"]] {
+		prefs = append(prefs, p)
+	}
+	sort.Slice(prefs, func(i, j int) bool {
+		if prefs[i].Category != prefs[j].Category {
+			return prefs[i].Category < prefs[j].Category
+		}
+		return prefs[i].Name < prefs[j].Name
+	})
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+func (s *Server) updatePreferencesHandler(w http.ResponseWriter, r *http.Request, userId string) {
+	var prefs model.Preferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		writeError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing preferences in request body.")
+		return
+	}
+
+	id := mux.Vars(r)["user_id"]
+	if id != userId {
+		writeError(w, http.StatusForbidden, "api.context.permissions.app_error", "You do not have the appropriate permissions.")
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.preferences[id] == nil {
+		s.preferences[id] = map[string]model.Preference{}
+	}
+	for _, p := range prefs {
+		s.preferences[id][p.Category+":"+p.Name] = p
+	}
+
+	writeStatusOK(w)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+const wsWriteTimeout = 5 * time.Second
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsConn is an authenticated WebSocket connection.
+type wsConn struct {
+	userId   string
+	conn     *websocket.Conn
+	writeMut sync.Mutex
+	sequence int64
+}
+
+func (c *wsConn) send(ev *model.WebSocketEvent) error {
+	c.writeMut.Lock()
+	defer c.writeMut.Unlock()
+	ev = ev.SetSequence(c.sequence)
+	c.sequence++
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(ev.ToJson()))
+}
+
+// hub keeps track of the active WebSocket connections of each user.
+type hub struct {
+	mut   sync.RWMutex
+	conns map[string]map[*wsConn]bool
+}
+
+func newHub() *hub {
+	return &hub{
+		conns: map[string]map[*wsConn]bool{},
+	}
+}
+
+func (h *hub) register(c *wsConn) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	if h.conns[c.userId] == nil {
+		h.conns[c.userId] = map[*wsConn]bool{}
+	}
+	h.conns[c.userId][c] = true
+}
+
+func (h *hub) unregister(c *wsConn) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	delete(h.conns[c.userId], c)
+	if len(h.conns[c.userId]) == 0 {
+		delete(h.conns, c.userId)
+	}
+}
+
+func (h *hub) isConnected(userId string) bool {
+	h.mut.RLock()
+	defer h.mut.RUnlock()
+	return len(h.conns[userId]) > 0
+}
+
+// broadcast sends the event to all the connections of the given users.
+func (h *hub) broadcast(ev *model.WebSocketEvent, userIds []string) {
+	h.mut.RLock()
+	var conns []*wsConn
+	for _, id := range userIds {
+		for c := range h.conns[id] {
+			conns = append(conns, c)
+		}
+	}
+	h.mut.RUnlock()
+
+	ev = ev.PrecomputeJSON()
+	for _, c := range conns {
+		// A failed write means the connection is broken. The reading
+		// goroutine will take care of unregistering it.
+		_ = c.send(ev)
+	}
+}
+
+func (h *hub) closeAll() {
+	h.mut.RLock()
+	defer h.mut.RUnlock()
+	for _, conns := range h.conns {
+		for c := range conns {
+			c.conn.Close()
+		}
+	}
+}
+
+func (s *Server) websocketHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// The first message is expected to be the authentication challenge.
+	var req model.WebSocketRequest
+	if err := conn.ReadJSON(&req); err != nil || req.Action != model.WEBSOCKET_AUTHENTICATION_CHALLENGE {
+		return
+	}
+	token, _ := req.Data["token"].(string)
+	userId, ok := s.sessionUserId(token)
+	if !ok {
+		return
+	}
+
+	c := &wsConn{userId: userId, conn: conn}
+	s.hub.register(c)
+	defer s.hub.unregister(c)
+
+	if err := c.send(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_HELLO, "", "", userId, nil)); err != nil {
+		return
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req model.WebSocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			continue
+		}
+		s.handleWebSocketRequest(userId, &req)
+	}
+}
+
+func (s *Server) handleWebSocketRequest(userId string, req *model.WebSocketRequest) {
+	switch req.Action {
+	case "user_typing":
+		channelId, _ := req.Data["channel_id"].(string)
+		parentId, _ := req.Data["parent_id"].(string)
+
+		s.mut.RLock()
+		if !s.isChannelMember(channelId, userId) {
+			s.mut.RUnlock()
+			return
+		}
+		var recipients []string
+		for _, id := range s.channelMemberIds(channelId) {
+			if id != userId {
+				recipients = append(recipients, id)
+			}
+		}
+		s.mut.RUnlock()
+
+		ev := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", channelId, "", map[string]bool{userId: true})
+		ev.Add("parent_id", parentId)
+		ev.Add("user_id", userId)
+		s.hub.broadcast(ev, recipients)
+	}
+}
@@ -24,9 +24,21 @@ type LoadTester struct {
 	statusChan    chan control.UserStatus
 	status        Status
 	newController NewController
+	rampStopChan  chan struct{}
+
+	// rampPauseState suspends the ramp profile while the load-test is paused.
+	rampPauseState control.PauseState
+	pauseTime      time.Time     // time of the latest call to Pause.
+	pausedDuration time.Duration // time spent paused since the load-test started.
 
 	activeControllers []control.UserController
 	idleControllers   []control.UserController
+	controllersInfo   map[control.UserController]*controllerInfo
+
+	// errorsMut protects errorsByControllerId which gets updated
+	// from the status handling goroutine.
+	errorsMut            sync.Mutex
+	errorsByControllerId map[int]int64
 }
 
 // NewController is a factory function that returns a new
@@ -48,6 +60,9 @@ func (lt *LoadTester) handleStatus(startedChan chan struct{}) {
 		if st.Code == control.USER_STATUS_ERROR {
 			mlog.Error(st.Err.Error(), mlog.Int("controller_id", st.ControllerId), mlog.String("user_id", st.User.Store().Id()))
 			atomic.AddInt64(&lt.status.NumErrors, 1)
+			lt.errorsMut.Lock()
+			lt.errorsByControllerId[st.ControllerId]++
+			lt.errorsMut.Unlock()
 			continue
 		} else if st.Code == control.USER_STATUS_FAILED {
 			mlog.Error(st.Err.Error())
@@ -100,6 +115,7 @@ func (lt *LoadTester) addUser() error {
 		if err != nil {
 			return err
 		}
+		lt.controllersInfo[controller] = &controllerInfo{id: userId}
 	}
 
 	rate, err := pickRate(lt.config.UserControllerConfiguration)
@@ -113,6 +129,7 @@ func (lt *LoadTester) addUser() error {
 	lt.status.NumUsers++
 	lt.status.NumUsersAdded++
 	lt.activeControllers = append(lt.activeControllers, controller)
+	lt.controllersInfo[controller].startTime = time.Now()
 
 	lt.wg.Add(1)
 	go func() {
@@ -122,22 +139,34 @@ func (lt *LoadTester) addUser() error {
 }
 
 // RemoveUsers attempts to decrement by numUsers the number of concurrently active users.
+// Users are picked according to the RemovalStrategy set in the configuration.
 // Returns the number of users successfully removed.
 func (lt *LoadTester) RemoveUsers(numUsers int) (int, error) {
+	return lt.RemoveUsersWithStrategy(numUsers, lt.config.UsersConfiguration.RemovalStrategy)
+}
+
+// RemoveUsersWithStrategy attempts to decrement by numUsers the number of
+// concurrently active users, picking the users to stop according to the given
+// strategy.
+// Returns the number of users successfully removed.
+func (lt *LoadTester) RemoveUsersWithStrategy(numUsers int, strategy RemovalStrategy) (int, error) {
 	lt.mut.Lock()
 	defer lt.mut.Unlock()
 	if numUsers <= 0 {
 		return 0, ErrInvalidNumUsers
 	}
+	if err := strategy.IsValid(); err != nil {
+		return 0, err
+	}
 	if lt.status.State != Running {
 		return 0, ErrNotRunning
 	}
-	return lt.removeUsers(numUsers)
+	return lt.removeUsers(numUsers, strategy)
 }
 
-// removeUsers is an internal API called from Stop and RemoveUsers both.
+// removeUsers is an internal API called from Stop and RemoveUsersWithStrategy both.
 // DO NOT call this by itself, because this method is not protected by a mutex.
-func (lt *LoadTester) removeUsers(numUsers int) (int, error) {
+func (lt *LoadTester) removeUsers(numUsers int, strategy RemovalStrategy) (int, error) {
 	activeUsers := len(lt.activeControllers)
 
 	var err error
@@ -146,20 +175,26 @@ func (lt *LoadTester) removeUsers(numUsers int) (int, error) {
 		err = ErrNoUsersLeft
 	}
 
+	removed, remaining := splitControllers(lt.activeControllers, lt.pickRemovalIndexes(strategy, numUsers))
+
 	var wg sync.WaitGroup
 	wg.Add(numUsers)
-	// TODO: Add a way to make how a user is removed decidable from the upper layer (the user of this API),
-	// for example by passing a typed constant (e.g. random, first, last).
-	for i := 0; i < numUsers; i++ {
-		go func(i int) {
+	for _, controller := range removed {
+		go func(controller control.UserController) {
 			defer wg.Done()
-			lt.activeControllers[activeUsers-1-i].Stop()
-		}(i)
+			controller.Stop()
+		}(controller)
 	}
 	wg.Wait()
 
-	lt.idleControllers = append(lt.idleControllers, lt.activeControllers[activeUsers-numUsers:]...)
-	lt.activeControllers = lt.activeControllers[:activeUsers-numUsers]
+	now := time.Now()
+	for _, controller := range removed {
+		info := lt.controllersInfo[controller]
+		info.runTime = info.totalRunTime(now)
+	}
+
+	lt.idleControllers = append(lt.idleControllers, removed...)
+	lt.activeControllers = remaining
 	lt.status.NumUsers -= int64(numUsers)
 	lt.status.NumUsersRemoved += int64(numUsers)
 
@@ -181,6 +216,10 @@ func (lt *LoadTester) Run() error {
 	lt.status.NumUsersStopped = 0
 	lt.status.NumErrors = 0
 	lt.status.StartTime = time.Now()
+	lt.pausedDuration = 0
+	lt.errorsMut.Lock()
+	lt.errorsByControllerId = make(map[int]int64)
+	lt.errorsMut.Unlock()
 	lt.statusChan = make(chan control.UserStatus, lt.config.UsersConfiguration.MaxActiveUsers)
 	startedChan := make(chan struct{})
 	go lt.handleStatus(startedChan)
@@ -191,21 +230,133 @@ func (lt *LoadTester) Run() error {
 		}
 	}
 	lt.status.State = Running
+
+	lt.status.Ramp = nil
+	if numPhases := len(lt.config.RampProfile.Phases); numPhases > 0 {
+		lt.status.Ramp = &RampStatus{NumPhases: numPhases}
+		lt.rampStopChan = make(chan struct{})
+		go lt.runRampProfile(lt.rampStopChan)
+	}
+
+	return nil
+}
+
+// SetRatesDistribution replaces the configured RatesDistribution and applies
+// a newly picked rate to each of the active users. Users added afterwards will
+// also have their rate picked from the given distribution.
+// It returns an error if the load-test is not running or paused.
+func (lt *LoadTester) SetRatesDistribution(dist []RatesDistribution) error {
+	ucc := UserControllerConfiguration{
+		Type:              lt.config.UserControllerConfiguration.Type,
+		RatesDistribution: dist,
+	}
+	if err := defaults.Validate(&ucc); err != nil {
+		return fmt.Errorf("loadtest: invalid rates distribution: %w", err)
+	}
+
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+
+	if lt.status.State != Running && lt.status.State != Paused {
+		return ErrNotRunning
+	}
+
+	for _, controller := range lt.activeControllers {
+		rate, err := pickRate(ucc)
+		if err != nil {
+			return fmt.Errorf("loadtest: failed to pick rate: %w", err)
+		}
+		if err := controller.SetRate(rate); err != nil {
+			return fmt.Errorf("loadtest: failed to set controller rate %w", err)
+		}
+	}
+	lt.config.UserControllerConfiguration.RatesDistribution = dist
+
+	return nil
+}
+
+// Pause suspends the execution of user actions for all the active users.
+// Sessions and WebSocket connections are kept alive so that the load-test can
+// be resumed without going through the login process again.
+// It returns an error if the load-test is not running.
+func (lt *LoadTester) Pause() error {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+
+	if lt.status.State != Running {
+		return ErrNotRunning
+	}
+
+	for _, controller := range lt.activeControllers {
+		controller.Pause()
+	}
+	lt.rampPauseState.Pause()
+	lt.pauseTime = time.Now()
+	lt.status.State = Paused
+
+	return nil
+}
+
+// Resume resumes the execution of user actions after a call to Pause.
+// It returns an error if the load-test is not paused.
+func (lt *LoadTester) Resume() error {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+
+	if lt.status.State != Paused {
+		return ErrNotPaused
+	}
+
+	lt.resumeControllers()
+	lt.status.State = Running
+
 	return nil
 }
 
+// activeTime returns the time the current load-test has been running for,
+// excluding the time spent paused.
+func (lt *LoadTester) activeTime() time.Duration {
+	lt.mut.RLock()
+	defer lt.mut.RUnlock()
+
+	paused := lt.pausedDuration
+	if lt.status.State == Paused {
+		paused += time.Since(lt.pauseTime)
+	}
+	return time.Since(lt.status.StartTime) - paused
+}
+
+// resumeControllers is an internal API called from Resume and Stop both.
+// DO NOT call this by itself, because this method is not protected by a mutex.
+func (lt *LoadTester) resumeControllers() {
+	for _, controller := range lt.activeControllers {
+		controller.Resume()
+	}
+	lt.rampPauseState.Resume()
+	lt.pausedDuration += time.Since(lt.pauseTime)
+}
+
 // Stop terminates the current load-test.
 // It returns an error if it is called when the load test has not started.
 func (lt *LoadTester) Stop() error {
 	lt.mut.Lock()
 	defer lt.mut.Unlock()
 
-	if lt.status.State != Running {
+	if lt.status.State != Running && lt.status.State != Paused {
 		return ErrNotRunning
 	}
+	if lt.status.State == Paused {
+		// Controllers are reused, so they must not be left paused.
+		lt.resumeControllers()
+	}
 	lt.status.State = Stopping
 
-	if _, err := lt.removeUsers(len(lt.activeControllers)); err != nil {
+	if lt.rampStopChan != nil {
+		close(lt.rampStopChan)
+		lt.rampStopChan = nil
+	}
+
+	if _, err := lt.removeUsers(len(lt.activeControllers), RemovalStrategyLIFO); err != nil {
 		mlog.Error(err.Error())
 	}
 
@@ -225,6 +376,12 @@ func (lt *LoadTester) Status() *Status {
 	numErrors := atomic.LoadInt64(&lt.status.NumErrors)
 	numStopped := atomic.LoadInt64(&lt.status.NumUsersStopped)
 
+	var ramp *RampStatus
+	if lt.status.Ramp != nil {
+		rampStatus := *lt.status.Ramp
+		ramp = &rampStatus
+	}
+
 	return &Status{
 		State:           lt.status.State,
 		NumUsers:        lt.status.NumUsers,
@@ -233,6 +390,7 @@ func (lt *LoadTester) Status() *Status {
 		NumUsersStopped: numStopped,
 		NumErrors:       numErrors,
 		StartTime:       lt.status.StartTime,
+		Ramp:            ramp,
 	}
 }
 
@@ -249,11 +407,13 @@ func New(config *Config, nc NewController) (*LoadTester, error) {
 	}
 
 	return &LoadTester{
-		config:            config,
-		statusChan:        make(chan control.UserStatus, config.UsersConfiguration.MaxActiveUsers),
-		newController:     nc,
-		status:            Status{},
-		activeControllers: make([]control.UserController, 0),
-		idleControllers:   make([]control.UserController, 0),
+		config:               config,
+		statusChan:           make(chan control.UserStatus, config.UsersConfiguration.MaxActiveUsers),
+		newController:        nc,
+		status:               Status{},
+		activeControllers:    make([]control.UserController, 0),
+		idleControllers:      make([]control.UserController, 0),
+		controllersInfo:      make(map[control.UserController]*controllerInfo),
+		errorsByControllerId: make(map[int]int64),
 	}, nil
 }
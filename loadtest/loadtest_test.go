@@ -4,6 +4,8 @@
 package loadtest
 
 import (
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,6 +33,10 @@ var ltConfig = Config{
 		MaxActiveUsers:     8,
 		InitialActiveUsers: 0,
 		AvgSessionsPerUser: 1,
+		RemovalStrategy:    RemovalStrategyLIFO,
+	},
+	RampProfile: RampProfile{
+		UpdateIntervalMs: 1000,
 	},
 	InstanceConfiguration: InstanceConfiguration{
 		NumTeams:               1,
@@ -108,9 +114,16 @@ func TestAddUsers(t *testing.T) {
 	require.Empty(t, lt.idleControllers)
 }
 
+// startStatusHandler starts handling the statuses sent by the controllers of
+// lt, as Run does.
+func startStatusHandler(lt *LoadTester) {
+	startedChan := make(chan struct{})
+	go lt.handleStatus(startedChan)
+	<-startedChan
+}
+
 func TestRemoveUsers(t *testing.T) {
 	lt, err := New(&ltConfig, newController)
-	defer close(lt.statusChan)
 	require.Nil(t, err)
 
 	n, err := lt.RemoveUsers(0)
@@ -122,11 +135,11 @@ func TestRemoveUsers(t *testing.T) {
 	require.Zero(t, n)
 
 	lt.status.State = Running
-	// This is needed to avoid the controllers deadlocking
-	// on sending data into the status channel.
-	go func() {
-		for range lt.statusChan {
-		}
+	// Statuses are handled as in a load-test so that controllers don't deadlock
+	// on sending data into the status channel, and get stopped by Stop.
+	startStatusHandler(lt)
+	defer func() {
+		require.NoError(t, lt.Stop())
 	}()
 
 	n, err = lt.RemoveUsers(1)
@@ -160,6 +173,131 @@ func TestRemoveUsers(t *testing.T) {
 	require.Len(t, lt.idleControllers, 2)
 }
 
+func TestRemoveUsersWithStrategy(t *testing.T) {
+	setup := func(t *testing.T) *LoadTester {
+		t.Helper()
+		lt, err := New(&ltConfig, newController)
+		require.Nil(t, err)
+		lt.status.State = Running
+		startStatusHandler(lt)
+		n, err := lt.AddUsers(4)
+		require.NoError(t, err)
+		require.Equal(t, 4, n)
+		return lt
+	}
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		lt := setup(t)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+
+		n, err := lt.RemoveUsersWithStrategy(1, "invalid")
+		require.Equal(t, ErrInvalidRemovalStrategy, err)
+		require.Zero(t, n)
+		require.Len(t, lt.activeControllers, 4)
+	})
+
+	t.Run("lifo", func(t *testing.T) {
+		lt := setup(t)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+		controllers := append([]control.UserController{}, lt.activeControllers...)
+
+		n, err := lt.RemoveUsersWithStrategy(1, RemovalStrategyLIFO)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, controllers[:3], lt.activeControllers)
+		require.Equal(t, controllers[3:], lt.idleControllers)
+	})
+
+	t.Run("fifo", func(t *testing.T) {
+		lt := setup(t)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+		controllers := append([]control.UserController{}, lt.activeControllers...)
+
+		n, err := lt.RemoveUsersWithStrategy(2, RemovalStrategyFIFO)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, controllers[2:], lt.activeControllers)
+		require.Equal(t, controllers[:2], lt.idleControllers)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		lt := setup(t)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+
+		n, err := lt.RemoveUsersWithStrategy(2, RemovalStrategyRandom)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Len(t, lt.activeControllers, 2)
+		require.Len(t, lt.idleControllers, 2)
+		for _, c := range lt.idleControllers {
+			require.NotContains(t, lt.activeControllers, c)
+		}
+	})
+
+	t.Run("longest running", func(t *testing.T) {
+		lt := setup(t)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+		controllers := append([]control.UserController{}, lt.activeControllers...)
+		lt.controllersInfo[controllers[2]].runTime = time.Hour
+
+		n, err := lt.RemoveUsersWithStrategy(1, RemovalStrategyLongestRunning)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []control.UserController{controllers[2]}, lt.idleControllers)
+	})
+
+	t.Run("most errors", func(t *testing.T) {
+		lt := setup(t)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+		controllers := append([]control.UserController{}, lt.activeControllers...)
+		lt.errorsMut.Lock()
+		lt.errorsByControllerId[lt.controllersInfo[controllers[1]].id] = 1000000
+		lt.errorsMut.Unlock()
+
+		n, err := lt.RemoveUsersWithStrategy(1, RemovalStrategyMostErrors)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []control.UserController{controllers[1]}, lt.idleControllers)
+	})
+}
+
+func TestHandleStatusErrors(t *testing.T) {
+	lt, err := New(&ltConfig, newController)
+	require.Nil(t, err)
+	startStatusHandler(lt)
+	defer close(lt.statusChan)
+
+	store, err := memstore.New(nil)
+	require.NoError(t, err)
+	ue := userentity.New(userentity.Setup{Store: store}, userentity.Config{})
+
+	lt.statusChan <- control.UserStatus{ControllerId: 1, User: ue, Code: control.USER_STATUS_ERROR, Err: errors.New("error")}
+	lt.statusChan <- control.UserStatus{ControllerId: 1, User: ue, Code: control.USER_STATUS_ERROR, Err: errors.New("error")}
+	lt.statusChan <- control.UserStatus{ControllerId: 2, User: ue, Code: control.USER_STATUS_INFO, Info: "info"}
+
+	require.Eventually(t, func() bool {
+		lt.errorsMut.Lock()
+		defer lt.errorsMut.Unlock()
+		return lt.errorsByControllerId[1] == 2
+	}, time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 2, atomic.LoadInt64(&lt.status.NumErrors))
+	lt.errorsMut.Lock()
+	defer lt.errorsMut.Unlock()
+	require.Zero(t, lt.errorsByControllerId[2])
+}
+
 func TestRun(t *testing.T) {
 	lt, err := New(&ltConfig, newController)
 	require.Nil(t, err)
@@ -212,6 +350,100 @@ func TestStop(t *testing.T) {
 	require.Empty(t, lt.activeControllers)
 }
 
+func TestPauseResume(t *testing.T) {
+	lt, err := New(&ltConfig, newController)
+	require.Nil(t, err)
+
+	err = lt.Pause()
+	require.Equal(t, ErrNotRunning, err)
+	err = lt.Resume()
+	require.Equal(t, ErrNotPaused, err)
+
+	err = lt.Run()
+	require.NoError(t, err)
+
+	n, err := lt.AddUsers(4)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	err = lt.Pause()
+	require.NoError(t, err)
+	require.Equal(t, Paused, lt.Status().State)
+	require.Len(t, lt.activeControllers, 4)
+
+	err = lt.Pause()
+	require.Equal(t, ErrNotRunning, err)
+	_, err = lt.AddUsers(1)
+	require.Equal(t, ErrNotRunning, err)
+
+	err = lt.Resume()
+	require.NoError(t, err)
+	require.Equal(t, Running, lt.Status().State)
+
+	t.Run("StopWhilePaused", func(t *testing.T) {
+		err := lt.Pause()
+		require.NoError(t, err)
+
+		err = lt.Stop()
+		require.NoError(t, err)
+		require.Equal(t, Stopped, lt.Status().State)
+		require.Empty(t, lt.activeControllers)
+	})
+}
+
+type rateController struct {
+	control.UserController
+	rate float64
+}
+
+func (c *rateController) SetRate(rate float64) error {
+	c.rate = rate
+	return c.UserController.SetRate(rate)
+}
+
+func TestSetRatesDistribution(t *testing.T) {
+	config := ltConfig
+	config.UserControllerConfiguration.RatesDistribution = []RatesDistribution{{Rate: 1.0, Percentage: 1.0}}
+	lt, err := New(&config, func(id int, status chan<- control.UserStatus) (control.UserController, error) {
+		controller, err := newController(id, status)
+		if err != nil {
+			return nil, err
+		}
+		return &rateController{UserController: controller}, nil
+	})
+	require.Nil(t, err)
+
+	dist := []RatesDistribution{{Rate: 2.5, Percentage: 1.0}}
+	err = lt.SetRatesDistribution(dist)
+	require.Equal(t, ErrNotRunning, err)
+
+	err = lt.Run()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, lt.Stop())
+	}()
+
+	_, err = lt.AddUsers(4)
+	require.NoError(t, err)
+	for _, c := range lt.activeControllers {
+		require.Equal(t, 1.0, c.(*rateController).rate)
+	}
+
+	err = lt.SetRatesDistribution([]RatesDistribution{{Rate: 2.5, Percentage: 0.5}})
+	require.Error(t, err)
+
+	err = lt.SetRatesDistribution(dist)
+	require.NoError(t, err)
+	for _, c := range lt.activeControllers {
+		require.Equal(t, 2.5, c.(*rateController).rate)
+	}
+	require.Equal(t, dist, config.UserControllerConfiguration.RatesDistribution)
+
+	_, err = lt.AddUsers(1)
+	require.NoError(t, err)
+	require.Equal(t, 2.5, lt.activeControllers[4].(*rateController).rate)
+}
+
 func TestStatus(t *testing.T) {
 	lt, err := New(&ltConfig, newController)
 	require.NotNil(t, lt)
@@ -260,3 +492,117 @@ func TestStatus(t *testing.T) {
 	assert.True(t, startTime.Before(st.StartTime))
 	assert.Equal(t, Running, st.State)
 }
+
+func TestRampProfile(t *testing.T) {
+	config := ltConfig
+	config.RampProfile = RampProfile{
+		Phases: []RampPhase{
+			{TargetUsers: 4, RampDurationSec: 1, HoldDurationSec: 0},
+			{TargetUsers: 6, RampDurationSec: 0, HoldDurationSec: 1},
+			{TargetUsers: 2, RampDurationSec: 0, HoldDurationSec: 0},
+		},
+		UpdateIntervalMs: 100,
+	}
+
+	t.Run("invalid target", func(t *testing.T) {
+		invalidConfig := config
+		invalidConfig.RampProfile.Phases = []RampPhase{
+			{TargetUsers: config.UsersConfiguration.MaxActiveUsers + 1},
+		}
+		lt, err := New(&invalidConfig, newController)
+		require.Error(t, err)
+		require.Nil(t, lt)
+	})
+
+	t.Run("run to completion", func(t *testing.T) {
+		lt, err := New(&config, newController)
+		require.NoError(t, err)
+
+		require.Nil(t, lt.Status().Ramp)
+
+		err = lt.Run()
+		require.NoError(t, err)
+
+		st := lt.Status()
+		require.NotNil(t, st.Ramp)
+		require.Equal(t, 3, st.Ramp.NumPhases)
+
+		require.Eventually(t, func() bool {
+			return lt.Status().Ramp.Stage == RampStageDone
+		}, 5*time.Second, 100*time.Millisecond)
+
+		st = lt.Status()
+		require.Equal(t, 2, st.Ramp.Phase)
+		require.Equal(t, int64(2), st.NumUsers)
+		require.Equal(t, int64(6), st.NumUsersAdded)
+		require.Equal(t, int64(4), st.NumUsersRemoved)
+
+		err = lt.Stop()
+		require.NoError(t, err)
+	})
+
+	t.Run("pause while holding", func(t *testing.T) {
+		pauseConfig := config
+		pauseConfig.RampProfile.Phases = []RampPhase{
+			{TargetUsers: 2, RampDurationSec: 0, HoldDurationSec: 1},
+			{TargetUsers: 1, RampDurationSec: 0, HoldDurationSec: 0},
+		}
+		lt, err := New(&pauseConfig, newController)
+		require.NoError(t, err)
+
+		err = lt.Run()
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, lt.Stop())
+		}()
+
+		require.Eventually(t, func() bool {
+			return lt.Status().Ramp.Stage == RampStageHolding
+		}, 5*time.Second, 10*time.Millisecond)
+
+		err = lt.Pause()
+		require.NoError(t, err)
+
+		// The hold duration only elapses while the load-test is running.
+		time.Sleep(1500 * time.Millisecond)
+		st := lt.Status()
+		require.Equal(t, 0, st.Ramp.Phase)
+		require.Equal(t, RampStageHolding, st.Ramp.Stage)
+		require.Equal(t, int64(2), st.NumUsers)
+
+		err = lt.Resume()
+		require.NoError(t, err)
+		resumeTime := time.Now()
+
+		require.Eventually(t, func() bool {
+			return lt.Status().Ramp.Stage == RampStageDone
+		}, 5*time.Second, 10*time.Millisecond)
+		// Most of the hold duration was left when the load-test got paused.
+		require.Greater(t, int64(time.Since(resumeTime)), int64(500*time.Millisecond))
+		require.Equal(t, int64(1), lt.Status().NumUsers)
+	})
+
+	t.Run("stop while ramping", func(t *testing.T) {
+		stopConfig := config
+		stopConfig.RampProfile.Phases = []RampPhase{
+			{TargetUsers: 4, RampDurationSec: 60, HoldDurationSec: 60},
+		}
+		lt, err := New(&stopConfig, newController)
+		require.NoError(t, err)
+
+		err = lt.Run()
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return lt.Status().Ramp.Stage == RampStageRamping
+		}, 5*time.Second, 100*time.Millisecond)
+
+		err = lt.Stop()
+		require.NoError(t, err)
+
+		st := lt.Status()
+		require.Equal(t, Stopped, st.State)
+		require.Equal(t, RampStageRamping, st.Ramp.Stage)
+		require.Empty(t, lt.activeControllers)
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package loadtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+// runRampProfile executes the phases of the configured RampProfile one after
+// the other. It returns early as soon as stopChan gets closed.
+func (lt *LoadTester) runRampProfile(stopChan <-chan struct{}) {
+	phases := lt.config.RampProfile.Phases
+	for i, phase := range phases {
+		if !lt.setRampStage(stopChan, i, RampStageRamping) {
+			return
+		}
+		mlog.Info("loadtest: ramp phase started", mlog.Int("phase", i), mlog.Int("target_users", phase.TargetUsers))
+
+		if !lt.rampUsers(stopChan, phase) {
+			return
+		}
+
+		if !lt.setRampStage(stopChan, i, RampStageHolding) {
+			return
+		}
+		mlog.Info("loadtest: ramp phase holding", mlog.Int("phase", i), mlog.Int("hold_duration_sec", phase.HoldDurationSec))
+
+		if !lt.holdUsers(stopChan, phase) {
+			return
+		}
+	}
+
+	if lt.setRampStage(stopChan, len(phases)-1, RampStageDone) {
+		mlog.Info("loadtest: ramp profile completed")
+	}
+}
+
+// rampUsers gradually moves the number of active users towards the phase's
+// target over the configured ramp duration. Time spent paused doesn't count
+// towards the ramp duration. It returns false if the ramp was interrupted by
+// stopChan getting closed.
+func (lt *LoadTester) rampUsers(stopChan <-chan struct{}, phase RampPhase) bool {
+	rampDuration := time.Duration(phase.RampDurationSec) * time.Second
+	interval := time.Duration(lt.config.RampProfile.UpdateIntervalMs) * time.Millisecond
+	start := lt.activeTime()
+	startUsers := int(lt.Status().NumUsers)
+
+	for {
+		elapsed := lt.activeTime() - start
+		target := phase.TargetUsers
+		if elapsed < rampDuration {
+			progress := elapsed.Seconds() / rampDuration.Seconds()
+			target = startUsers + int(math.Round(float64(phase.TargetUsers-startUsers)*progress))
+		}
+
+		if !lt.adjustUsers(stopChan, target) {
+			return false
+		}
+
+		if elapsed >= rampDuration {
+			return true
+		}
+
+		select {
+		case <-stopChan:
+			return false
+		case <-time.After(interval):
+		}
+
+		if !lt.rampPauseState.Wait(stopChan) {
+			return false
+		}
+	}
+}
+
+// holdUsers waits for the phase's hold duration to elapse. Time spent paused
+// doesn't count towards it. It returns false if the wait was interrupted by
+// stopChan getting closed.
+func (lt *LoadTester) holdUsers(stopChan <-chan struct{}, phase RampPhase) bool {
+	holdDuration := time.Duration(phase.HoldDurationSec) * time.Second
+	start := lt.activeTime()
+
+	for {
+		remaining := holdDuration - (lt.activeTime() - start)
+		if remaining <= 0 {
+			return true
+		}
+
+		select {
+		case <-stopChan:
+			return false
+		case <-time.After(remaining):
+		}
+
+		if !lt.rampPauseState.Wait(stopChan) {
+			return false
+		}
+	}
+}
+
+// adjustUsers adds or removes users so that the number of active users
+// matches target. No changes are made while the load-test is paused.
+// It returns false if stopChan was closed.
+func (lt *LoadTester) adjustUsers(stopChan <-chan struct{}, target int) bool {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+
+	// Checking while holding the lock guarantees we never operate
+	// on a load-test that has already been stopped.
+	select {
+	case <-stopChan:
+		return false
+	default:
+	}
+
+	if lt.status.State == Paused {
+		return true
+	}
+
+	diff := target - len(lt.activeControllers)
+	if diff > 0 {
+		for i := 0; i < diff; i++ {
+			if err := lt.addUser(); err != nil {
+				mlog.Warn("loadtest: failed to add user during ramp", mlog.Err(err))
+				break
+			}
+		}
+	} else if diff < 0 {
+		if _, err := lt.removeUsers(-diff, lt.config.UsersConfiguration.RemovalStrategy); err != nil {
+			mlog.Warn("loadtest: failed to remove users during ramp", mlog.Err(err))
+		}
+	}
+
+	return true
+}
+
+// setRampStage updates the ramp status. It returns false if stopChan was
+// closed.
+func (lt *LoadTester) setRampStage(stopChan <-chan struct{}, phase int, stage RampStage) bool {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+
+	select {
+	case <-stopChan:
+		return false
+	default:
+	}
+
+	if lt.status.Ramp.Phase != phase || lt.status.Ramp.Stage != stage {
+		lt.status.Ramp.StageStartTime = time.Now()
+	}
+	lt.status.Ramp.Phase = phase
+	lt.status.Ramp.Stage = stage
+
+	return true
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package loadtest
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/control"
+)
+
+// RemovalStrategy determines which of the active users are stopped first
+// when the number of concurrently active users is decremented.
+type RemovalStrategy string
+
+// Available removal strategies.
+const (
+	// RemovalStrategyLIFO removes the most recently added users first.
+	RemovalStrategyLIFO RemovalStrategy = "lifo"
+	// RemovalStrategyFIFO removes the least recently added users first.
+	RemovalStrategyFIFO RemovalStrategy = "fifo"
+	// RemovalStrategyRandom removes users picked at random.
+	RemovalStrategyRandom RemovalStrategy = "random"
+	// RemovalStrategyLongestRunning removes the users that have been running
+	// for the longest accumulated amount of time first.
+	RemovalStrategyLongestRunning RemovalStrategy = "longest_running"
+	// RemovalStrategyMostErrors removes the users that have reported the
+	// highest number of errors first.
+	RemovalStrategyMostErrors RemovalStrategy = "most_errors"
+)
+
+// IsValid reports whether a given RemovalStrategy is valid or not.
+// Returns an error if the validation fails.
+func (s RemovalStrategy) IsValid() error {
+	switch s {
+	case RemovalStrategyLIFO, RemovalStrategyFIFO, RemovalStrategyRandom,
+		RemovalStrategyLongestRunning, RemovalStrategyMostErrors:
+		return nil
+	}
+	return ErrInvalidRemovalStrategy
+}
+
+// controllerInfo holds the bookkeeping data the LoadTester keeps about
+// a UserController in order to apply a RemovalStrategy.
+type controllerInfo struct {
+	id        int           // id the controller was created with.
+	startTime time.Time     // time of the latest activation.
+	runTime   time.Duration // accumulated running time of previous activations.
+}
+
+// totalRunTime returns the accumulated running time of the controller,
+// including the current activation.
+func (ci *controllerInfo) totalRunTime(now time.Time) time.Duration {
+	return ci.runTime + now.Sub(ci.startTime)
+}
+
+// pickRemovalIndexes returns the indexes in lt.activeControllers of the
+// numUsers controllers that should be removed according to strategy.
+// DO NOT call this by itself, because this method is not protected by a mutex.
+func (lt *LoadTester) pickRemovalIndexes(strategy RemovalStrategy, numUsers int) []int {
+	activeUsers := len(lt.activeControllers)
+	indexes := make([]int, activeUsers)
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	switch strategy {
+	case RemovalStrategyFIFO:
+	case RemovalStrategyRandom:
+		rand.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+	case RemovalStrategyLongestRunning:
+		now := time.Now()
+		runTimes := make([]time.Duration, activeUsers)
+		for i, c := range lt.activeControllers {
+			runTimes[i] = lt.controllersInfo[c].totalRunTime(now)
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return runTimes[indexes[i]] > runTimes[indexes[j]]
+		})
+	case RemovalStrategyMostErrors:
+		// Sessions of the same user share the controller id, so their
+		// errors are counted together.
+		lt.errorsMut.Lock()
+		numErrors := make([]int64, activeUsers)
+		for i, c := range lt.activeControllers {
+			numErrors[i] = lt.errorsByControllerId[lt.controllersInfo[c].id]
+		}
+		lt.errorsMut.Unlock()
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return numErrors[indexes[i]] > numErrors[indexes[j]]
+		})
+	default:
+		// RemovalStrategyLIFO
+		for i, j := 0, len(indexes)-1; i < j; i, j = i+1, j-1 {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		}
+	}
+
+	return indexes[:numUsers]
+}
+
+// splitControllers partitions controllers into the ones whose index is
+// included in indexes and the remaining ones, preserving their order.
+func splitControllers(controllers []control.UserController, indexes []int) ([]control.UserController, []control.UserController) {
+	selected := make(map[int]bool, len(indexes))
+	for _, idx := range indexes {
+		selected[idx] = true
+	}
+
+	removed := make([]control.UserController, 0, len(indexes))
+	remaining := make([]control.UserController, 0, len(controllers)-len(indexes))
+	for i, c := range controllers {
+		if selected[i] {
+			removed = append(removed, c)
+		} else {
+			remaining = append(remaining, c)
+		}
+	}
+
+	return removed, remaining
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphFormat is the image format of the generated graphs.
+type GraphFormat string
+
+// Available graph formats.
+const (
+	GraphFormatPNG GraphFormat = "png"
+	GraphFormatSVG GraphFormat = "svg"
+)
+
+// IsValid reports whether a given GraphFormat is valid or not.
+// Returns an error if the validation fails.
+func (f GraphFormat) IsValid() error {
+	switch f {
+	case GraphFormatPNG, GraphFormatSVG:
+		return nil
+	default:
+		return fmt.Errorf("invalid graph format %q", f)
+	}
+}
+
+const (
+	chartWidth        = 800
+	chartHeight       = 480
+	chartMarginLeft   = 80
+	chartMarginRight  = 30
+	chartMarginTop    = 50
+	chartMarginBottom = 80
+	chartNumTicks     = 6
+	chartTickSize     = 5
+	chartLegendSwatch = 12
+)
+
+var (
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorForeground = color.RGBA{0x24, 0x29, 0x2e, 0xff}
+	colorAxis       = color.RGBA{0x95, 0x9d, 0xa5, 0xff}
+	colorGrid       = color.RGBA{0xea, 0xec, 0xef, 0xff}
+	seriesColors    = []color.RGBA{
+		{0x03, 0x66, 0xd6, 0xff},
+		{0xd7, 0x3a, 0x49, 0xff},
+		{0x28, 0xa7, 0x45, 0xff},
+		{0x6f, 0x42, 0xc1, 0xff},
+		{0xf6, 0x6a, 0x0a, 0xff},
+		{0x6a, 0x73, 0x7d, 0xff},
+	}
+)
+
+// textAnchor is the horizontal alignment of a text relative to its
+// position.
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is a drawing surface a chart can be rendered on. Text is vertically
+// centered on its position.
+type canvas interface {
+	line(x1, y1, x2, y2 float64, c color.RGBA)
+	polyline(xs, ys []float64, c color.RGBA)
+	rect(x, y, w, h float64, c color.RGBA)
+	text(x, y float64, s string, large bool, anchor textAnchor, c color.RGBA)
+	// textWidth returns the approximate width of the given text.
+	textWidth(s string, large bool) float64
+}
+
+// chartSeries is a single line of a chart.
+type chartSeries struct {
+	label string
+	x     []float64 // Seconds since the first value.
+	y     []float64
+}
+
+// chart is a line chart plotting one or more series over time.
+type chart struct {
+	title  string
+	series []chartSeries
+}
+
+// newChart creates a chart from the values of a metric of multiple reports.
+// Missing values are skipped.
+func newChart(title string, graphs []labelValues) chart {
+	ch := chart{title: title}
+	for _, g := range graphs {
+		s := chartSeries{label: g.label}
+		for _, v := range g.values {
+			if math.IsNaN(float64(v.Value)) || math.IsInf(float64(v.Value), 0) {
+				continue
+			}
+			s.x = append(s.x, float64(v.Timestamp-g.values[0].Timestamp)/1000)
+			s.y = append(s.y, float64(v.Value))
+		}
+		ch.series = append(ch.series, s)
+	}
+	return ch
+}
+
+// render writes the chart to w in the given format.
+func (ch chart) render(w io.Writer, format GraphFormat) error {
+	switch format {
+	case GraphFormatPNG:
+		c := newPNGCanvas(chartWidth, chartHeight, colorBackground)
+		ch.draw(c)
+		return c.encode(w)
+	case GraphFormatSVG:
+		c := newSVGCanvas(chartWidth, chartHeight, colorBackground)
+		ch.draw(c)
+		return c.encode(w)
+	default:
+		return fmt.Errorf("invalid graph format %q", format)
+	}
+}
+
+// bounds returns the ranges of the values of all series.
+func (ch chart) bounds() (minX, maxX, minY, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, s := range ch.series {
+		for i := range s.x {
+			minX = math.Min(minX, s.x[i])
+			maxX = math.Max(maxX, s.x[i])
+			minY = math.Min(minY, s.y[i])
+			maxY = math.Max(maxY, s.y[i])
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return 0, 1, 0, 1
+	}
+	if maxX == minX {
+		maxX = minX + 1
+	}
+	minY = math.Min(0, minY)
+	if maxY == minY {
+		maxY = minY + 1
+	}
+	return minX, maxX, minY, maxY
+}
+
+func (ch chart) draw(c canvas) {
+	plotW := float64(chartWidth - chartMarginLeft - chartMarginRight)
+	plotH := float64(chartHeight - chartMarginTop - chartMarginBottom)
+	left := float64(chartMarginLeft)
+	top := float64(chartMarginTop)
+	bottom := top + plotH
+
+	minX, maxX, minY, maxY := ch.bounds()
+	xTicks := timeTicks(minX, maxX, chartNumTicks)
+	yTicks := niceTicks(minY, maxY, chartNumTicks)
+	// Extend the ranges to include the outermost ticks.
+	minX, maxX = math.Min(minX, xTicks[0]), math.Max(maxX, xTicks[len(xTicks)-1])
+	minY, maxY = math.Min(minY, yTicks[0]), math.Max(maxY, yTicks[len(yTicks)-1])
+
+	sx := func(x float64) float64 { return left + (x-minX)/(maxX-minX)*plotW }
+	sy := func(y float64) float64 { return bottom - (y-minY)/(maxY-minY)*plotH }
+
+	c.text(chartWidth/2, top/2, ch.title, true, anchorMiddle, colorForeground)
+
+	for _, y := range yTicks {
+		c.line(left, sy(y), left+plotW, sy(y), colorGrid)
+		c.line(left-chartTickSize, sy(y), left, sy(y), colorAxis)
+		c.text(left-chartTickSize-4, sy(y), formatValue(y), false, anchorEnd, colorForeground)
+	}
+	for _, x := range xTicks {
+		c.line(sx(x), top, sx(x), bottom, colorGrid)
+		c.line(sx(x), bottom, sx(x), bottom+chartTickSize, colorAxis)
+		c.text(sx(x), bottom+chartTickSize+10, formatElapsed(x), false, anchorMiddle, colorForeground)
+	}
+	c.line(left, top, left, bottom, colorAxis)
+	c.line(left, bottom, left+plotW, bottom, colorAxis)
+	c.text(left+plotW/2, bottom+chartTickSize+30, "time (normalized)", false, anchorMiddle, colorForeground)
+
+	legendX := left
+	legendY := float64(chartHeight) - 20
+	for i, s := range ch.series {
+		col := seriesColors[i%len(seriesColors)]
+		xs := make([]float64, len(s.x))
+		ys := make([]float64, len(s.y))
+		for j := range s.x {
+			xs[j] = sx(s.x[j])
+			ys[j] = sy(s.y[j])
+		}
+		c.polyline(xs, ys, col)
+
+		c.rect(legendX, legendY-chartLegendSwatch/2, chartLegendSwatch, chartLegendSwatch, col)
+		c.text(legendX+chartLegendSwatch+5, legendY, s.label, false, anchorStart, colorForeground)
+		legendX += chartLegendSwatch + 5 + c.textWidth(s.label, false) + 20
+	}
+}
+
+// niceTicks returns about n evenly spaced round values covering the given
+// range.
+func niceTicks(min, max float64, n int) []float64 {
+	step := niceNumber((max - min) / float64(n-1))
+	start := math.Floor(min/step) * step
+	end := math.Ceil(max/step) * step
+	var ticks []float64
+	for i := 0; start+float64(i)*step <= end+step/2; i++ {
+		ticks = append(ticks, start+float64(i)*step)
+	}
+	return ticks
+}
+
+// timeTicks is like niceTicks for a range of seconds, using steps which are
+// round durations.
+func timeTicks(min, max float64, n int) []float64 {
+	steps := []float64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 900, 1800, 3600}
+	target := (max - min) / float64(n-1)
+	if target > steps[len(steps)-1] {
+		return niceTicks(min, max, n)
+	}
+	step := steps[0]
+	for _, s := range steps {
+		step = s
+		if s >= target {
+			break
+		}
+	}
+	var ticks []float64
+	for v := math.Floor(min/step) * step; v <= math.Ceil(max/step)*step; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// niceNumber rounds the given value to 1, 2 or 5 times a power of ten.
+func niceNumber(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Floor(math.Log10(v))
+	frac := v / math.Pow(10, exp)
+	var nice float64
+	switch {
+	case frac < 1.5:
+		nice = 1
+	case frac < 3:
+		nice = 2
+	case frac < 7:
+		nice = 5
+	default:
+		nice = 10
+	}
+	return nice * math.Pow(10, exp)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// formatElapsed formats a number of seconds as a short duration.
+func formatElapsed(sec float64) string {
+	d := time.Duration(sec * float64(time.Second)).Round(time.Second)
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// pngCanvas is a canvas drawing on an in-memory image, encoded as PNG.
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func newPNGCanvas(width, height int, bg color.RGBA) *pngCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{bg}, image.Point{}, draw.Src)
+	return &pngCanvas{img: img}
+}
+
+func (c *pngCanvas) line(x1, y1, x2, y2 float64, col color.RGBA) {
+	c.drawLine(x1, y1, x2, y2, col, false)
+}
+
+func (c *pngCanvas) polyline(xs, ys []float64, col color.RGBA) {
+	for i := 1; i < len(xs); i++ {
+		c.drawLine(xs[i-1], ys[i-1], xs[i], ys[i], col, true)
+	}
+}
+
+func (c *pngCanvas) rect(x, y, w, h float64, col color.RGBA) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	draw.Draw(c.img, r, &image.Uniform{col}, image.Point{}, draw.Src)
+}
+
+func (c *pngCanvas) text(x, y float64, s string, large bool, anchor textAnchor, col color.RGBA) {
+	scale := fontScale(large)
+	w := c.textWidth(s, large)
+	switch anchor {
+	case anchorMiddle:
+		x -= w / 2
+	case anchorEnd:
+		x -= w
+	}
+	px := int(math.Round(x))
+	py := int(math.Round(y)) - fontHeight*scale/2
+	for _, r := range s {
+		glyph, ok := fontGlyphs[r]
+		if !ok {
+			glyph = fontGlyphs['?']
+		}
+		for i, bits := range glyph {
+			for row := 0; row < fontHeight; row++ {
+				if bits&(1<<uint(row)) == 0 {
+					continue
+				}
+				for dx := 0; dx < scale; dx++ {
+					for dy := 0; dy < scale; dy++ {
+						c.img.SetRGBA(px+i*scale+dx, py+row*scale+dy, col)
+					}
+				}
+			}
+		}
+		px += fontAdvance * scale
+	}
+}
+
+func (c *pngCanvas) textWidth(s string, large bool) float64 {
+	return float64(len([]rune(s)) * fontAdvance * fontScale(large))
+}
+
+// drawLine draws a straight line using Bresenham's algorithm. Thick lines
+// are two pixels wide.
+func (c *pngCanvas) drawLine(fx1, fy1, fx2, fy2 float64, col color.RGBA, thick bool) {
+	x1, y1 := int(math.Round(fx1)), int(math.Round(fy1))
+	x2, y2 := int(math.Round(fx2)), int(math.Round(fy2))
+	dx := abs(x2 - x1)
+	dy := -abs(y2 - y1)
+	stepX, stepY := 1, 1
+	if x1 > x2 {
+		stepX = -1
+	}
+	if y1 > y2 {
+		stepY = -1
+	}
+	e := dx + dy
+	for {
+		c.img.SetRGBA(x1, y1, col)
+		if thick {
+			if dx > -dy {
+				c.img.SetRGBA(x1, y1+1, col)
+			} else {
+				c.img.SetRGBA(x1+1, y1, col)
+			}
+		}
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x1 += stepX
+		}
+		if e2 <= dx {
+			e += dx
+			y1 += stepY
+		}
+	}
+}
+
+// encode writes the image to w in PNG format.
+func (c *pngCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+func fontScale(large bool) int {
+	if large {
+		return 2
+	}
+	return 1
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+const (
+	svgFontSize      = 11
+	svgLargeFontSize = 16
+)
+
+// svgCanvas is a canvas producing an SVG document.
+type svgCanvas struct {
+	width  int
+	height int
+	buf    bytes.Buffer
+}
+
+func newSVGCanvas(width, height int, bg color.RGBA) *svgCanvas {
+	c := &svgCanvas{width: width, height: height}
+	c.rect(0, 0, float64(width), float64(height), bg)
+	return c
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2 float64, col color.RGBA) {
+	fmt.Fprintf(&c.buf, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-width="1"/>`+"\n",
+		x1, y1, x2, y2, svgColor(col))
+}
+
+func (c *svgCanvas) polyline(xs, ys []float64, col color.RGBA) {
+	points := make([]string, len(xs))
+	for i := range xs {
+		points[i] = fmt.Sprintf("%.2f,%.2f", xs[i], ys[i])
+	}
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+		strings.Join(points, " "), svgColor(col))
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, col color.RGBA) {
+	fmt.Fprintf(&c.buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`+"\n",
+		x, y, w, h, svgColor(col))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, large bool, anchor textAnchor, col color.RGBA) {
+	size := svgFontSize
+	if large {
+		size = svgLargeFontSize
+	}
+	anchors := map[textAnchor]string{anchorStart: "start", anchorMiddle: "middle", anchorEnd: "end"}
+	fmt.Fprintf(&c.buf, `<text x="%.2f" y="%.2f" font-size="%d" text-anchor="%s" dominant-baseline="middle" fill="%s">`,
+		x, y, size, anchors[anchor], svgColor(col))
+	_ = xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) textWidth(s string, large bool) float64 {
+	size := svgFontSize
+	if large {
+		size = svgLargeFontSize
+	}
+	// An approximation of the average glyph width of sans-serif fonts.
+	return float64(len(s)*size) * 0.6
+}
+
+// encode writes the SVG document to w.
+func (c *svgCanvas) encode(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`+"\n%s</svg>\n",
+		c.width, c.height, c.width, c.height, c.buf.String())
+	return err
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNiceTicks(t *testing.T) {
+	require.InDeltaSlice(t, []float64{0, 0.2, 0.4, 0.6, 0.8, 1}, niceTicks(0, 1, 6), 1e-9)
+	require.Equal(t, []float64{0, 20, 40, 60, 80, 100, 120}, niceTicks(0, 110, 6))
+	require.Equal(t, []float64{-50, 0, 50, 100}, niceTicks(-30, 90, 4))
+}
+
+func TestTimeTicks(t *testing.T) {
+	require.Equal(t, []float64{0, 60, 120, 180, 240, 300}, timeTicks(0, 295, 6))
+	require.Equal(t, []float64{0, 2, 4, 6, 8, 10}, timeTicks(0, 10, 6))
+	require.Equal(t, []float64{0, 1, 2}, timeTicks(0, 1.5, 6))
+}
+
+func TestFormatElapsed(t *testing.T) {
+	require.Equal(t, "0s", formatElapsed(0))
+	require.Equal(t, "45s", formatElapsed(45))
+	require.Equal(t, "2m", formatElapsed(120))
+	require.Equal(t, "2m30s", formatElapsed(150))
+	require.Equal(t, "1h", formatElapsed(3600))
+}
+
+func TestChartRender(t *testing.T) {
+	ch := newChart("CPU Utilization", []labelValues{
+		{
+			label: "base",
+			values: []model.SamplePair{
+				{Timestamp: 1000, Value: 10},
+				{Timestamp: 6000, Value: model.SampleValue(math.NaN())},
+				{Timestamp: 11000, Value: 30},
+			},
+		},
+		{
+			label:  "new <1>",
+			values: []model.SamplePair{{Timestamp: 5000, Value: 20}, {Timestamp: 10000, Value: 25}},
+		},
+	})
+	require.Len(t, ch.series, 2)
+	require.Equal(t, []float64{0, 10}, ch.series[0].x)
+	require.Equal(t, []float64{10, 30}, ch.series[0].y)
+
+	t.Run("png", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, ch.render(&buf, GraphFormatPNG))
+		img, err := png.Decode(&buf)
+		require.NoError(t, err)
+		require.Equal(t, chartWidth, img.Bounds().Dx())
+		require.Equal(t, chartHeight, img.Bounds().Dy())
+
+		// The output is reproducible.
+		var other bytes.Buffer
+		require.NoError(t, ch.render(&other, GraphFormatPNG))
+		var again bytes.Buffer
+		require.NoError(t, ch.render(&again, GraphFormatPNG))
+		require.Equal(t, other.Bytes(), again.Bytes())
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, ch.render(&buf, GraphFormatSVG))
+		out := buf.String()
+		require.Contains(t, out, "CPU Utilization")
+		require.Contains(t, out, "new &lt;1&gt;")
+		require.Contains(t, out, "<polyline")
+
+		// The output is well-formed XML.
+		dec := xml.NewDecoder(&buf)
+		for {
+			_, err := dec.Token()
+			if err != nil {
+				require.Equal(t, "EOF", err.Error())
+				break
+			}
+		}
+	})
+
+	require.Error(t, ch.render(&bytes.Buffer{}, GraphFormat("gif")))
+}
+
+func TestChartRenderEmpty(t *testing.T) {
+	ch := newChart("Empty", nil)
+	require.NoError(t, ch.render(&bytes.Buffer{}, GraphFormatPNG))
+	require.NoError(t, ch.render(&bytes.Buffer{}, GraphFormatSVG))
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Threshold is the maximum regression allowed for a measurement. A
+// regression exceeding any of the set limits is a violation.
+type Threshold struct {
+	// The maximum allowed increase, as a percentage of the base value.
+	// Ignored if zero.
+	MaxDeltaPercent float64
+	// The maximum allowed increase in milliseconds. Ignored if zero, or if
+	// the metric is not measured in seconds.
+	MaxDeltaMs float64
+}
+
+// Thresholds holds the regressions allowed when checking a report against
+// a base one.
+type Thresholds struct {
+	// The threshold applied to measurements which are not matched by
+	// Labels or Metrics.
+	Default Threshold
+	// The thresholds for all the values of a metric, indexed by metric
+	// name.
+	Metrics map[string]Threshold
+	// The thresholds for a given store method, API handler or any other
+	// label, across all metrics. They take precedence over Metrics.
+	Labels map[string]Threshold
+	// The minimum confidence, as a percentage, that a regression is
+	// statistically significant for it to be a violation. Regressions of
+	// metrics without histograms are always considered significant.
+	// Ignored if zero.
+	MinConfidence float64 `json:",omitempty"`
+}
+
+// Violation is a measurement which regressed more than allowed.
+type Violation struct {
+	Metric       string // The name of the metric.
+	Label        string // The store method, API handler or other label.
+	Unit         Unit
+	Base         float64
+	Actual       float64
+	Delta        float64
+	DeltaPercent float64
+}
+
+// LoadThresholds loads the thresholds from a given file path.
+func LoadThresholds(path string) (Thresholds, error) {
+	var t Thresholds
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(buf, &t); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// threshold returns the threshold applying to the given measurement.
+func (t Thresholds) threshold(metric, label string) Threshold {
+	if th, ok := t.Labels[label]; ok {
+		return th
+	}
+	if th, ok := t.Metrics[metric]; ok {
+		return th
+	}
+	return t.Default
+}
+
+// check reports whether the given regression exceeds the threshold.
+func (t Threshold) check(delta, deltaPercent float64, unit Unit) bool {
+	if t.MaxDeltaPercent > 0 && deltaPercent > t.MaxDeltaPercent {
+		return true
+	}
+	if t.MaxDeltaMs > 0 && unit == UnitSeconds && delta*1000 > t.MaxDeltaMs {
+		return true
+	}
+	return false
+}
+
+// Check compares the candidate report against the base one and returns
+// the measurements which regressed more than the given thresholds allow.
+// Metrics describing the load, such as request counts, are not checked, nor
+// are regressions less significant than Thresholds.MinConfidence.
+func Check(base, candidate Report, thresholds Thresholds) []Violation {
+	var violations []Violation
+	for _, m := range calculateDeltas(base, candidate) {
+		if m.Unit.describesLoad() {
+			continue
+		}
+		for _, label := range sortKeys(m.Values) {
+			t := thresholds.threshold(m.Name, string(label))
+			for _, d := range m.diffs[label] {
+				if d.delta <= 0 {
+					continue
+				}
+				if d.hasConfidence && d.confidence*100 < thresholds.MinConfidence {
+					continue
+				}
+				baseValue := d.actual - d.delta
+				deltaPercent := 100.0
+				if baseValue > 0 {
+					deltaPercent = d.delta / baseValue * 100
+				}
+				if t.check(d.delta, deltaPercent, m.Unit) {
+					violations = append(violations, Violation{
+						Metric:       m.Name,
+						Label:        string(label),
+						Unit:         m.Unit,
+						Base:         baseValue,
+						Actual:       d.actual,
+						Delta:        d.delta,
+						DeltaPercent: deltaPercent,
+					})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// DisplayViolations prints the given violations as a markdown table to the
+// given target.
+func DisplayViolations(target io.Writer, violations []Violation) {
+	fmt.Fprintln(target, "| Metric | Name | Base | Actual | Delta | Delta % |")
+	fmt.Fprintln(target, "| --- | --- | --- | --- | --- | --- |")
+	for _, v := range violations {
+		fmt.Fprintf(target, "| %s | %s | %s | %s | %s | %.3f |\n",
+			v.Metric, v.Label, v.Unit.format(v.Base), v.Unit.format(v.Actual), v.Unit.format(v.Delta), v.DeltaPercent)
+	}
+}
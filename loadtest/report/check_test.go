@@ -0,0 +1,122 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	newReport := func(label string, storeAvg, storeP99, apiAvg, apiP99 map[model.LabelValue]model.SampleValue) Report {
+		return Report{
+			Label: label,
+			Metrics: []MetricTable{
+				{Name: MetricAvgStoreTimes, GroupBy: "method", Unit: UnitSeconds, Values: storeAvg},
+				{Name: MetricP99StoreTimes, GroupBy: "method", Unit: UnitSeconds, Values: storeP99},
+				{Name: MetricAvgAPITimes, GroupBy: "handler", Unit: UnitSeconds, Values: apiAvg},
+				{Name: MetricP99APITimes, GroupBy: "handler", Unit: UnitSeconds, Values: apiP99},
+			},
+		}
+	}
+	base := newReport("base",
+		map[model.LabelValue]model.SampleValue{"Post.Get": 0.010, "User.Get": 0.010},
+		map[model.LabelValue]model.SampleValue{"Post.Get": 0.100, "User.Get": 0.100},
+		map[model.LabelValue]model.SampleValue{"getPost": 0.020},
+		map[model.LabelValue]model.SampleValue{"getPost": 0.200},
+	)
+	candidate := newReport("new",
+		map[model.LabelValue]model.SampleValue{"Post.Get": 0.012, "User.Get": 0.009},
+		map[model.LabelValue]model.SampleValue{"Post.Get": 0.105, "User.Get": 0.150},
+		map[model.LabelValue]model.SampleValue{"getPost": 0.025},
+		map[model.LabelValue]model.SampleValue{"getPost": 0.210},
+	)
+
+	t.Run("no thresholds", func(t *testing.T) {
+		require.Empty(t, Check(base, candidate, Thresholds{}))
+	})
+
+	t.Run("default percent", func(t *testing.T) {
+		violations := Check(base, candidate, Thresholds{Default: Threshold{MaxDeltaPercent: 10}})
+		require.Len(t, violations, 3)
+
+		expected := []Violation{
+			{Metric: MetricAvgStoreTimes, Label: "Post.Get", Unit: UnitSeconds, Base: 0.010, Actual: 0.012, Delta: 0.002, DeltaPercent: 20},
+			{Metric: MetricP99StoreTimes, Label: "User.Get", Unit: UnitSeconds, Base: 0.100, Actual: 0.150, Delta: 0.050, DeltaPercent: 50},
+			{Metric: MetricAvgAPITimes, Label: "getPost", Unit: UnitSeconds, Base: 0.020, Actual: 0.025, Delta: 0.005, DeltaPercent: 25},
+		}
+		for i, v := range violations {
+			require.Equal(t, expected[i].Metric, v.Metric)
+			require.Equal(t, expected[i].Label, v.Label)
+			require.Equal(t, expected[i].Unit, v.Unit)
+			require.InDelta(t, expected[i].Base, v.Base, 1e-9)
+			require.InDelta(t, expected[i].Actual, v.Actual, 1e-9)
+			require.InDelta(t, expected[i].Delta, v.Delta, 1e-9)
+			require.InDelta(t, expected[i].DeltaPercent, v.DeltaPercent, 1e-6)
+		}
+	})
+
+	t.Run("load metrics", func(t *testing.T) {
+		base := base
+		base.Metrics = []MetricTable{{Name: "API requests (count)", GroupBy: "handler", Unit: UnitCount, Values: map[model.LabelValue]model.SampleValue{"getPost": 100}}}
+		candidate := candidate
+		candidate.Metrics = []MetricTable{{Name: "API requests (count)", GroupBy: "handler", Unit: UnitCount, Values: map[model.LabelValue]model.SampleValue{"getPost": 200}}}
+		require.Empty(t, Check(base, candidate, Thresholds{Default: Threshold{MaxDeltaPercent: 10}}))
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		violations := Check(base, candidate, Thresholds{
+			Default: Threshold{MaxDeltaPercent: 10},
+			Metrics: map[string]Threshold{
+				MetricAvgStoreTimes: {MaxDeltaPercent: 25},
+				MetricP99StoreTimes: {MaxDeltaMs: 60},
+			},
+			Labels: map[string]Threshold{
+				"getPost": {MaxDeltaMs: 4},
+			},
+		})
+		require.Len(t, violations, 2)
+		require.Equal(t, MetricAvgAPITimes, violations[0].Metric)
+		require.Equal(t, "getPost", violations[0].Label)
+		require.Equal(t, MetricP99APITimes, violations[1].Metric)
+		require.Equal(t, "getPost", violations[1].Label)
+	})
+}
+
+func TestCheckMinConfidence(t *testing.T) {
+	newReport := func(label string, avg model.SampleValue, hist Histogram) Report {
+		return Report{
+			Label: label,
+			Metrics: []MetricTable{
+				{Name: MetricAvgStoreTimes, GroupBy: "method", Unit: UnitSeconds, Histogram: HistogramStoreTimes, Values: map[model.LabelValue]model.SampleValue{"Post.Get": avg}},
+			},
+			Histograms: []HistogramTable{
+				{Name: HistogramStoreTimes, GroupBy: "method", Histograms: map[model.LabelValue]Histogram{"Post.Get": hist}},
+			},
+		}
+	}
+	hist := Histogram{Buckets: []Bucket{{0.01, 50}, {0.1, 90}}, Count: 100}
+	base := newReport("base", 0.010, hist)
+	thresholds := Thresholds{Default: Threshold{MaxDeltaPercent: 10}, MinConfidence: 95}
+
+	t.Run("noise", func(t *testing.T) {
+		candidate := newReport("new", 0.012, hist)
+		require.Empty(t, Check(base, candidate, thresholds))
+		thresholds := thresholds
+		thresholds.MinConfidence = 0
+		require.Len(t, Check(base, candidate, thresholds), 1)
+	})
+
+	t.Run("significant", func(t *testing.T) {
+		candidate := newReport("new", 0.012, Histogram{Buckets: []Bucket{{0.01, 20}, {0.1, 70}}, Count: 100})
+		require.Len(t, Check(base, candidate, thresholds), 1)
+	})
+
+	t.Run("no histograms", func(t *testing.T) {
+		candidate := newReport("new", 0.012, Histogram{})
+		require.Len(t, Check(base, candidate, thresholds), 1)
+	})
+}
@@ -5,91 +5,114 @@ package report
 
 import (
 	"fmt"
+	"io"
 	"math"
-	"os"
-	"time"
 
 	"github.com/prometheus/common/model"
 )
 
 // diff contains the differences from a base measurement.
 type diff struct {
-	actual       time.Duration
-	delta        time.Duration
+	actual       float64
+	delta        float64
 	deltaPercent float64
+	// The confidence, between 0 and 1, that the difference is
+	// statistically significant. Only set if hasConfidence is true.
+	confidence    float64
+	hasConfidence bool
 }
 
-// avgp99 is an array of 2 slices.
-// The array is for avg and p99 measurements.
-// The slice is used to contain measurements from multiple reports so that they can be
-// displayed side-by-side.
-type avgp99 [2][]diff
-
-type comp struct {
-	store map[model.LabelValue]avgp99
-	api   map[model.LabelValue]avgp99
+// metricComp is the comparison of a single metric.
+type metricComp struct {
+	MetricTable // The metric of the base report.
+	// The diffs of each label, one per compared report, so that they can
+	// be displayed side-by-side.
+	diffs map[model.LabelValue][]diff
 }
 
+// comp holds the comparison of all the metrics of the base report.
+type comp []metricComp
+
 // labelValues is used to compare a single metric from different load tests.
 type labelValues struct {
 	label  string             // The label of a report.
 	values []model.SamplePair // The series of values for a given metric.
 }
 
-// Compare compares the given set of reports.
+// Format is the output format of a comparison.
+type Format string
+
+// Available output formats.
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// IsValid reports whether a given Format is valid or not.
+// Returns an error if the validation fails.
+func (f Format) IsValid() error {
+	switch f {
+	case FormatMarkdown, FormatHTML:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q", f)
+	}
+}
+
+// CompareOpts holds the options of a comparison.
+type CompareOpts struct {
+	// The output format.
+	Format Format
+	// If set, graphs comparing the metrics of the reports are also written
+	// to the current directory in this format. It is only used by the
+	// markdown format, since HTML output already embeds the graphs.
+	GraphFormat GraphFormat
+	// Whether to annotate each delta with the confidence that it is
+	// statistically significant, computed from the histograms of the
+	// reports.
+	Significance bool
+}
+
+// Compare compares the given set of reports with the given options.
 // The first report is considered to be the base.
-func Compare(target *os.File, genGraph bool, reports ...Report) error {
+func Compare(target io.Writer, opts CompareOpts, reports ...Report) error {
+	format, graphFormat := opts.Format, opts.GraphFormat
+	if err := format.IsValid(); err != nil {
+		return err
+	}
+	if format == FormatHTML {
+		return WriteHTML(target, opts.Significance, reports...)
+	}
+	if graphFormat != "" {
+		if err := graphFormat.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	base := reports[0]
 
 	// Calculate the deltas.
 	c := calculateDeltas(reports...)
 
 	// Now display the comparison in markdown.
-	displayMarkdown(c, target, base, len(reports[1:]))
+	displayMarkdown(c, target, len(reports[1:]), opts.Significance)
+
+	if graphFormat == "" {
+		return nil
+	}
 
 	// TODO: generate a single image combining all the graphs.
-	// Printing the graphs.
-	if genGraph {
-		// gPlots is a slice of structs to aggregate graphs of a single type
-		// from multiple reports.
-		var gPlots []struct {
-			name   string // the name of the metric
-			graphs []labelValues
-		}
-		// A single report has multiple graphs.
-		// What we are doing here is changing the aggregation such that
-		// a single gPlot contains graphs of a single type from multiple reports.
-		for i, r := range reports[1:] {
-			for _, g := range r.Graphs {
-				if i == 0 {
-					gPlots = append(gPlots, struct {
-						name   string
-						graphs []labelValues
-					}{
-						// Only set the name one time
-						name: g.Name,
-						graphs: []labelValues{
-							{
-								label:  r.Label,
-								values: g.Values,
-							},
-						},
-					})
-				} else {
-					// Graph of the same type from another report, append it.
-					gPlots[i].graphs = append(gPlots[i].graphs, labelValues{
-						label:  r.Label,
-						values: g.Values,
-					})
-				}
+	// A single report has multiple graphs. Graphs of the same metric from
+	// all the reports are plotted together.
+	for i, g := range base.Graphs {
+		graphs := []labelValues{{label: base.Label, values: g.Values}}
+		for _, r := range reports[1:] {
+			if i < len(r.Graphs) {
+				graphs = append(graphs, labelValues{label: r.Label, values: r.Graphs[i].Values})
 			}
 		}
-
-		for i, plot := range gPlots {
-			err := generateGraph(plot.name, base.Label, base.Graphs[i], plot.graphs)
-			if err != nil {
-				return fmt.Errorf("error while generating graph for %s: %w", plot.name, err)
-			}
+		if err := generateGraph(g.Name, graphs, graphFormat); err != nil {
+			return fmt.Errorf("error while generating graph for %s: %w", g.Name, err)
 		}
 	}
 	return nil
@@ -98,80 +121,58 @@ func Compare(target *os.File, genGraph bool, reports ...Report) error {
 // calculateDeltas returns a comparison from a given set of reports.
 func calculateDeltas(reports ...Report) comp {
 	base := reports[0]
-	c := comp{
-		store: make(map[model.LabelValue]avgp99),
-		api:   make(map[model.LabelValue]avgp99),
-	}
-	for _, r := range reports[1:] {
-		// XXX: This can be somewhat refactored but whether absolute metrics
-		// are useful or not needs to be seen.
-		for label, value := range base.AvgStoreTimes {
-			actual := getDuration(float64(r.AvgStoreTimes[label]))
-			delta := actual - getDuration(float64(value))
-			deltaP := (delta.Seconds() / actual.Seconds()) * 100
-			if math.IsNaN(deltaP) {
-				deltaP = 0
-			}
-
-			diffs := c.store[label]
-			diffs[0] = append(diffs[0], diff{
-				actual:       actual,
-				delta:        delta,
-				deltaPercent: deltaP,
-			})
-			c.store[label] = diffs
+	c := make(comp, 0, len(base.Metrics))
+	for _, m := range base.Metrics {
+		mc := metricComp{
+			MetricTable: m,
+			diffs:       make(map[model.LabelValue][]diff),
 		}
-
-		for label, value := range base.P99StoreTimes {
-			actual := getDuration(float64(r.P99StoreTimes[label]))
-			delta := actual - getDuration(float64(value))
-			deltaP := (delta.Seconds() / actual.Seconds()) * 100
-			if math.IsNaN(deltaP) {
-				deltaP = 0
+		baseHist, _ := base.histogram(m.Histogram)
+		for _, r := range reports[1:] {
+			other, _ := r.metric(m.Name)
+			otherHist, _ := r.histogram(m.Histogram)
+			for label, value := range m.Values {
+				actual := roundValue(float64(other.Values[label]), m.Unit)
+				delta := actual - roundValue(float64(value), m.Unit)
+				deltaP := (delta / actual) * 100
+				if math.IsNaN(deltaP) {
+					deltaP = 0
+				}
+				d := diff{
+					actual:       actual,
+					delta:        delta,
+					deltaPercent: deltaP,
+				}
+				if m.Histogram != "" {
+					d.confidence, d.hasConfidence = significance(baseHist.Histograms[label], otherHist.Histograms[label])
+				}
+				mc.diffs[label] = append(mc.diffs[label], d)
 			}
-
-			diffs := c.store[label]
-			diffs[1] = append(diffs[1], diff{
-				actual:       actual,
-				delta:        delta,
-				deltaPercent: deltaP,
-			})
-			c.store[label] = diffs
 		}
+		c = append(c, mc)
+	}
+	return c
+}
 
-		for label, value := range base.AvgAPITimes {
-			actual := getDuration(float64(r.AvgAPITimes[label]))
-			delta := actual - getDuration(float64(value))
-			deltaP := (delta.Seconds() / actual.Seconds()) * 100
-			if math.IsNaN(deltaP) {
-				deltaP = 0
-			}
-
-			diffs := c.api[label]
-			diffs[0] = append(diffs[0], diff{
-				actual:       actual,
-				delta:        delta,
-				deltaPercent: deltaP,
-			})
-			c.api[label] = diffs
-		}
+// significant reports whether the diff is either statistically significant
+// or of unknown significance.
+func (d diff) significant() bool {
+	return !d.hasConfidence || d.confidence >= significanceLevel
+}
 
-		for label, value := range base.P99APITimes {
-			actual := getDuration(float64(r.P99APITimes[label]))
-			delta := actual - getDuration(float64(value))
-			deltaP := (delta.Seconds() / actual.Seconds()) * 100
-			if math.IsNaN(deltaP) {
-				deltaP = 0
-			}
+// formatConfidence returns a human readable representation of the
+// confidence of the diff.
+func (d diff) formatConfidence() string {
+	if !d.hasConfidence {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", d.confidence*100)
+}
 
-			diffs := c.api[label]
-			diffs[1] = append(diffs[1], diff{
-				actual:       actual,
-				delta:        delta,
-				deltaPercent: deltaP,
-			})
-			c.api[label] = diffs
-		}
+// roundValue rounds a value to the precision it is displayed with.
+func roundValue(v float64, unit Unit) float64 {
+	if unit == UnitSeconds {
+		return getDuration(v).Seconds()
 	}
-	return c
+	return v
 }
@@ -17,8 +17,15 @@ import (
 
 // Config contains information needed to generate reports.
 type Config struct {
-	Label        string // Label to be used when querying Prometheus.
-	GraphQueries []GraphQuery
+	Label string // Label to be used when querying Prometheus.
+	// The tabular metrics to include in reports. If empty, the ones
+	// returned by DefaultMetricQueries are used.
+	MetricQueries []MetricQuery
+	// The histograms to include in reports, used to assess the statistical
+	// significance of comparisons. If empty, the ones returned by
+	// DefaultHistogramQueries are used.
+	HistogramQueries []HistogramQuery
+	GraphQueries     []GraphQuery
 }
 
 // GraphQuery contains the query to be executed against a Prometheus instance
@@ -38,12 +45,14 @@ type Generator struct {
 // Report contains the entire report data comprising of several metrics
 // that are needed to compare load test runs.
 type Report struct {
-	Label         string // A friendly name of the report.
-	AvgStoreTimes map[model.LabelValue]model.SampleValue
-	P99StoreTimes map[model.LabelValue]model.SampleValue
-	AvgAPITimes   map[model.LabelValue]model.SampleValue
-	P99APITimes   map[model.LabelValue]model.SampleValue
-	Graphs        []graph
+	Label     string    // A friendly name of the report.
+	StartTime time.Time // The start of the time range the report covers.
+	EndTime   time.Time // The end of the time range the report covers.
+	Metrics   []MetricTable
+	// The distributions of the observations of the metrics, used to assess
+	// the statistical significance of comparisons.
+	Histograms []HistogramTable `json:",omitempty"`
+	Graphs     []graph
 }
 
 // graph contains data for a single metric.
@@ -61,7 +70,8 @@ func New(label string, helper *prometheus.Helper, cfg Config) *Generator {
 	}
 }
 
-// Load loads a report from a given file path.
+// Load loads a report from a given file path. Reports generated by older
+// versions, storing a fixed set of metrics, are converted.
 func Load(path string) (Report, error) {
 	var r Report
 	buf, err := ioutil.ReadFile(path)
@@ -72,49 +82,79 @@ func Load(path string) (Report, error) {
 	if err != nil {
 		return r, err
 	}
+	if len(r.Metrics) == 0 {
+		var legacy legacyReport
+		if err := json.Unmarshal(buf, &legacy); err != nil {
+			return r, err
+		}
+		r.Metrics = legacy.tables()
+	}
 	return r, nil
 }
 
+// metric returns the table of the metric with the given name, if present.
+func (r Report) metric(name string) (MetricTable, bool) {
+	for _, m := range r.Metrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return MetricTable{}, false
+}
+
 // Generate returns a report from a given start time to end time.
 func (g *Generator) Generate(startTime, endTime time.Time) (Report, error) {
 	data := Report{
-		Label: g.label,
+		Label:     g.label,
+		StartTime: startTime,
+		EndTime:   endTime,
 	}
 
-	var err error
-	diff := endTime.Sub(startTime)
-	sec := int(diff.Seconds())
-
-	// Avg store times.
-	tmpl := `sum(rate(mattermost_db_store_time_sum%s[%ds])) by (method) / sum(rate(mattermost_db_store_time_count%s[%ds])) by (method)`
-	query := fmt.Sprintf(tmpl, g.cfg.Label, sec, g.cfg.Label, sec)
-	data.AvgStoreTimes, err = g.getValue(endTime, query, "method")
-	if err != nil {
-		return data, fmt.Errorf("error while getting avg store times: %w", err)
+	params := queryParams{
+		Label: g.cfg.Label,
+		Range: fmt.Sprintf("%ds", int(endTime.Sub(startTime).Seconds())),
 	}
 
-	// P99 store times.
-	tmpl = `histogram_quantile(0.99, sum(rate(mattermost_db_store_time_bucket%s[%ds])) by (le,method))`
-	query = fmt.Sprintf(tmpl, g.cfg.Label, sec)
-	data.P99StoreTimes, err = g.getValue(endTime, query, "method")
-	if err != nil {
-		return data, fmt.Errorf("error while getting p99 store times: %w", err)
+	metrics := g.cfg.MetricQueries
+	if len(metrics) == 0 {
+		metrics = DefaultMetricQueries()
 	}
-
-	// Avg API times.
-	tmpl = `sum(rate(mattermost_api_time_sum%s[%ds])) by (handler) / sum(rate(mattermost_api_time_count%s[%ds])) by (handler)`
-	query = fmt.Sprintf(tmpl, g.cfg.Label, sec, g.cfg.Label, sec)
-	data.AvgAPITimes, err = g.getValue(endTime, query, "handler")
-	if err != nil {
-		return data, fmt.Errorf("error while getting avg API times: %w", err)
+	for _, mq := range metrics {
+		query, err := mq.build(params)
+		if err != nil {
+			return data, err
+		}
+		values, err := g.getValue(endTime, query, mq.GroupBy)
+		if err != nil {
+			return data, fmt.Errorf("error while getting %s: %w", mq.Name, err)
+		}
+		data.Metrics = append(data.Metrics, MetricTable{
+			Name:      mq.Name,
+			GroupBy:   mq.GroupBy,
+			Unit:      mq.Unit,
+			Histogram: mq.Histogram,
+			Values:    values,
+		})
 	}
 
-	// P99 API times.
-	tmpl = `histogram_quantile(0.99, sum(rate(mattermost_api_time_bucket%s[%ds])) by (handler,le))`
-	query = fmt.Sprintf(tmpl, g.cfg.Label, sec)
-	data.P99APITimes, err = g.getValue(endTime, query, "handler")
-	if err != nil {
-		return data, fmt.Errorf("error while getting p99 API times: %w", err)
+	histograms := g.cfg.HistogramQueries
+	if len(histograms) == 0 {
+		histograms = DefaultHistogramQueries()
+	}
+	for _, hq := range histograms {
+		query, err := buildQuery(hq.Name, hq.Query, params, hq.GroupBy, 0)
+		if err != nil {
+			return data, err
+		}
+		values, err := g.getHistograms(endTime, query, hq.GroupBy)
+		if err != nil {
+			return data, fmt.Errorf("error while getting %s: %w", hq.Name, err)
+		}
+		data.Histograms = append(data.Histograms, HistogramTable{
+			Name:       hq.Name,
+			GroupBy:    hq.GroupBy,
+			Histograms: values,
+		})
 	}
 
 	for _, gq := range g.cfg.GraphQueries {
@@ -5,10 +5,14 @@ package report
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/mattermost/mattermost-load-test-ng/coordinator/performance/prometheus"
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
 
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
@@ -50,102 +54,6 @@ func TestGenerate(t *testing.T) {
 	}
 
 	var input = map[string]model.Matrix{
-		"sum(rate(mattermost_db_store_time_sum[10s])) by (method) / sum(rate(mattermost_db_store_time_count[10s])) by (method)": {
-			&model.SampleStream{
-				Metric: model.Metric{
-					"method": "method1",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.01,
-					},
-				},
-			},
-			&model.SampleStream{
-				Metric: model.Metric{
-					"method": "method2",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.02,
-					},
-				},
-			},
-		},
-		"histogram_quantile(0.99, sum(rate(mattermost_db_store_time_bucket[10s])) by (le,method))": {
-			&model.SampleStream{
-				Metric: model.Metric{
-					"method": "method1",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.01,
-					},
-				},
-			},
-			&model.SampleStream{
-				Metric: model.Metric{
-					"method": "method2",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.02,
-					},
-				},
-			},
-		},
-		"sum(rate(mattermost_api_time_sum[10s])) by (handler) / sum(rate(mattermost_api_time_count[10s])) by (handler)": {
-			&model.SampleStream{
-				Metric: model.Metric{
-					"handler": "handler1",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.01,
-					},
-				},
-			},
-			&model.SampleStream{
-				Metric: model.Metric{
-					"handler": "handler2",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.02,
-					},
-				},
-			},
-		},
-		"histogram_quantile(0.99, sum(rate(mattermost_api_time_bucket[10s])) by (handler,le))": {
-			&model.SampleStream{
-				Metric: model.Metric{
-					"handler": "handler1",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.01,
-					},
-				},
-			},
-			&model.SampleStream{
-				Metric: model.Metric{
-					"handler": "handler2",
-				},
-				Values: []model.SamplePair{
-					{
-						Timestamp: model.Time(time.Now().Unix()),
-						Value:     0.02,
-					},
-				},
-			},
-		},
 		`avg(irate(mattermost_process_cpu_seconds_total{instance=~"app.*"}[1m])* 100)`: {
 			&model.SampleStream{
 				Metric: model.Metric{},
@@ -178,6 +86,53 @@ func TestGenerate(t *testing.T) {
 		},
 	}
 
+	// Each default metric returns the same values.
+	var metrics []MetricTable
+	for _, mq := range DefaultMetricQueries() {
+		query, err := mq.build(queryParams{Range: "10s"})
+		require.NoError(t, err)
+		valuesMap := storeMap
+		if mq.GroupBy == "handler" {
+			valuesMap = apiMap
+		}
+		var streams model.Matrix
+		for label, value := range valuesMap {
+			streams = append(streams, &model.SampleStream{
+				Metric: model.Metric{model.LabelName(mq.GroupBy): label},
+				Values: []model.SamplePair{{Timestamp: model.Time(time.Now().Unix()), Value: value}},
+			})
+		}
+		input[query] = streams
+		metrics = append(metrics, MetricTable{Name: mq.Name, GroupBy: mq.GroupBy, Unit: mq.Unit, Histogram: mq.Histogram, Values: valuesMap})
+	}
+
+	// Each default histogram has the same buckets.
+	var histograms []HistogramTable
+	for _, hq := range DefaultHistogramQueries() {
+		query, err := buildQuery(hq.Name, hq.Query, queryParams{Range: "10s"}, hq.GroupBy, 0)
+		require.NoError(t, err)
+		labels := storeMap
+		if hq.GroupBy == "handler" {
+			labels = apiMap
+		}
+		var streams model.Matrix
+		table := HistogramTable{Name: hq.Name, GroupBy: hq.GroupBy, Histograms: make(map[model.LabelValue]Histogram)}
+		for label := range labels {
+			for _, b := range []struct {
+				le    model.LabelValue
+				count model.SampleValue
+			}{{"+Inf", 10}, {"0.1", 8}, {"0.01", 5}} {
+				streams = append(streams, &model.SampleStream{
+					Metric: model.Metric{model.LabelName(hq.GroupBy): label, model.BucketLabel: b.le},
+					Values: []model.SamplePair{{Timestamp: model.Time(time.Now().Unix()), Value: b.count}},
+				})
+			}
+			table.Histograms[label] = Histogram{Buckets: []Bucket{{0.01, 5}, {0.1, 8}}, Count: 10}
+		}
+		input[query] = streams
+		histograms = append(histograms, table)
+	}
+
 	cfg := Config{
 		Label: "",
 		GraphQueries: []GraphQuery{
@@ -205,12 +160,13 @@ func TestGenerate(t *testing.T) {
 	}
 
 	label := "base"
+	now := time.Now()
 	var output = Report{
-		Label:         label,
-		AvgStoreTimes: storeMap,
-		P99StoreTimes: storeMap,
-		AvgAPITimes:   apiMap,
-		P99APITimes:   apiMap,
+		Label:      label,
+		StartTime:  now.Add(-10 * time.Second),
+		EndTime:    now,
+		Metrics:    metrics,
+		Histograms: histograms,
 		Graphs: []graph{
 			{
 				Name:   "CPU Utilization",
@@ -241,8 +197,116 @@ func TestGenerate(t *testing.T) {
 	})
 
 	g := New(label, helper, cfg)
-	now := time.Now()
 	r, err := g.Generate(now.Add(-10*time.Second), now)
 	require.NoError(t, err)
 	assert.Equal(t, output, r, "incorrect report generated")
 }
+
+func TestDefaultMetricQueries(t *testing.T) {
+	queries := DefaultMetricQueries()
+	require.Len(t, queries, 16)
+
+	expected := map[string]string{
+		MetricAvgStoreTimes:        "sum(rate(mattermost_db_store_time_sum[10s])) by (method) / sum(rate(mattermost_db_store_time_count[10s])) by (method)",
+		MetricP99StoreTimes:        "histogram_quantile(0.99, sum(rate(mattermost_db_store_time_bucket[10s])) by (le,method))",
+		"API times (p99.9)":        "histogram_quantile(0.999, sum(rate(mattermost_api_time_bucket[10s])) by (le,handler))",
+		"API requests (count)":     "sum(increase(mattermost_api_time_count[10s])) by (handler)",
+		"Store calls (throughput)": "sum(rate(mattermost_db_store_time_count[10s])) by (method)",
+	}
+	for _, mq := range queries {
+		require.NoError(t, defaults.Validate(mq))
+		query, ok := expected[mq.Name]
+		if !ok {
+			continue
+		}
+		built, err := mq.build(queryParams{Range: "10s"})
+		require.NoError(t, err)
+		require.Equal(t, query, built)
+		delete(expected, mq.Name)
+	}
+	require.Empty(t, expected)
+}
+
+func TestGenerateMetricQueries(t *testing.T) {
+	input := map[string]model.Matrix{
+		`sum(mattermost_db_master_connections_total{instance=~"app.*"}) by (instance)`: {
+			&model.SampleStream{
+				Metric: model.Metric{"instance": "app0"},
+				Values: []model.SamplePair{{Timestamp: model.Now(), Value: 20}},
+			},
+		},
+		`sum(increase(mattermost_db_connection_wait_time_bucket{instance=~"app.*"}[10s])) by (le,instance)`: {
+			&model.SampleStream{
+				Metric: model.Metric{"instance": "app0", "le": "+Inf"},
+				Values: []model.SamplePair{{Timestamp: model.Now(), Value: 4}},
+			},
+			&model.SampleStream{
+				Metric: model.Metric{"instance": "app0", "le": "1"},
+				Values: []model.SamplePair{{Timestamp: model.Now(), Value: 3}},
+			},
+			&model.SampleStream{
+				Metric: model.Metric{"instance": "app0", "le": "0.1"},
+				Values: []model.SamplePair{{Timestamp: model.Now(), Value: 2}},
+			},
+		},
+	}
+
+	helper := &prometheus.Helper{}
+	helper.SetAPI(mockAPI{dataMap: input})
+
+	cfg := Config{
+		Label: `{instance=~"app.*"}`,
+		MetricQueries: []MetricQuery{
+			{
+				Name:    "DB connections",
+				Query:   "sum(mattermost_db_master_connections_total{{.Label}}) by ({{.GroupBy}})",
+				GroupBy: "instance",
+			},
+		},
+		HistogramQueries: []HistogramQuery{
+			{
+				Name:    "DB connection wait times",
+				Query:   "sum(increase(mattermost_db_connection_wait_time_bucket{{.Label}}[{{.Range}}])) by (le,{{.GroupBy}})",
+				GroupBy: "instance",
+			},
+		},
+	}
+
+	now := time.Now()
+	r, err := New("base", helper, cfg).Generate(now.Add(-10*time.Second), now)
+	require.NoError(t, err)
+	require.Equal(t, []MetricTable{
+		{Name: "DB connections", GroupBy: "instance", Values: map[model.LabelValue]model.SampleValue{"app0": 20}},
+	}, r.Metrics)
+	require.Equal(t, []HistogramTable{
+		{
+			Name:    "DB connection wait times",
+			GroupBy: "instance",
+			Histograms: map[model.LabelValue]Histogram{
+				"app0": {Buckets: []Bucket{{0.1, 2}, {1, 3}}, Count: 4},
+			},
+		},
+	}, r.Histograms)
+
+	cfg.MetricQueries[0].Query = "sum({{.Unknown}})"
+	_, err = New("base", helper, cfg).Generate(now.Add(-10*time.Second), now)
+	require.Error(t, err)
+}
+
+func TestLoadLegacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "legacy.out")
+	data := `{"Label": "old", "AvgStoreTimes": {"Post.Get": "0.01"}, "P99APITimes": {"getPost": "0.2"}}`
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), 0600))
+
+	r, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, "old", r.Label)
+	require.Equal(t, []MetricTable{
+		{Name: MetricAvgStoreTimes, GroupBy: "method", Unit: UnitSeconds, Values: map[model.LabelValue]model.SampleValue{"Post.Get": 0.01}},
+		{Name: MetricP99APITimes, GroupBy: "handler", Unit: UnitSeconds, Values: map[model.LabelValue]model.SampleValue{"getPost": 0.2}},
+	}, r.Metrics)
+}
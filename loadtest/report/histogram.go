@@ -0,0 +1,189 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// HistogramQuery describes the distribution of a metric to include in
+// reports, used to assess whether the differences between reports are
+// statistically significant.
+type HistogramQuery struct {
+	// A friendly name for the histogram, referenced by MetricQuery.Histogram.
+	Name string `validate:"notempty"`
+	// The template of the Prometheus query returning the increase of each
+	// bucket over the time range of the report, grouped by the "le" label
+	// and GroupBy. It can use the same fields as MetricQuery.Query.
+	Query string `validate:"notempty"`
+	// The label to group the histograms by.
+	GroupBy string `validate:"notempty"`
+}
+
+// Bucket is a cumulative histogram bucket.
+type Bucket struct {
+	UpperBound float64 // The inclusive upper bound of the bucket.
+	Count      float64 // The number of observations less or equal than UpperBound.
+}
+
+// Histogram is the distribution of the observations of a metric.
+type Histogram struct {
+	// The buckets with a finite upper bound, sorted by upper bound.
+	Buckets []Bucket
+	// The total number of observations.
+	Count float64
+}
+
+// HistogramTable holds the histograms of a metric, grouped by label.
+type HistogramTable struct {
+	Name       string // The name of the histogram.
+	GroupBy    string // The label the histograms are grouped by.
+	Histograms map[model.LabelValue]Histogram
+}
+
+// significanceLevel is the confidence below which differences are
+// considered to be noise.
+const significanceLevel = 0.95
+
+// minEffectSize is the Kolmogorov-Smirnov statistic, the largest distance
+// between the two distributions, below which differences are considered to
+// be noise. With the millions of observations of a typical run, the test
+// alone flags even negligible shifts as significant.
+const minEffectSize = 0.05
+
+// Names of the default histograms.
+const (
+	HistogramStoreTimes = "Store times"
+	HistogramAPITimes   = "API times"
+)
+
+// DefaultHistogramQueries returns the histograms included in reports when
+// none are configured.
+func DefaultHistogramQueries() []HistogramQuery {
+	return []HistogramQuery{
+		{
+			Name:    HistogramStoreTimes,
+			Query:   "sum(increase(mattermost_db_store_time_bucket{{.Label}}[{{.Range}}])) by (le,{{.GroupBy}})",
+			GroupBy: "method",
+		},
+		{
+			Name:    HistogramAPITimes,
+			Query:   "sum(increase(mattermost_api_time_bucket{{.Label}}[{{.Range}}])) by (le,{{.GroupBy}})",
+			GroupBy: "handler",
+		},
+	}
+}
+
+// histogram returns the table of the histogram with the given name, if
+// present.
+func (r Report) histogram(name string) (HistogramTable, bool) {
+	for _, h := range r.Histograms {
+		if h.Name == name {
+			return h, true
+		}
+	}
+	return HistogramTable{}, false
+}
+
+// getHistograms returns a map of labels to the histograms for the last
+// timestamp of a given query.
+func (g *Generator) getHistograms(endTime time.Time, query, label string) (map[model.LabelValue]Histogram, error) {
+	// As with getValue, the query already computes the values from
+	// startTime to endTime.
+	res, err := g.helper.Matrix(query, endTime.Add(-5*time.Second), endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	histograms := make(map[model.LabelValue]Histogram)
+	for _, sample := range res {
+		metric := sample.Metric[model.LabelName(label)]
+		val := sample.Values[len(sample.Values)-1]
+		if math.IsNaN(float64(val.Value)) {
+			continue
+		}
+		bound, err := strconv.ParseFloat(string(sample.Metric[model.BucketLabel]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket bound for %s: %w", metric, err)
+		}
+		h := histograms[metric]
+		if math.IsInf(bound, 1) {
+			h.Count = float64(val.Value)
+		} else {
+			h.Buckets = append(h.Buckets, Bucket{UpperBound: bound, Count: float64(val.Value)})
+		}
+		histograms[metric] = h
+	}
+
+	for label, h := range histograms {
+		sort.Slice(h.Buckets, func(i, j int) bool {
+			return h.Buckets[i].UpperBound < h.Buckets[j].UpperBound
+		})
+		histograms[label] = h
+	}
+	return histograms, nil
+}
+
+// cdf returns the fraction of observations less or equal than x.
+func (h Histogram) cdf(x float64) float64 {
+	var count float64
+	for _, b := range h.Buckets {
+		if b.UpperBound > x {
+			break
+		}
+		count = b.Count
+	}
+	return math.Min(count/h.Count, 1)
+}
+
+// significance returns the confidence, between 0 and 1, that the
+// observations of the two histograms come from different distributions.
+// It runs a two-sample Kolmogorov-Smirnov test on the bucket bounds, which
+// is conservative since differences within a bucket are not visible.
+// Differences smaller than minEffectSize have a confidence of 0. The second
+// value is false if any of the histograms has no observations.
+func significance(a, b Histogram) (float64, bool) {
+	if a.Count <= 0 || b.Count <= 0 {
+		return 0, false
+	}
+
+	var d float64
+	for _, h := range []Histogram{a, b} {
+		for _, bucket := range h.Buckets {
+			d = math.Max(d, math.Abs(a.cdf(bucket.UpperBound)-b.cdf(bucket.UpperBound)))
+		}
+	}
+	if d < minEffectSize {
+		return 0, true
+	}
+
+	n := math.Sqrt(a.Count * b.Count / (a.Count + b.Count))
+	return 1 - ksProbability((n+0.12+0.11/n)*d), true
+}
+
+// ksProbability returns the probability of the Kolmogorov-Smirnov statistic
+// being at least as large as the observed one under the null hypothesis.
+func ksProbability(lambda float64) float64 {
+	if lambda < 0.2 {
+		// The series converges too slowly, and the value is about 1.
+		return 1
+	}
+	var sum float64
+	sign := 1.0
+	for j := 1; j <= 100; j++ {
+		term := sign * 2 * math.Exp(-2*float64(j*j)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Max(0, math.Min(1, sum))
+}
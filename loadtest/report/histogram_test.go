@@ -0,0 +1,112 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/mattermost-load-test-ng/defaults"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramCDF(t *testing.T) {
+	h := Histogram{Buckets: []Bucket{{0.01, 2}, {0.1, 6}, {1, 8}}, Count: 10}
+	require.Equal(t, 0.0, h.cdf(0.001))
+	require.Equal(t, 0.2, h.cdf(0.01))
+	require.Equal(t, 0.6, h.cdf(0.5))
+	require.Equal(t, 0.8, h.cdf(1))
+	require.Equal(t, 0.8, h.cdf(10))
+}
+
+func TestSignificance(t *testing.T) {
+	t.Run("no observations", func(t *testing.T) {
+		_, ok := significance(Histogram{}, Histogram{Buckets: []Bucket{{0.1, 1}}, Count: 1})
+		require.False(t, ok)
+	})
+
+	t.Run("same distribution", func(t *testing.T) {
+		a := Histogram{Buckets: []Bucket{{0.01, 500}, {0.1, 900}}, Count: 1000}
+		b := Histogram{Buckets: []Bucket{{0.01, 505}, {0.1, 898}}, Count: 1000}
+		confidence, ok := significance(a, b)
+		require.True(t, ok)
+		require.Less(t, confidence, 0.5)
+	})
+
+	t.Run("shifted distribution", func(t *testing.T) {
+		a := Histogram{Buckets: []Bucket{{0.01, 500}, {0.1, 900}}, Count: 1000}
+		b := Histogram{Buckets: []Bucket{{0.01, 300}, {0.1, 800}}, Count: 1000}
+		confidence, ok := significance(a, b)
+		require.True(t, ok)
+		require.Greater(t, confidence, 0.99)
+	})
+
+	t.Run("few observations", func(t *testing.T) {
+		a := Histogram{Buckets: []Bucket{{0.01, 5}, {0.1, 9}}, Count: 10}
+		b := Histogram{Buckets: []Bucket{{0.01, 3}, {0.1, 8}}, Count: 10}
+		confidence, ok := significance(a, b)
+		require.True(t, ok)
+		require.Less(t, confidence, significanceLevel)
+	})
+
+	t.Run("small shift with many observations", func(t *testing.T) {
+		a := Histogram{Buckets: []Bucket{{0.01, 5e6}, {0.1, 9e6}}, Count: 1e7}
+		b := Histogram{Buckets: []Bucket{{0.01, 4.9e6}, {0.1, 8.95e6}}, Count: 1e7}
+		confidence, ok := significance(a, b)
+		require.True(t, ok)
+		require.Equal(t, 0.0, confidence)
+	})
+}
+
+func TestKSProbability(t *testing.T) {
+	require.Equal(t, 1.0, ksProbability(0))
+	require.InDelta(t, 0.27, ksProbability(1), 0.01)
+	require.InDelta(t, 0.0, ksProbability(3), 1e-6)
+}
+
+func TestDefaultHistogramQueries(t *testing.T) {
+	names := make(map[string]bool)
+	for _, hq := range DefaultHistogramQueries() {
+		require.NoError(t, defaults.Validate(hq))
+		names[hq.Name] = true
+	}
+	for _, mq := range DefaultMetricQueries() {
+		if mq.Histogram != "" {
+			require.True(t, names[mq.Histogram], "unknown histogram %s", mq.Histogram)
+		}
+	}
+}
+
+func TestCompareSignificance(t *testing.T) {
+	newReport := func(label string, avg model.SampleValue, hist Histogram) Report {
+		return Report{
+			Label: label,
+			Metrics: []MetricTable{
+				{Name: MetricAvgAPITimes, GroupBy: "handler", Unit: UnitSeconds, Histogram: HistogramAPITimes, Values: map[model.LabelValue]model.SampleValue{"getPost": avg}},
+			},
+			Histograms: []HistogramTable{
+				{Name: HistogramAPITimes, GroupBy: "handler", Histograms: map[model.LabelValue]Histogram{"getPost": hist}},
+			},
+		}
+	}
+	hist := Histogram{Buckets: []Bucket{{0.01, 50}, {0.1, 90}}, Count: 100}
+	base := newReport("base", 0.010, hist)
+	candidate := newReport("new", 0.012, hist)
+
+	var buf bytes.Buffer
+	require.NoError(t, Compare(&buf, CompareOpts{Format: FormatMarkdown}, base, candidate))
+	require.NotContains(t, buf.String(), "Confidence")
+
+	buf.Reset()
+	require.NoError(t, Compare(&buf, CompareOpts{Format: FormatMarkdown, Significance: true}, base, candidate))
+	require.Contains(t, buf.String(), "| handler | Base | Actual | Delta | Delta % | Confidence |")
+	require.Contains(t, buf.String(), "| getPost | 10ms| 12ms | 2ms | 16.667 | 0.0%")
+
+	buf.Reset()
+	require.NoError(t, Compare(&buf, CompareOpts{Format: FormatHTML, Significance: true}, base, candidate))
+	require.Contains(t, buf.String(), "<th>Confidence</th>")
+	require.Contains(t, buf.String(), `class="noise"`)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// htmlData holds the data used to render the HTML report template.
+type htmlData struct {
+	Title   string
+	Reports []htmlReportInfo
+	Tables  []htmlTable
+	Graphs  []htmlGraph
+}
+
+// htmlReportInfo holds the metadata of a single report.
+type htmlReportInfo struct {
+	Label     string
+	StartTime string
+	EndTime   string
+	Duration  string
+}
+
+// htmlTable is a sortable table comparing a single measurement across
+// reports.
+type htmlTable struct {
+	Title   string
+	Headers []string
+	Rows    []htmlRow
+}
+
+type htmlRow struct {
+	Label string
+	Cells []htmlCell
+}
+
+// htmlCell is a table cell. Value is used to sort the table by the cell's
+// column.
+type htmlCell struct {
+	Text  string
+	Value float64
+	Class string
+}
+
+// htmlGraph holds the series of a single metric from all the reports.
+type htmlGraph struct {
+	Name   string       `json:"name"`
+	Series []htmlSeries `json:"series"`
+}
+
+type htmlSeries struct {
+	Label string    `json:"label"`
+	X     []float64 `json:"x"` // Seconds since the first value.
+	Y     []float64 `json:"y"`
+}
+
+// WriteHTML writes a self-contained HTML page displaying the given reports
+// to target. The first report is considered to be the base, and the others
+// are compared against it. If significance is set, the confidence of each
+// delta is also displayed, and deltas which are likely noise are not
+// highlighted.
+func WriteHTML(target io.Writer, significance bool, reports ...Report) error {
+	if len(reports) == 0 {
+		return fmt.Errorf("report: no reports to display")
+	}
+	tmpl, err := template.New("report").Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("report: failed to parse HTML template: %w", err)
+	}
+	return tmpl.Execute(target, newHTMLData(reports, significance))
+}
+
+func newHTMLData(reports []Report, significance bool) htmlData {
+	base := reports[0]
+	c := calculateDeltas(reports...)
+
+	data := htmlData{
+		Title: "Load-test report: " + base.Label,
+	}
+	if len(reports) > 1 {
+		data.Title = "Load-test comparison"
+	}
+
+	for _, r := range reports {
+		info := htmlReportInfo{Label: r.Label, StartTime: "-", EndTime: "-", Duration: "-"}
+		if !r.StartTime.IsZero() {
+			info.StartTime = r.StartTime.UTC().Format(time.RFC3339)
+		}
+		if !r.EndTime.IsZero() {
+			info.EndTime = r.EndTime.UTC().Format(time.RFC3339)
+		}
+		if !r.StartTime.IsZero() && !r.EndTime.IsZero() {
+			info.Duration = r.EndTime.Sub(r.StartTime).String()
+		}
+		data.Reports = append(data.Reports, info)
+	}
+
+	for _, m := range c {
+		data.Tables = append(data.Tables, newHTMLTable(m, reports, significance))
+	}
+
+	for i, g := range base.Graphs {
+		hg := htmlGraph{Name: g.Name}
+		for _, r := range reports {
+			if i < len(r.Graphs) {
+				hg.Series = append(hg.Series, newHTMLSeries(r.Label, r.Graphs[i].Values))
+			}
+		}
+		data.Graphs = append(data.Graphs, hg)
+	}
+
+	return data
+}
+
+// newHTMLTable creates a table for the given metric comparison.
+func newHTMLTable(m metricComp, reports []Report, significance bool) htmlTable {
+	t := htmlTable{
+		Title:   m.Name,
+		Headers: []string{m.GroupBy, reports[0].Label},
+	}
+	for _, r := range reports[1:] {
+		t.Headers = append(t.Headers, r.Label, "Delta", "Delta %")
+		if significance {
+			t.Headers = append(t.Headers, "Confidence")
+		}
+	}
+
+	for _, label := range sortKeys(m.Values) {
+		value := roundValue(float64(m.Values[label]), m.Unit)
+		row := htmlRow{
+			Label: string(label),
+			Cells: []htmlCell{{Text: m.Unit.format(value), Value: value}},
+		}
+		for _, d := range m.diffs[label] {
+			// Changes in the load are neither better nor worse.
+			class := ""
+			switch {
+			case m.Unit.describesLoad():
+			case significance && !d.significant():
+				class = "noise"
+			case d.delta > 0:
+				class = "worse"
+			case d.delta < 0:
+				class = "better"
+			}
+			row.Cells = append(row.Cells,
+				htmlCell{Text: m.Unit.format(d.actual), Value: d.actual},
+				htmlCell{Text: m.Unit.format(d.delta), Value: d.delta, Class: class},
+				htmlCell{Text: fmt.Sprintf("%.3f", d.deltaPercent), Value: d.deltaPercent, Class: class},
+			)
+			if significance {
+				row.Cells = append(row.Cells, htmlCell{Text: d.formatConfidence(), Value: d.confidence * 100, Class: class})
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	return t
+}
+
+func newHTMLSeries(label string, values []model.SamplePair) htmlSeries {
+	s := htmlSeries{Label: label}
+	for _, v := range values {
+		// Missing values cannot be encoded in JSON and are not plotted.
+		if math.IsNaN(float64(v.Value)) || math.IsInf(float64(v.Value), 0) {
+			continue
+		}
+		s.X = append(s.X, float64(v.Timestamp-values[0].Timestamp)/1000)
+		s.Y = append(s.Y, float64(v.Value))
+	}
+	return s
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+// htmlTemplate is the template used to render HTML reports. All the styles
+// and scripts are embedded so that the resulting page can be shared as a
+// single file.
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.3em; margin-top: 2em; border-bottom: 1px solid #e1e4e8; }
+table { border-collapse: collapse; margin: 1em 0; font-size: 0.9em; }
+th, td { border: 1px solid #dfe2e5; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f6f8fa; }
+table.sortable th { cursor: pointer; user-select: none; }
+table.sortable th.asc::after { content: " \25B2"; }
+table.sortable th.desc::after { content: " \25BC"; }
+td.worse { color: #cb2431; }
+td.better { color: #22863a; }
+td.noise { color: #959da5; }
+.charts { display: flex; flex-wrap: wrap; }
+.chart { margin: 1em 2em 1em 0; }
+.chart h3 { font-size: 1em; margin: 0 0 0.5em 0; }
+.chart svg { border: 1px solid #e1e4e8; background: #fff; }
+.chart .axis { stroke: #959da5; stroke-width: 1; }
+.chart .grid { stroke: #eaecef; stroke-width: 1; }
+.chart text { font-size: 10px; fill: #586069; }
+.chart .line { fill: none; stroke-width: 1.5; }
+.chart .hidden { display: none; }
+.legend span { cursor: pointer; margin-right: 1em; font-size: 0.85em; }
+.legend span.off { opacity: 0.4; }
+.legend i { display: inline-block; width: 10px; height: 10px; margin-right: 4px; }
+.tooltip { position: absolute; pointer-events: none; background: rgba(36,41,46,0.9); color: #fff; padding: 4px 8px; font-size: 0.8em; border-radius: 3px; display: none; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+
+<h2>Reports</h2>
+<table>
+<tr><th>Label</th><th>Start time</th><th>End time</th><th>Duration</th></tr>
+{{- range .Reports}}
+<tr><td>{{.Label}}</td><td>{{.StartTime}}</td><td>{{.EndTime}}</td><td>{{.Duration}}</td></tr>
+{{- end}}
+</table>
+
+{{- range .Tables}}
+<h2>{{.Title}}</h2>
+<table class="sortable">
+<thead><tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.Label}}</td>{{range .Cells}}<td{{if .Class}} class="{{.Class}}"{{end}} data-value="{{.Value}}">{{.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+
+{{- if .Graphs}}
+<h2>Graphs</h2>
+<div class="charts" id="charts"></div>
+{{- end}}
+<div class="tooltip" id="tooltip"></div>
+
+<script>
+(function() {
+  "use strict";
+
+  // Sortable tables.
+  document.querySelectorAll("table.sortable").forEach(function(table) {
+    var headers = table.querySelectorAll("th");
+    headers.forEach(function(th, col) {
+      th.addEventListener("click", function() {
+        var asc = !th.classList.contains("asc");
+        headers.forEach(function(h) { h.classList.remove("asc", "desc"); });
+        th.classList.add(asc ? "asc" : "desc");
+        var tbody = table.querySelector("tbody");
+        var rows = Array.prototype.slice.call(tbody.querySelectorAll("tr"));
+        rows.sort(function(a, b) {
+          var ca = a.children[col], cb = b.children[col];
+          var res;
+          if (col === 0) {
+            res = ca.textContent.localeCompare(cb.textContent);
+          } else {
+            res = parseFloat(ca.dataset.value) - parseFloat(cb.dataset.value);
+          }
+          return asc ? res : -res;
+        });
+        rows.forEach(function(r) { tbody.appendChild(r); });
+      });
+    });
+  });
+
+  // Charts.
+  var graphs = {{.Graphs}};
+  var colors = ["#0366d6", "#d73a49", "#28a745", "#6f42c1", "#f66a0a", "#959da5"];
+  var ns = "http://www.w3.org/2000/svg";
+  var width = 560, height = 260, pad = {top: 10, right: 15, bottom: 30, left: 70};
+  var tooltip = document.getElementById("tooltip");
+  var container = document.getElementById("charts");
+
+  function el(name, attrs) {
+    var e = document.createElementNS(ns, name);
+    for (var k in attrs) { e.setAttribute(k, attrs[k]); }
+    return e;
+  }
+
+  function format(v) {
+    if (v !== 0 && (Math.abs(v) >= 1e5 || Math.abs(v) < 1e-2)) {
+      return v.toExponential(2);
+    }
+    return v.toFixed(2);
+  }
+
+  (graphs || []).forEach(function(g) {
+    var minX = Infinity, maxX = -Infinity, minY = Infinity, maxY = -Infinity;
+    g.series.forEach(function(s) {
+      (s.x || []).forEach(function(x) { minX = Math.min(minX, x); maxX = Math.max(maxX, x); });
+      (s.y || []).forEach(function(y) { minY = Math.min(minY, y); maxY = Math.max(maxY, y); });
+    });
+    if (!isFinite(minX)) { minX = 0; maxX = 1; minY = 0; maxY = 1; }
+    if (maxX === minX) { maxX = minX + 1; }
+    minY = Math.min(0, minY);
+    if (maxY === minY) { maxY = minY + 1; }
+
+    var w = width - pad.left - pad.right, h = height - pad.top - pad.bottom;
+    function sx(x) { return pad.left + (x - minX) / (maxX - minX) * w; }
+    function sy(y) { return pad.top + h - (y - minY) / (maxY - minY) * h; }
+
+    var div = document.createElement("div");
+    div.className = "chart";
+    var title = document.createElement("h3");
+    title.textContent = g.name;
+    div.appendChild(title);
+
+    var svg = el("svg", {width: width, height: height});
+    for (var i = 0; i <= 4; i++) {
+      var yv = minY + (maxY - minY) * i / 4;
+      svg.appendChild(el("line", {"class": "grid", x1: pad.left, x2: pad.left + w, y1: sy(yv), y2: sy(yv)}));
+      var label = el("text", {x: pad.left - 5, y: sy(yv) + 3, "text-anchor": "end"});
+      label.textContent = format(yv);
+      svg.appendChild(label);
+
+      var xv = minX + (maxX - minX) * i / 4;
+      var xlabel = el("text", {x: sx(xv), y: pad.top + h + 15, "text-anchor": "middle"});
+      xlabel.textContent = Math.round(xv) + "s";
+      svg.appendChild(xlabel);
+    }
+    svg.appendChild(el("line", {"class": "axis", x1: pad.left, x2: pad.left, y1: pad.top, y2: pad.top + h}));
+    svg.appendChild(el("line", {"class": "axis", x1: pad.left, x2: pad.left + w, y1: pad.top + h, y2: pad.top + h}));
+
+    var legend = document.createElement("div");
+    legend.className = "legend";
+
+    g.series.forEach(function(s, idx) {
+      var color = colors[idx % colors.length];
+      var points = (s.x || []).map(function(x, j) { return sx(x) + "," + sy(s.y[j]); }).join(" ");
+      var line = el("polyline", {"class": "line", points: points, stroke: color});
+      svg.appendChild(line);
+
+      var item = document.createElement("span");
+      var swatch = document.createElement("i");
+      swatch.style.background = color;
+      item.appendChild(swatch);
+      item.appendChild(document.createTextNode(s.label));
+      item.addEventListener("click", function() {
+        line.classList.toggle("hidden");
+        item.classList.toggle("off");
+      });
+      legend.appendChild(item);
+    });
+
+    svg.addEventListener("mousemove", function(ev) {
+      var rect = svg.getBoundingClientRect();
+      var x = minX + (ev.clientX - rect.left - pad.left) / w * (maxX - minX);
+      var lines = [Math.round(x) + "s"];
+      g.series.forEach(function(s) {
+        if (!s.x || s.x.length === 0) { return; }
+        var best = 0;
+        s.x.forEach(function(v, j) { if (Math.abs(v - x) < Math.abs(s.x[best] - x)) { best = j; } });
+        lines.push(s.label + ": " + format(s.y[best]));
+      });
+      tooltip.textContent = "";
+      lines.forEach(function(l, j) {
+        if (j > 0) { tooltip.appendChild(document.createElement("br")); }
+        tooltip.appendChild(document.createTextNode(l));
+      });
+      tooltip.style.display = "block";
+      tooltip.style.left = (ev.pageX + 12) + "px";
+      tooltip.style.top = (ev.pageY + 12) + "px";
+    });
+    svg.addEventListener("mouseleave", function() { tooltip.style.display = "none"; });
+
+    div.appendChild(svg);
+    div.appendChild(legend);
+    container.appendChild(div);
+  });
+})();
+</script>
+</body>
+</html>
+`
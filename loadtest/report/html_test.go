@@ -0,0 +1,63 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTML(t *testing.T) {
+	start := time.Date(2020, 6, 17, 4, 37, 5, 0, time.UTC)
+	newReport := func(label string, value model.SampleValue) Report {
+		return Report{
+			Label:     label,
+			StartTime: start,
+			EndTime:   start.Add(5 * time.Minute),
+			Metrics: []MetricTable{
+				{Name: MetricAvgStoreTimes, GroupBy: "method", Unit: UnitSeconds, Values: map[model.LabelValue]model.SampleValue{"Post.Get": value}},
+				{Name: MetricAvgAPITimes, GroupBy: "handler", Unit: UnitSeconds, Values: map[model.LabelValue]model.SampleValue{"getPost": value * 2}},
+				{Name: "Connections", GroupBy: "instance", Values: map[model.LabelValue]model.SampleValue{"app0": value * 1000}},
+			},
+			Graphs: []graph{
+				{
+					Name: "RPS",
+					Values: []model.SamplePair{
+						{Timestamp: 1000, Value: value},
+						{Timestamp: 6000, Value: model.SampleValue(math.NaN())},
+						{Timestamp: 11000, Value: value * 3},
+					},
+				},
+			},
+		}
+	}
+
+	require.Error(t, WriteHTML(&bytes.Buffer{}, false))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTML(&buf, false, newReport("base", 0.01)))
+	out := buf.String()
+	require.Contains(t, out, "Load-test report: base")
+	require.Contains(t, out, "2020-06-17T04:37:05Z")
+	require.Contains(t, out, "Post.Get")
+	require.Contains(t, out, "Connections")
+	require.Contains(t, out, "10.000")
+	require.NotContains(t, out, "Delta %")
+
+	buf.Reset()
+	require.NoError(t, Compare(&buf, CompareOpts{Format: FormatHTML}, newReport("base", 0.01), newReport("<new>", 0.02)))
+	out = buf.String()
+	require.Contains(t, out, "Load-test comparison")
+	require.Contains(t, out, "&lt;new&gt;")
+	require.Contains(t, out, "Delta %")
+	require.Contains(t, out, `class="worse"`)
+	require.Contains(t, out, `"x":[0,10],"y":[0.01,0.03]`)
+
+	require.Error(t, Compare(&buf, CompareOpts{Format: Format("pdf")}, newReport("base", 0.01)))
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+
+	"github.com/prometheus/common/model"
+)
+
+// Unit is the unit of measurement of the values of a metric.
+type Unit string
+
+// Available units.
+const (
+	UnitNone      Unit = ""
+	UnitSeconds   Unit = "seconds"
+	UnitBytes     Unit = "bytes"
+	UnitCount     Unit = "count"
+	UnitPerSecond Unit = "per_second"
+)
+
+// describesLoad reports whether values in this unit describe the load the
+// system was under, rather than how well it performed. Such values are
+// compared but are not checked for regressions.
+func (u Unit) describesLoad() bool {
+	return u == UnitCount || u == UnitPerSecond
+}
+
+// format returns a human readable representation of the given value.
+func (u Unit) format(v float64) string {
+	switch u {
+	case UnitSeconds:
+		return getDuration(v).String()
+	case UnitBytes:
+		abs := v
+		if abs < 0 {
+			abs = -abs
+		}
+		units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+		i := 0
+		for ; abs >= 1024 && i < len(units)-1; i++ {
+			abs /= 1024
+			v /= 1024
+		}
+		return fmt.Sprintf("%.2f%s", v, units[i])
+	case UnitCount:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case UnitPerSecond:
+		return strconv.FormatFloat(v, 'f', 3, 64) + "/s"
+	default:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+}
+
+// MetricQuery describes a tabular metric to include in reports. The value of
+// the metric is computed over the whole time range of the report, for each
+// value of the GroupBy label.
+type MetricQuery struct {
+	// A friendly name for the metric.
+	Name string `validate:"notempty"`
+	// The template of the Prometheus query to run. It is a Go text/template
+	// which can use the following fields:
+	// - Label: the label to filter the query with, as set in Config.
+	// - Range: the time range of the report, e.g. "300s".
+	// - GroupBy: the label to group the values by.
+	// - Quantile: the quantile to compute.
+	Query string `validate:"notempty"`
+	// The label to group the values by.
+	GroupBy string `validate:"notempty"`
+	// The quantile to compute, if any.
+	Quantile float64 `validate:"range:[0,1]"`
+	// The unit of measurement of the values.
+	Unit Unit `validate:"oneof:{,seconds,bytes,count,per_second}"`
+	// The name of the histogram, as set in Config.HistogramQueries,
+	// holding the distribution of the observations of this metric. If set,
+	// it is used to assess whether differences are statistically
+	// significant.
+	Histogram string
+}
+
+// queryParams holds the values available to MetricQuery templates.
+type queryParams struct {
+	Label    string
+	Range    string
+	GroupBy  string
+	Quantile float64
+}
+
+// build executes the query template with the given parameters.
+func (q MetricQuery) build(params queryParams) (string, error) {
+	return buildQuery(q.Name, q.Query, params, q.GroupBy, q.Quantile)
+}
+
+// buildQuery executes a query template with the given parameters.
+func buildQuery(name, query string, params queryParams, groupBy string, quantile float64) (string, error) {
+	tmpl, err := template.New(name).Parse(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid query template for %s: %w", name, err)
+	}
+	params.GroupBy = groupBy
+	params.Quantile = quantile
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return "", fmt.Errorf("invalid query template for %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// MetricTable holds the values of a tabular metric, grouped by label.
+type MetricTable struct {
+	Name      string // The name of the metric.
+	GroupBy   string // The label the values are grouped by.
+	Unit      Unit   // The unit of measurement of the values.
+	Histogram string `json:",omitempty"` // The name of the histogram of the observations.
+	Values    map[model.LabelValue]model.SampleValue
+}
+
+// Names of the legacy metrics, which were stored by older versions of
+// reports.
+const (
+	MetricAvgStoreTimes = "Store times (avg)"
+	MetricP99StoreTimes = "Store times (p99)"
+	MetricAvgAPITimes   = "API times (avg)"
+	MetricP99APITimes   = "API times (p99)"
+)
+
+// defaultQuantiles are the quantiles of the store and API times included in
+// reports by default.
+var defaultQuantiles = []struct {
+	name  string
+	value float64
+}{
+	{"p50", 0.5},
+	{"p90", 0.9},
+	{"p95", 0.95},
+	{"p99", 0.99},
+	{"p99.9", 0.999},
+}
+
+// DefaultMetricQueries returns the metrics included in reports when none
+// are configured: the average and quantiles of the store and API times, and
+// the number and rate of store calls and API requests.
+func DefaultMetricQueries() []MetricQuery {
+	var queries []MetricQuery
+	for _, m := range []struct {
+		kind      string
+		calls     string
+		metric    string
+		groupBy   string
+		histogram string
+	}{
+		{"Store", "calls", "mattermost_db_store_time", "method", HistogramStoreTimes},
+		{"API", "requests", "mattermost_api_time", "handler", HistogramAPITimes},
+	} {
+		queries = append(queries, MetricQuery{
+			Name:      m.kind + " times (avg)",
+			Query:     "sum(rate(" + m.metric + "_sum{{.Label}}[{{.Range}}])) by ({{.GroupBy}}) / sum(rate(" + m.metric + "_count{{.Label}}[{{.Range}}])) by ({{.GroupBy}})",
+			GroupBy:   m.groupBy,
+			Unit:      UnitSeconds,
+			Histogram: m.histogram,
+		})
+		for _, q := range defaultQuantiles {
+			queries = append(queries, MetricQuery{
+				Name:      m.kind + " times (" + q.name + ")",
+				Query:     "histogram_quantile({{.Quantile}}, sum(rate(" + m.metric + "_bucket{{.Label}}[{{.Range}}])) by (le,{{.GroupBy}}))",
+				GroupBy:   m.groupBy,
+				Quantile:  q.value,
+				Unit:      UnitSeconds,
+				Histogram: m.histogram,
+			})
+		}
+		queries = append(queries, MetricQuery{
+			Name:    m.kind + " " + m.calls + " (count)",
+			Query:   "sum(increase(" + m.metric + "_count{{.Label}}[{{.Range}}])) by ({{.GroupBy}})",
+			GroupBy: m.groupBy,
+			Unit:    UnitCount,
+		}, MetricQuery{
+			Name:    m.kind + " " + m.calls + " (throughput)",
+			Query:   "sum(rate(" + m.metric + "_count{{.Label}}[{{.Range}}])) by ({{.GroupBy}})",
+			GroupBy: m.groupBy,
+			Unit:    UnitPerSecond,
+		})
+	}
+	return queries
+}
+
+// legacyReport holds the fixed set of metrics stored by older versions of
+// reports.
+type legacyReport struct {
+	AvgStoreTimes map[model.LabelValue]model.SampleValue
+	P99StoreTimes map[model.LabelValue]model.SampleValue
+	AvgAPITimes   map[model.LabelValue]model.SampleValue
+	P99APITimes   map[model.LabelValue]model.SampleValue
+}
+
+// tables converts the legacy metrics into metric tables.
+func (l legacyReport) tables() []MetricTable {
+	var tables []MetricTable
+	add := func(name, groupBy string, values map[model.LabelValue]model.SampleValue) {
+		if values != nil {
+			tables = append(tables, MetricTable{Name: name, GroupBy: groupBy, Unit: UnitSeconds, Values: values})
+		}
+	}
+	add(MetricAvgStoreTimes, "method", l.AvgStoreTimes)
+	add(MetricP99StoreTimes, "method", l.P99StoreTimes)
+	add(MetricAvgAPITimes, "handler", l.AvgAPITimes)
+	add(MetricP99APITimes, "handler", l.P99APITimes)
+	return tables
+}
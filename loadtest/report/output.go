@@ -5,10 +5,9 @@ package report
 
 import (
 	"fmt"
-	"io/ioutil"
+	"io"
 	"math"
 	"os"
-	"os/exec"
 	"sort"
 	"strings"
 	"time"
@@ -18,148 +17,69 @@ import (
 )
 
 // displayMarkdown prints a given comparison in markdown to the given target.
-func displayMarkdown(c comp, target *os.File, base Report, cols int) {
-	fmt.Fprintln(target, "### Store times:")
-	printHeader(target, cols)
-
-	keys := sortKeys(c.store)
-	for _, label := range keys {
-		measurement := c.store[label]
-		fmt.Fprint(target, "| "+label)
-		avg := measurement[0]
-		p99 := measurement[1]
-
-		fmt.Fprint(target, " |  Avg")
-		fmt.Fprintf(target, "| %s", getDuration(float64(base.AvgStoreTimes[label])))
-		for i := 0; i < len(avg); i++ {
-			fmt.Fprintf(target, "| %s | %s | %.3f", avg[i].actual, avg[i].delta, avg[i].deltaPercent)
-		}
-		fmt.Fprintln(target)
-
-		fmt.Fprint(target, "| |  P99")
-		fmt.Fprintf(target, "| %s", getDuration(float64(base.P99StoreTimes[label])))
-		for i := 0; i < len(p99); i++ {
-			fmt.Fprintf(target, "| %s | %s | %.3f", p99[i].actual, p99[i].delta, p99[i].deltaPercent)
-		}
-		fmt.Fprintln(target)
-	}
-
-	fmt.Fprintln(target, "### API times:")
-	printHeader(target, cols)
-
-	keys = sortKeys(c.api)
-	for _, label := range keys {
-		measurement := c.api[label]
-		fmt.Fprint(target, "| ", label)
-		avg := measurement[0]
-		p99 := measurement[1]
-
-		fmt.Fprint(target, " | Avg")
-		fmt.Fprintf(target, "| %s", getDuration(float64(base.AvgAPITimes[label])))
-		for i := 0; i < len(avg); i++ {
-			fmt.Fprintf(target, "| %s | %s | %.3f", avg[i].actual, avg[i].delta, avg[i].deltaPercent)
-		}
-		fmt.Fprintln(target)
-
-		fmt.Fprint(target, "| | P99")
-		fmt.Fprintf(target, "| %s", getDuration(float64(base.P99APITimes[label])))
-		for i := 0; i < len(p99); i++ {
-			fmt.Fprintf(target, "| %s | %s | %.3f", p99[i].actual, p99[i].delta, p99[i].deltaPercent)
+// If significance is set, the confidence of each delta is also printed.
+func displayMarkdown(c comp, target io.Writer, cols int, significance bool) {
+	for _, m := range c {
+		fmt.Fprintf(target, "### %s:\n", m.Name)
+		printHeader(target, m.GroupBy, cols, significance)
+
+		for _, label := range sortKeys(m.Values) {
+			fmt.Fprintf(target, "| %s | %s", label, m.Unit.format(float64(m.Values[label])))
+			for _, d := range m.diffs[label] {
+				fmt.Fprintf(target, "| %s | %s | %.3f", m.Unit.format(d.actual), m.Unit.format(d.delta), d.deltaPercent)
+				if significance {
+					fmt.Fprintf(target, " | %s", d.formatConfidence())
+				}
+			}
+			fmt.Fprintln(target)
 		}
-		fmt.Fprintln(target)
 	}
 }
 
 // printHeader prints the header row of a markdown table.
-func printHeader(target *os.File, cols int) {
-	fmt.Fprint(target, "| | | Base | ")
+func printHeader(target io.Writer, groupBy string, cols int, significance bool) {
+	fmt.Fprintf(target, "| %s | Base | ", groupBy)
 	header := ""
 	for i := 0; i < cols; i++ {
 		header += "Actual | Delta | Delta % |"
+		if significance {
+			header += " Confidence |"
+		}
 	}
 	fmt.Fprintln(target, header)
 
-	fmt.Fprint(target, "| --- | --- | --- | ")
+	fmt.Fprint(target, "| --- | --- | ")
 	header = ""
 	for i := 0; i < cols; i++ {
 		header += "--- | --- | --- |"
-	}
-	fmt.Fprintln(target, header)
-}
-
-// generateGraph creates an input file for GNUplot to create a plot from.
-func generateGraph(name, baseLabel string, base graph, others []labelValues) error {
-	f, err := ioutil.TempFile("", "tmp.out")
-	if err != nil {
-		return err
-	}
-	defer os.Remove(f.Name())
-	// Write the header
-	fmt.Fprint(f, "# time, base")
-	for i := range others {
-		fmt.Fprintf(f, ", col%d", i)
-	}
-	fmt.Fprintln(f)
-	// Do a row-wise traversal of the data.
-	for i, pt := range base.Values {
-		// Write the line of values separated by comma.
-		fmt.Fprintf(f, "%d, %f", i, pt.Value)
-		for _, r := range others {
-			if i < len(r.values) {
-				fmt.Fprintf(f, ", %f", r.values[i].Value)
-			}
+		if significance {
+			header += " --- |"
 		}
-		// Print a newline to go to the next timestamp.
-		fmt.Fprintln(f)
-	}
-	err = f.Close()
-	if err != nil {
-		return err
-	}
-
-	err = plot(name, f.Name(), others, baseLabel)
-	if err != nil {
-		return fmt.Errorf("error while plotting %s graph: %s", name, err)
 	}
-	return nil
+	fmt.Fprintln(target, header)
 }
 
-// plot creates a gnu plot file and then creates a png output file from it.
-func plot(metric, fileName string, others []labelValues, baseLabel string) error {
-	f, err := ioutil.TempFile("", "tmp.plt")
+// generateGraph renders a graph comparing a metric from multiple reports
+// to an image file in the current directory.
+func generateGraph(name string, graphs []labelValues, format GraphFormat) error {
+	imageFile := strings.Replace(strings.ToLower(name), " ", "-", -1) + "." + string(format)
+	f, err := os.Create(imageFile)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(f.Name())
 
-	imageFile := strings.Replace(strings.ToLower(metric), " ", "-", -1)
-	fmt.Fprintln(f, "set grid")
-	fmt.Fprintln(f, "set terminal png")
-	fmt.Fprintf(f, "set output '%s.png'\n", imageFile)
-	fmt.Fprintf(f, "set title '%s'\n", metric)
-	fmt.Fprintln(f, "set xlabel 'time (normalized)'")
-
-	// Just some gnuplot jargon to specify plot characteristics and the columns to compare.
-	fmt.Fprintf(f, "plot '%s' u 1:2 w lp t '%s'", fileName, baseLabel)
-	for i := 0; i < len(others); i++ {
-		fmt.Fprintf(f, ", '%s' u 1:%d w lp t '%s'", fileName, i+3, others[i].label)
+	if err := newChart(name, graphs).render(f, format); err != nil {
+		f.Close()
+		return fmt.Errorf("error while rendering %s graph: %w", name, err)
 	}
-
-	err = f.Close()
-	if err != nil {
+	if err := f.Close(); err != nil {
 		return err
 	}
-
-	cmd := exec.Command("gnuplot", f.Name())
-	_, err = cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("error while running gnuplot: %w", err)
-	}
-	mlog.Info("Wrote " + imageFile + ".png")
+	mlog.Info("Wrote " + imageFile)
 	return nil
 }
 
-func sortKeys(m map[model.LabelValue]avgp99) []model.LabelValue {
+func sortKeys(m map[model.LabelValue]model.SampleValue) []model.LabelValue {
 	var labels []model.LabelValue
 	for key := range m {
 		labels = append(labels, key)
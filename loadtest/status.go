@@ -19,6 +19,7 @@ const (
 	Starting
 	Running
 	Stopping
+	Paused
 )
 
 // ErrInvalidState is returned when an unknown state variable is encoded/decoded.
@@ -42,6 +43,8 @@ func (s *State) UnmarshalJSON(b []byte) error {
 		*s = Running
 	case "stopping":
 		*s = Stopping
+	case "paused":
+		*s = Paused
 	}
 
 	return nil
@@ -61,18 +64,40 @@ func (s State) MarshalJSON() ([]byte, error) {
 		res = "running"
 	case Stopping:
 		res = "stopping"
+	case Paused:
+		res = "paused"
 	}
 
 	return json.Marshal(res)
 }
 
+// RampStage determines which stage of a ramp phase is being executed.
+type RampStage string
+
+// Different possible stages of a ramp phase.
+const (
+	RampStageRamping RampStage = "ramping"
+	RampStageHolding RampStage = "holding"
+	RampStageDone    RampStage = "done"
+)
+
+// RampStatus contains information about the execution of the configured
+// RampProfile.
+type RampStatus struct {
+	Phase          int       // Index of the phase being executed.
+	NumPhases      int       // Total number of phases in the profile.
+	Stage          RampStage // Stage of the phase being executed.
+	StageStartTime time.Time // Time when the current stage was entered.
+}
+
 // Status contains various information about the load test.
 type Status struct {
-	State           State     // State of the load test.
-	NumUsers        int64     // Number of active users.
-	NumUsersAdded   int64     // Number of users added since the start of the test.
-	NumUsersRemoved int64     // Number of users removed since the start of the test.
-	NumUsersStopped int64     // Number of users that stopped running.
-	NumErrors       int64     // Number of errors that have occurred.
-	StartTime       time.Time // Time when the load test was started. This only logs the time when the load test was first started, and does not get reset if it was subsequently restarted.
+	State           State       // State of the load test.
+	NumUsers        int64       // Number of active users.
+	NumUsersAdded   int64       // Number of users added since the start of the test.
+	NumUsersRemoved int64       // Number of users removed since the start of the test.
+	NumUsersStopped int64       // Number of users that stopped running.
+	NumErrors       int64       // Number of errors that have occurred.
+	StartTime       time.Time   // Time when the load test was started. This only logs the time when the load test was first started, and does not get reset if it was subsequently restarted.
+	Ramp            *RampStatus `json:",omitempty"` // Status of the ramp profile, if one is configured.
 }
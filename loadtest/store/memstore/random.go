@@ -21,7 +21,10 @@ var (
 	ErrTeamStoreEmpty    = errors.New("memstore: team store is empty")
 	ErrChannelStoreEmpty = errors.New("memstore: channel store is empty")
 	ErrChannelNotFound   = errors.New("memstore: channel not found")
+	ErrMemberNotFound    = errors.New("memstore: channel member not found")
 	ErrPostNotFound      = errors.New("memstore: post not found")
+	ErrThreadNotFound    = errors.New("memstore: thread not found")
+	ErrFileInfoNotFound  = errors.New("memstore: file info not found")
 	ErrInvalidData       = errors.New("memstore: invalid data found")
 )
 
@@ -324,3 +327,35 @@ func pickRandomKeyFromMap(m interface{}) (interface{}, error) {
 	idx := rand.Intn(len(keys))
 	return keys[idx].Interface(), nil
 }
+
+// RandomThread returns a random followed thread.
+func (s *MemStore) RandomThread() (store.Thread, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.threads) == 0 {
+		return store.Thread{}, ErrThreadNotFound
+	}
+
+	key, err := pickRandomKeyFromMap(s.threads)
+	if err != nil {
+		return store.Thread{}, err
+	}
+	return *s.threads[key.(string)], nil
+}
+
+// RandomFileInfo returns a random file info.
+func (s *MemStore) RandomFileInfo() (model.FileInfo, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.fileInfos) == 0 {
+		return model.FileInfo{}, ErrFileInfoNotFound
+	}
+
+	key, err := pickRandomKeyFromMap(s.fileInfos)
+	if err != nil {
+		return model.FileInfo{}, err
+	}
+	return *s.fileInfos[key.(string)], nil
+}
@@ -10,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
+
 	"github.com/mattermost/mattermost-server/v5/model"
 )
 
@@ -39,6 +41,10 @@ type MemStore struct {
 	currentTeam         *model.Team
 	channelViews        map[string]int64
 	profileImages       map[string]bool
+	threads             map[string]*store.Thread
+	threadsQueue        *CQueue
+	fileInfos           map[string]*model.FileInfo
+	fileInfosQueue      *CQueue
 }
 
 // New returns a new instance of MemStore with the given config.
@@ -86,6 +92,10 @@ func (s *MemStore) Clear() {
 	s.license = map[string]string{}
 	s.channelViews = map[string]int64{}
 	s.profileImages = map[string]bool{}
+	s.threads = map[string]*store.Thread{}
+	s.threadsQueue.Reset()
+	s.fileInfos = map[string]*model.FileInfo{}
+	s.fileInfosQueue.Reset()
 }
 
 func (s *MemStore) setupQueues(config *Config) error {
@@ -122,6 +132,20 @@ func (s *MemStore) setupQueues(config *Config) error {
 			},
 			&s.statusesQueue,
 		},
+		{
+			config.MaxStoredThreads,
+			func() interface{} {
+				return new(store.Thread)
+			},
+			&s.threadsQueue,
+		},
+		{
+			config.MaxStoredFileInfos,
+			func() interface{} {
+				return new(model.FileInfo)
+			},
+			&s.fileInfosQueue,
+		},
 	}
 
 	for _, setup := range setups {
@@ -344,6 +368,12 @@ func (s *MemStore) SetPost(post *model.Post) error {
 	post.ShallowCopy(p)
 	s.posts[post.Id] = p
 
+	if post.Metadata != nil {
+		for _, info := range post.Metadata.Files {
+			s.setFileInfo(info)
+		}
+	}
+
 	return nil
 }
 
@@ -607,6 +637,31 @@ func (s *MemStore) ChannelMember(channelId, userId string) (model.ChannelMember,
 	return cm, nil
 }
 
+// SetChannelMemberNotifyProps merges the given notification preferences into
+// the ones of the stored channel member.
+func (s *MemStore) SetChannelMemberNotifyProps(channelId, userId string, notifyProps model.StringMap) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cm := s.channelMembers[channelId][userId]
+	if cm == nil {
+		return ErrMemberNotFound
+	}
+
+	// Copies returned by ChannelMember share the current map, so a new one is
+	// built instead of updating it in place.
+	props := make(model.StringMap, len(cm.NotifyProps)+len(notifyProps))
+	for k, v := range cm.NotifyProps {
+		props[k] = v
+	}
+	for k, v := range notifyProps {
+		props[k] = v
+	}
+	cm.NotifyProps = props
+
+	return nil
+}
+
 func (s *MemStore) RemoveChannelMember(channelId string, userId string) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -870,3 +925,165 @@ func (s *MemStore) SetProfileImage(userId string) error {
 	s.profileImages[userId] = true
 	return nil
 }
+
+// Thread returns the followed thread with the given root post id.
+func (s *MemStore) Thread(threadId string) (*store.Thread, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if thread, ok := s.threads[threadId]; ok {
+		t := *thread
+		return &t, nil
+	}
+	return nil, ErrThreadNotFound
+}
+
+// ThreadsSorted returns the followed threads, sorted by the time of their
+// last reply. If unreadOnly is set, only threads with unread replies are
+// returned.
+func (s *MemStore) ThreadsSorted(unreadOnly, asc bool) ([]*store.Thread, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var threads []*store.Thread
+	for _, thread := range s.threads {
+		if unreadOnly && thread.UnreadReplies == 0 {
+			continue
+		}
+		t := *thread
+		threads = append(threads, &t)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		if asc {
+			return threads[i].LastReplyAt < threads[j].LastReplyAt
+		}
+		return threads[i].LastReplyAt > threads[j].LastReplyAt
+	})
+	return threads, nil
+}
+
+// SetThreads stores the given followed threads.
+func (s *MemStore) SetThreads(threads []*store.Thread) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, thread := range threads {
+		if thread == nil || thread.PostId == "" {
+			return errors.New("memstore: thread should not be nil and have an id")
+		}
+
+		if t, ok := s.threads[thread.PostId]; ok {
+			*t = *thread
+			continue
+		}
+
+		// We get an element from the queue and check if we have it in the map and
+		// if it points to the same memory location. If so, we delete it since it means the queue is full.
+		// This is done to keep the data pointed by the map consistent with the data stored in the queue.
+		t := s.threadsQueue.Get().(*store.Thread)
+		if tt, ok := s.threads[t.PostId]; ok && tt == t {
+			delete(s.threads, t.PostId)
+		}
+		*t = *thread
+		s.threads[thread.PostId] = t
+	}
+
+	return nil
+}
+
+// MarkThreadAsRead marks the given thread as viewed at the given timestamp
+// in milliseconds.
+func (s *MemStore) MarkThreadAsRead(threadId string, timestamp int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	thread, ok := s.threads[threadId]
+	if !ok {
+		return ErrThreadNotFound
+	}
+	thread.LastViewedAt = timestamp
+	thread.UnreadReplies = 0
+	thread.UnreadMentions = 0
+	return nil
+}
+
+// MarkAllThreadsInTeamAsRead marks all the followed threads in the given
+// team, including the ones in direct and group channels, as viewed.
+func (s *MemStore) MarkAllThreadsInTeamAsRead(teamId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := model.GetMillis()
+	for _, thread := range s.threads {
+		if thread.Post != nil {
+			if channel, ok := s.channels[thread.Post.ChannelId]; ok && channel.TeamId != "" && channel.TeamId != teamId {
+				continue
+			}
+		}
+		thread.LastViewedAt = now
+		thread.UnreadReplies = 0
+		thread.UnreadMentions = 0
+	}
+	return nil
+}
+
+// RemoveThread removes the given thread, which the user stopped following.
+func (s *MemStore) RemoveThread(threadId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.threads, threadId)
+	return nil
+}
+
+// FileInfo returns the file info with the given id.
+func (s *MemStore) FileInfo(fileId string) (*model.FileInfo, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if info, ok := s.fileInfos[fileId]; ok {
+		i := *info
+		return &i, nil
+	}
+	return nil, ErrFileInfoNotFound
+}
+
+// SetFileInfos stores the given file infos.
+func (s *MemStore) SetFileInfos(infos []*model.FileInfo) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, info := range infos {
+		if info == nil || info.Id == "" {
+			return errors.New("memstore: file info should not be nil and have an id")
+		}
+		s.setFileInfo(info)
+	}
+
+	return nil
+}
+
+// setFileInfo stores the given file info.
+// It must be called with s.lock held.
+func (s *MemStore) setFileInfo(info *model.FileInfo) {
+	// Deleted files are not accessible anymore.
+	if info.DeleteAt > 0 {
+		delete(s.fileInfos, info.Id)
+		return
+	}
+
+	if i, ok := s.fileInfos[info.Id]; ok {
+		*i = *info
+		return
+	}
+
+	// We get an element from the queue and check if we have it in the map and
+	// if it points to the same memory location. If so, we delete it since it means the queue is full.
+	// This is done to keep the data pointed by the map consistent with the data stored in the queue.
+	i := s.fileInfosQueue.Get().(*model.FileInfo)
+	if ii, ok := s.fileInfos[i.Id]; ok && ii == i {
+		delete(s.fileInfos, i.Id)
+	}
+	*i = *info
+	s.fileInfos[info.Id] = i
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
+
 	"github.com/mattermost/mattermost-server/v5/model"
 
 	"github.com/stretchr/testify/require"
@@ -482,6 +484,30 @@ func TestChannelMembers(t *testing.T) {
 		require.Equal(t, expected, member)
 	})
 
+	t.Run("SetChannelMemberNotifyProps", func(t *testing.T) {
+		channelId := model.NewId()
+		userId := model.NewId()
+		err := s.SetChannelMemberNotifyProps(channelId, userId, model.StringMap{})
+		require.Equal(t, ErrMemberNotFound, err)
+		err = s.SetChannelMember(channelId, &model.ChannelMember{
+			ChannelId:   channelId,
+			UserId:      userId,
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
+		})
+		require.NoError(t, err)
+		before, err := s.ChannelMember(channelId, userId)
+		require.NoError(t, err)
+		err = s.SetChannelMemberNotifyProps(channelId, userId, model.StringMap{
+			model.MARK_UNREAD_NOTIFY_PROP: model.CHANNEL_MARK_UNREAD_MENTION,
+		})
+		require.NoError(t, err)
+		member, err := s.ChannelMember(channelId, userId)
+		require.NoError(t, err)
+		require.Equal(t, model.CHANNEL_MARK_UNREAD_MENTION, member.NotifyProps[model.MARK_UNREAD_NOTIFY_PROP])
+		require.Equal(t, model.CHANNEL_NOTIFY_DEFAULT, member.NotifyProps[model.DESKTOP_NOTIFY_PROP])
+		require.Equal(t, model.CHANNEL_MARK_UNREAD_ALL, before.NotifyProps[model.MARK_UNREAD_NOTIFY_PROP])
+	})
+
 	t.Run("Remove channel members", func(t *testing.T) {
 		s := newStore(t)
 		channel := &model.Channel{Id: model.NewId()}
@@ -568,6 +594,142 @@ func TestTeamMembers(t *testing.T) {
 	})
 }
 
+func TestThreads(t *testing.T) {
+	s := newStore(t)
+
+	teamId := model.NewId()
+	channel := &model.Channel{Id: model.NewId(), TeamId: teamId}
+	otherChannel := &model.Channel{Id: model.NewId(), TeamId: model.NewId()}
+	require.NoError(t, s.SetChannels([]*model.Channel{channel, otherChannel}))
+
+	thread1 := &store.Thread{
+		PostId:        model.NewId(),
+		LastReplyAt:   100,
+		UnreadReplies: 2,
+		Post:          &model.Post{ChannelId: channel.Id},
+	}
+	thread2 := &store.Thread{
+		PostId:      model.NewId(),
+		LastReplyAt: 200,
+		Post:        &model.Post{ChannelId: channel.Id},
+	}
+	thread3 := &store.Thread{
+		PostId:         model.NewId(),
+		LastReplyAt:    300,
+		UnreadReplies:  1,
+		UnreadMentions: 1,
+		Post:           &model.Post{ChannelId: otherChannel.Id},
+	}
+
+	t.Run("SetThreads", func(t *testing.T) {
+		_, err := s.RandomThread()
+		require.Equal(t, ErrThreadNotFound, err)
+		require.Error(t, s.SetThreads([]*store.Thread{{}}))
+
+		require.NoError(t, s.SetThreads([]*store.Thread{thread1, thread2, thread3}))
+		thread, err := s.Thread(thread1.PostId)
+		require.NoError(t, err)
+		require.Equal(t, thread1, thread)
+		require.False(t, thread == thread1)
+
+		_, err = s.RandomThread()
+		require.NoError(t, err)
+	})
+
+	t.Run("ThreadsSorted", func(t *testing.T) {
+		threads, err := s.ThreadsSorted(false, false)
+		require.NoError(t, err)
+		require.Equal(t, []*store.Thread{thread3, thread2, thread1}, threads)
+
+		threads, err = s.ThreadsSorted(true, true)
+		require.NoError(t, err)
+		require.Equal(t, []*store.Thread{thread1, thread3}, threads)
+	})
+
+	t.Run("MarkThreadAsRead", func(t *testing.T) {
+		require.Equal(t, ErrThreadNotFound, s.MarkThreadAsRead(model.NewId(), 1))
+		require.NoError(t, s.MarkThreadAsRead(thread1.PostId, 150))
+		thread, err := s.Thread(thread1.PostId)
+		require.NoError(t, err)
+		require.EqualValues(t, 150, thread.LastViewedAt)
+		require.Zero(t, thread.UnreadReplies)
+	})
+
+	t.Run("MarkAllThreadsInTeamAsRead", func(t *testing.T) {
+		require.NoError(t, s.SetThreads([]*store.Thread{thread1}))
+		require.NoError(t, s.MarkAllThreadsInTeamAsRead(teamId))
+		threads, err := s.ThreadsSorted(true, false)
+		require.NoError(t, err)
+		require.Equal(t, []*store.Thread{thread3}, threads)
+	})
+
+	t.Run("RemoveThread", func(t *testing.T) {
+		require.NoError(t, s.RemoveThread(thread3.PostId))
+		_, err := s.Thread(thread3.PostId)
+		require.Equal(t, ErrThreadNotFound, err)
+	})
+
+	t.Run("MaxStoredThreads", func(t *testing.T) {
+		s := newStore(t)
+		for i := 0; i < 150; i++ {
+			require.NoError(t, s.SetThreads([]*store.Thread{{PostId: model.NewId()}}))
+		}
+		threads, err := s.ThreadsSorted(false, false)
+		require.NoError(t, err)
+		require.Len(t, threads, 100)
+	})
+}
+
+func TestFileInfos(t *testing.T) {
+	s := newStore(t)
+
+	info := &model.FileInfo{Id: model.NewId(), Name: "test.png"}
+
+	t.Run("SetFileInfos", func(t *testing.T) {
+		_, err := s.RandomFileInfo()
+		require.Equal(t, ErrFileInfoNotFound, err)
+		require.Error(t, s.SetFileInfos([]*model.FileInfo{{}}))
+
+		require.NoError(t, s.SetFileInfos([]*model.FileInfo{info}))
+		stored, err := s.FileInfo(info.Id)
+		require.NoError(t, err)
+		require.Equal(t, info, stored)
+		require.False(t, stored == info)
+
+		random, err := s.RandomFileInfo()
+		require.NoError(t, err)
+		require.Equal(t, *info, random)
+	})
+
+	t.Run("SetPost", func(t *testing.T) {
+		postInfo := &model.FileInfo{Id: model.NewId(), Name: "test.jpg"}
+		require.NoError(t, s.SetPost(&model.Post{
+			Id:       model.NewId(),
+			FileIds:  []string{postInfo.Id},
+			Metadata: &model.PostMetadata{Files: []*model.FileInfo{postInfo}},
+		}))
+		stored, err := s.FileInfo(postInfo.Id)
+		require.NoError(t, err)
+		require.Equal(t, postInfo, stored)
+	})
+
+	t.Run("DeletedFileInfo", func(t *testing.T) {
+		deleted := *info
+		deleted.DeleteAt = 1
+		require.NoError(t, s.SetFileInfos([]*model.FileInfo{&deleted}))
+		_, err := s.FileInfo(info.Id)
+		require.Equal(t, ErrFileInfoNotFound, err)
+	})
+
+	t.Run("MaxStoredFileInfos", func(t *testing.T) {
+		s := newStore(t)
+		for i := 0; i < 150; i++ {
+			require.NoError(t, s.SetFileInfos([]*model.FileInfo{{Id: model.NewId()}}))
+		}
+		require.Len(t, s.fileInfos, 100)
+	})
+}
+
 func TestConfig(t *testing.T) {
 	s := newStore(t)
 
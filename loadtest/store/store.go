@@ -108,6 +108,22 @@ type UserStore interface {
 	UserForPost(postId string) (string, error)
 	// FileInfoForPost returns the FileInfo for the given post if any.
 	FileInfoForPost(postId string) ([]*model.FileInfo, error)
+
+	// files
+	// FileInfo returns the file info with the given id.
+	FileInfo(fileId string) (*model.FileInfo, error)
+	// RandomFileInfo returns a random file info.
+	RandomFileInfo() (model.FileInfo, error)
+
+	// threads
+	// Thread returns the followed thread with the given root post id.
+	Thread(threadId string) (*Thread, error)
+	// ThreadsSorted returns the followed threads, sorted by the time of
+	// their last reply. If unreadOnly is set, only threads with unread
+	// replies are returned.
+	ThreadsSorted(unreadOnly, asc bool) ([]*Thread, error)
+	// RandomThread returns a random followed thread.
+	RandomThread() (Thread, error)
 }
 
 // MutableUserStore is a super-set of UserStore which, apart from providing
@@ -136,6 +152,11 @@ type MutableUserStore interface {
 	SetPosts(posts []*model.Post) error
 	Post(postId string) (*model.Post, error)
 
+	// files
+	// SetFileInfos stores the given file infos. The file infos attached to
+	// the posts set in the store are stored as well.
+	SetFileInfos(infos []*model.FileInfo) error
+
 	// reactions
 	SetReactions(postId string, reactions []*model.Reaction) error
 	SetReaction(reaction *model.Reaction) error
@@ -156,6 +177,9 @@ type MutableUserStore interface {
 	SetChannelMembers(channelMembers *model.ChannelMembers) error
 	ChannelMembers(channelId string) (*model.ChannelMembers, error)
 	SetChannelMember(channelId string, channelMember *model.ChannelMember) error
+	// SetChannelMemberNotifyProps updates the notification preferences of
+	// the given channel member.
+	SetChannelMemberNotifyProps(channelId, userId string, notifyProps model.StringMap) error
 	RemoveChannelMember(channelId string, userId string) error
 
 	// teams
@@ -181,4 +205,17 @@ type MutableUserStore interface {
 
 	// profile
 	SetProfileImage(userId string) error
+
+	// threads
+	// SetThreads stores the given followed threads.
+	SetThreads(threads []*Thread) error
+	// MarkThreadAsRead marks the given thread as viewed at the given
+	// timestamp in milliseconds.
+	MarkThreadAsRead(threadId string, timestamp int64) error
+	// MarkAllThreadsInTeamAsRead marks all the followed threads in the given
+	// team, including the ones in direct and group channels, as viewed.
+	MarkAllThreadsInTeamAsRead(teamId string) error
+	// RemoveThread removes the given thread, which the user stopped
+	// following.
+	RemoveThread(threadId string) error
 }
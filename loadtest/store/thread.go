@@ -0,0 +1,33 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package store
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// Thread holds the state of a collaborative thread followed by the user.
+// The model package of the server version in use predates Collaborative
+// Threads, so the type mirrors the JSON representation returned by the
+// server.
+type Thread struct {
+	// PostId is the id of the root post of the thread.
+	PostId       string        `json:"id"`
+	ReplyCount   int64         `json:"reply_count"`
+	LastReplyAt  int64         `json:"last_reply_at"`
+	LastViewedAt int64         `json:"last_viewed_at"`
+	Participants []*model.User `json:"participants"`
+	Post         *model.Post   `json:"post"`
+	// The number of replies and mentions since the thread was last viewed.
+	UnreadReplies  int64 `json:"unread_replies"`
+	UnreadMentions int64 `json:"unread_mentions"`
+}
+
+// Threads is a page of the threads followed by the user in a team.
+type Threads struct {
+	Total               int64     `json:"total"`
+	TotalUnreadThreads  int64     `json:"total_unread_threads"`
+	TotalUnreadMentions int64     `json:"total_unread_mentions"`
+	Threads             []*Thread `json:"threads"`
+}
@@ -11,6 +11,26 @@ import (
 	"github.com/mattermost/mattermost-server/v5/model"
 )
 
+// WebSocket events sent by the Collaborative Threads feature. They are not
+// defined by the model package of the server version in use.
+const (
+	WebSocketEventThreadUpdated       = "thread_updated"
+	WebSocketEventThreadFollowChanged = "thread_follow_changed"
+	WebSocketEventThreadReadChanged   = "thread_read_changed"
+)
+
+// CustomStatusPropKey is the key of the user prop holding the custom status
+// of a user.
+const CustomStatusPropKey = "customStatus"
+
+// CustomStatus is a short message, along with an emoji, set by a user to let
+// others know what they are up to. It is not defined by the model package of
+// the server version in use.
+type CustomStatus struct {
+	Emoji string `json:"emoji"`
+	Text  string `json:"text"`
+}
+
 // TestUserSuffixRegexp matches the numerical suffix of test usernames,
 // which are assumed to be in this format.
 var TestUserSuffixRegexp = regexp.MustCompile(`\d+$`)
@@ -47,6 +67,14 @@ type User interface {
 	GetUsersByUsernames(usernames []string) ([]string, error)
 	GetUserStatus() error
 	GetUsersStatusesByIds(userIds []string) error
+	// UpdateUserStatus sets the status of the user. Possible values are
+	// model.STATUS_ONLINE, model.STATUS_AWAY, model.STATUS_DND and
+	// model.STATUS_OFFLINE.
+	UpdateUserStatus(status string) error
+	// UpdateCustomStatus sets the custom status of the user.
+	UpdateCustomStatus(customStatus *CustomStatus) error
+	// RemoveCustomStatus clears the custom status of the user.
+	RemoveCustomStatus() error
 	GetUsersInChannel(channelId string, page, perPage int) error
 	GetUsers(page, perPage int) ([]string, error)
 	SetProfileImage(data []byte) error
@@ -69,12 +97,41 @@ type User interface {
 	SaveReaction(reaction *model.Reaction) error
 	DeleteReaction(reaction *model.Reaction) error
 	GetReactions(postId string) error
+	// GetPostThread fetches the root post and the replies of the thread the
+	// given post belongs to. It returns the ids of the posts.
+	GetPostThread(postId string) ([]string, error)
+
+	// threads
+	// GetUserThreads fetches the first page of the threads followed by the
+	// user in the given team, sorted by the time of their last reply. If
+	// unreadOnly is set, only threads with unread replies are fetched.
+	GetUserThreads(teamId string, unreadOnly bool, pageSize int) ([]*store.Thread, error)
+	// GetUserThread fetches the given followed thread.
+	GetUserThread(teamId, threadId string) (*store.Thread, error)
+	// UpdateThreadRead marks the given thread as read up to the given
+	// timestamp in milliseconds.
+	UpdateThreadRead(teamId, threadId string, timestamp int64) error
+	// UpdateThreadsReadForUser marks all the followed threads in the given
+	// team as read.
+	UpdateThreadsReadForUser(teamId string) error
+	// UpdateThreadFollow starts or stops following the given thread.
+	UpdateThreadFollow(teamId, threadId string, state bool) error
 
 	// files
 	UploadFile(data []byte, channelId, filename string) (*model.FileUploadResponse, error)
 	GetFileInfosForPost(postId string) ([]*model.FileInfo, error)
 	GetFileThumbnail(fileId string) error
 	GetFilePreview(fileId string) error
+	// GetFileInfo fetches the info of the given file.
+	GetFileInfo(fileId string) (*model.FileInfo, error)
+	// DownloadFile downloads the contents of the given file.
+	DownloadFile(fileId string) error
+	// SearchFiles searches the files in the given team matching the given
+	// terms.
+	SearchFiles(teamId, terms string, isOrSearch bool) ([]*model.FileInfo, error)
+	// SearchFilesInChannel searches the files shared in the given team
+	// channel. It relies on the server's file search.
+	SearchFilesInChannel(channelId string) ([]*model.FileInfo, error)
 
 	// channels
 	CreateChannel(channel *model.Channel) (string, error)
@@ -91,6 +148,9 @@ type User interface {
 	// GetChannelMembersForUser gets all the channel members for a user on a team.
 	GetChannelMembersForUser(userId, teamId string) error
 	GetChannelMember(channelId string, userId string) error
+	// UpdateChannelNotifyProps updates the notification preferences of a
+	// channel member.
+	UpdateChannelNotifyProps(channelId, userId string, props map[string]string) error
 	GetChannelStats(channelId string) error
 	AddChannelMember(channelId, userId string) error
 	GetChannelsForTeamForUser(teamId, userId string, includeDeleted bool) ([]*model.Channel, error)
@@ -125,6 +185,23 @@ type User interface {
 	// plugins
 	GetWebappPlugins() error
 
+	// integrations
+	// ExecuteCommand executes the given slash command in the given channel.
+	ExecuteCommand(channelId, command string) (*model.CommandResponse, error)
+	// CreateCommand creates the given custom slash command and returns its id.
+	CreateCommand(cmd *model.Command) (string, error)
+	// CreateIncomingWebhook creates the given incoming webhook and returns its
+	// id.
+	CreateIncomingWebhook(hook *model.IncomingWebhook) (string, error)
+	// PostToIncomingWebhook posts a message through the given incoming
+	// webhook, the same way an external service would.
+	PostToIncomingWebhook(hookId string, request *model.IncomingWebhookRequest) error
+	// CreateOutgoingWebhook creates the given outgoing webhook and returns its
+	// id.
+	CreateOutgoingWebhook(hook *model.OutgoingWebhook) (string, error)
+	// CreateBot creates the given bot account and returns its user id.
+	CreateBot(bot *model.Bot) (string, error)
+
 	// license
 	// GetClientLicense returns the client license in the old format.
 	GetClientLicense() error
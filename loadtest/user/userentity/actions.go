@@ -4,9 +4,18 @@
 package userentity
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store/memstore"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 )
@@ -277,7 +286,7 @@ func (ue *UserEntity) UploadFile(data []byte, channelId, filename string) (*mode
 		return nil, resp.Error
 	}
 
-	return fresp, nil
+	return fresp, ue.store.SetFileInfos(fresp.FileInfos)
 }
 
 func (ue *UserEntity) CreateChannel(channel *model.Channel) (string, error) {
@@ -459,6 +468,17 @@ func (ue *UserEntity) GetChannelMember(channelId, userId string) error {
 	return ue.store.SetChannelMember(channelId, cm)
 }
 
+// UpdateChannelNotifyProps updates the notification preferences of the
+// given channel member and stores them.
+func (ue *UserEntity) UpdateChannelNotifyProps(channelId, userId string, props map[string]string) error {
+	_, resp := ue.client.UpdateChannelNotifyProps(channelId, userId, props)
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return ue.store.SetChannelMemberNotifyProps(channelId, userId, props)
+}
+
 func (ue *UserEntity) GetChannelStats(channelId string) error {
 	_, resp := ue.client.GetChannelStats(channelId, "")
 	if resp.Error != nil {
@@ -602,12 +622,12 @@ func (ue *UserEntity) GetUserStatus() error {
 		return err
 	}
 
-	_, resp := ue.client.GetUserStatus(user.Id, "")
+	status, resp := ue.client.GetUserStatus(user.Id, "")
 	if resp.Error != nil {
 		return resp.Error
 	}
 
-	return nil
+	return ue.store.SetStatus(user.Id, status)
 }
 
 func (ue *UserEntity) GetUsersStatusesByIds(userIds []string) error {
@@ -625,6 +645,72 @@ func (ue *UserEntity) GetUsersStatusesByIds(userIds []string) error {
 	return nil
 }
 
+// UpdateUserStatus sets the status of the user. Possible values are
+// model.STATUS_ONLINE, model.STATUS_AWAY, model.STATUS_DND and
+// model.STATUS_OFFLINE.
+func (ue *UserEntity) UpdateUserStatus(status string) error {
+	user, err := ue.getUserFromStore()
+	if err != nil {
+		return err
+	}
+
+	st, resp := ue.client.UpdateUserStatus(user.Id, &model.Status{
+		UserId: user.Id,
+		Status: status,
+	})
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return ue.store.SetStatus(user.Id, st)
+}
+
+// UpdateCustomStatus sets the custom status of the user.
+func (ue *UserEntity) UpdateCustomStatus(customStatus *user.CustomStatus) error {
+	if customStatus == nil {
+		return errors.New("userentity: customStatus should not be nil")
+	}
+
+	data, err := json.Marshal(customStatus)
+	if err != nil {
+		return err
+	}
+
+	return ue.setCustomStatus(http.MethodPut, string(data))
+}
+
+// RemoveCustomStatus clears the custom status of the user.
+func (ue *UserEntity) RemoveCustomStatus() error {
+	return ue.setCustomStatus(http.MethodDelete, "")
+}
+
+// setCustomStatus updates or, if data is empty, removes the custom status of
+// the user, keeping the user props in the store up to date.
+func (ue *UserEntity) setCustomStatus(method, data string) error {
+	u, err := ue.getUserFromStore()
+	if err != nil {
+		return err
+	}
+
+	if err := ue.doAPIRequest(method, ue.client.GetUserRoute(u.Id)+"/status/custom", data, nil); err != nil {
+		return err
+	}
+
+	// The stored user is shared, so it gets replaced rather than modified.
+	updated := *u
+	updated.Props = model.StringMap{}
+	for k, v := range u.Props {
+		updated.Props[k] = v
+	}
+	if data == "" {
+		delete(updated.Props, user.CustomStatusPropKey)
+	} else {
+		updated.Props[user.CustomStatusPropKey] = data
+	}
+
+	return ue.store.SetUser(&updated)
+}
+
 func (ue *UserEntity) GetUsersInChannel(channelId string, page, perPage int) error {
 	if len(channelId) == 0 {
 		return errors.New("userentity: channelId should not be empty")
@@ -680,7 +766,7 @@ func (ue *UserEntity) GetFileInfosForPost(postId string) ([]*model.FileInfo, err
 	if resp.Error != nil {
 		return nil, resp.Error
 	}
-	return infos, nil
+	return infos, ue.store.SetFileInfos(infos)
 }
 
 func (ue *UserEntity) GetFileThumbnail(fileId string) error {
@@ -700,6 +786,72 @@ func (ue *UserEntity) GetFilePreview(fileId string) error {
 	return nil
 }
 
+// GetFileInfo fetches the info of the given file.
+func (ue *UserEntity) GetFileInfo(fileId string) (*model.FileInfo, error) {
+	info, resp := ue.client.GetFileInfo(fileId)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return info, ue.store.SetFileInfos([]*model.FileInfo{info})
+}
+
+// DownloadFile downloads the contents of the given file.
+func (ue *UserEntity) DownloadFile(fileId string) error {
+	_, resp := ue.client.DownloadFile(fileId, true)
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
+
+// SearchFiles searches the files in the given team matching the given terms.
+func (ue *UserEntity) SearchFiles(teamId, terms string, isOrSearch bool) ([]*model.FileInfo, error) {
+	page, perPage, timeZoneOffset, includeDeleted := 0, 20, 0, false
+	params := &model.SearchParameter{
+		Terms:                  &terms,
+		IsOrSearch:             &isOrSearch,
+		TimeZoneOffset:         &timeZoneOffset,
+		Page:                   &page,
+		PerPage:                &perPage,
+		IncludeDeletedChannels: &includeDeleted,
+	}
+
+	var list fileInfoList
+	route := ue.client.GetTeamRoute(teamId) + "/files/search"
+	if err := ue.doAPIRequest(http.MethodPost, route, params.SearchParameterToJson(), &list); err != nil {
+		return nil, err
+	}
+
+	infos := make([]*model.FileInfo, 0, len(list.Order))
+	for _, id := range list.Order {
+		if info, ok := list.FileInfos[id]; ok {
+			infos = append(infos, info)
+		}
+	}
+	if len(infos) == 0 {
+		return nil, nil
+	}
+
+	return infos, ue.store.SetFileInfos(infos)
+}
+
+// SearchFilesInChannel searches the files shared in the given channel by
+// running a file search with the channel as the only modifier. Results depend
+// on the server's search indexing. Only team channels are supported, since
+// file searches are scoped to a team.
+func (ue *UserEntity) SearchFilesInChannel(channelId string) ([]*model.FileInfo, error) {
+	channel, err := ue.store.Channel(channelId)
+	if err != nil {
+		return nil, err
+	} else if channel == nil || channel.TeamId == "" {
+		return nil, fmt.Errorf("userentity: team channel %q not found in the store", channelId)
+	}
+
+	return ue.SearchFiles(channel.TeamId, "in:"+channel.Name, false)
+}
+
 func (ue *UserEntity) AddTeamMemberFromInvite(token, inviteId string) error {
 	tm, resp := ue.client.AddTeamMemberFromInvite(token, inviteId)
 	if resp.Error != nil {
@@ -869,6 +1021,74 @@ func (ue *UserEntity) GetWebappPlugins() error {
 	return nil
 }
 
+// ExecuteCommand executes the given slash command in the given channel.
+func (ue *UserEntity) ExecuteCommand(channelId, command string) (*model.CommandResponse, error) {
+	cmdResp, resp := ue.client.ExecuteCommand(channelId, command)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return cmdResp, nil
+}
+
+// CreateCommand creates the given custom slash command and returns its id.
+func (ue *UserEntity) CreateCommand(cmd *model.Command) (string, error) {
+	cmd, resp := ue.client.CreateCommand(cmd)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	return cmd.Id, nil
+}
+
+// CreateIncomingWebhook creates the given incoming webhook and returns its id.
+func (ue *UserEntity) CreateIncomingWebhook(hook *model.IncomingWebhook) (string, error) {
+	hook, resp := ue.client.CreateIncomingWebhook(hook)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	return hook.Id, nil
+}
+
+// PostToIncomingWebhook posts a message through the given incoming webhook,
+// the same way an external service would.
+func (ue *UserEntity) PostToIncomingWebhook(hookId string, request *model.IncomingWebhookRequest) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("userentity: failed to encode webhook request: %w", err)
+	}
+
+	r, appErr := ue.client.DoApiRequest(http.MethodPost, ue.client.Url+"/hooks/"+hookId, string(data), "")
+	if appErr != nil {
+		return model.BuildErrorResponse(r, appErr).Error
+	}
+	_, _ = io.Copy(ioutil.Discard, r.Body)
+	_ = r.Body.Close()
+
+	return nil
+}
+
+// CreateOutgoingWebhook creates the given outgoing webhook and returns its id.
+func (ue *UserEntity) CreateOutgoingWebhook(hook *model.OutgoingWebhook) (string, error) {
+	hook, resp := ue.client.CreateOutgoingWebhook(hook)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	return hook.Id, nil
+}
+
+// CreateBot creates the given bot account and returns its user id.
+func (ue *UserEntity) CreateBot(bot *model.Bot) (string, error) {
+	bot, resp := ue.client.CreateBot(bot)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	return bot.UserId, nil
+}
+
 // GetClientLicense returns the client license in the old format.
 func (ue *UserEntity) GetClientLicense() error {
 	license, resp := ue.client.GetOldClientLicense("")
@@ -953,3 +1173,151 @@ func (ue *UserEntity) getPluginStatuses() (model.PluginStatuses, *model.Response
 	}()
 	return model.PluginStatusesFromJson(r.Body), model.BuildResponse(r)
 }
+
+// GetPostThread fetches the root post and the replies of the thread the
+// given post belongs to. It returns the ids of the posts.
+func (ue *UserEntity) GetPostThread(postId string) ([]string, error) {
+	postList, resp := ue.client.GetPostThread(postId, "")
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	if postList == nil || len(postList.Posts) == 0 {
+		return nil, nil
+	}
+
+	return postList.Order, ue.store.SetPosts(postListToSlice(postList))
+}
+
+// GetUserThreads fetches the first page of the threads followed by the user
+// in the given team, sorted by the time of their last reply. If unreadOnly
+// is set, only threads with unread replies are fetched.
+func (ue *UserEntity) GetUserThreads(teamId string, unreadOnly bool, pageSize int) ([]*store.Thread, error) {
+	route, err := ue.threadsRoute(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("pageSize", strconv.Itoa(pageSize))
+	query.Set("extended", "true")
+	if unreadOnly {
+		query.Set("unread", "true")
+	}
+
+	var threads store.Threads
+	if err := ue.doAPIRequest(http.MethodGet, route+"?"+query.Encode(), "", &threads); err != nil {
+		return nil, err
+	}
+	if len(threads.Threads) == 0 {
+		return nil, nil
+	}
+
+	return threads.Threads, ue.store.SetThreads(threads.Threads)
+}
+
+// GetUserThread fetches the given followed thread.
+func (ue *UserEntity) GetUserThread(teamId, threadId string) (*store.Thread, error) {
+	route, err := ue.threadsRoute(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	var thread store.Thread
+	if err := ue.doAPIRequest(http.MethodGet, route+"/"+threadId+"?extended=true", "", &thread); err != nil {
+		return nil, err
+	}
+
+	return &thread, ue.store.SetThreads([]*store.Thread{&thread})
+}
+
+// UpdateThreadRead marks the given thread as read up to the given timestamp
+// in milliseconds.
+func (ue *UserEntity) UpdateThreadRead(teamId, threadId string, timestamp int64) error {
+	route, err := ue.threadsRoute(teamId)
+	if err != nil {
+		return err
+	}
+
+	if err := ue.doAPIRequest(http.MethodPut, fmt.Sprintf("%s/%s/read/%d", route, threadId, timestamp), "", nil); err != nil {
+		return err
+	}
+
+	if err := ue.store.MarkThreadAsRead(threadId, timestamp); err != nil && !errors.Is(err, memstore.ErrThreadNotFound) {
+		return err
+	}
+	return nil
+}
+
+// UpdateThreadsReadForUser marks all the followed threads in the given team
+// as read.
+func (ue *UserEntity) UpdateThreadsReadForUser(teamId string) error {
+	route, err := ue.threadsRoute(teamId)
+	if err != nil {
+		return err
+	}
+
+	if err := ue.doAPIRequest(http.MethodPut, route+"/read", "", nil); err != nil {
+		return err
+	}
+
+	return ue.store.MarkAllThreadsInTeamAsRead(teamId)
+}
+
+// UpdateThreadFollow starts or stops following the given thread.
+func (ue *UserEntity) UpdateThreadFollow(teamId, threadId string, state bool) error {
+	route, err := ue.threadsRoute(teamId)
+	if err != nil {
+		return err
+	}
+
+	method := http.MethodPut
+	if !state {
+		method = http.MethodDelete
+	}
+	if err := ue.doAPIRequest(method, route+"/"+threadId+"/following", "", nil); err != nil {
+		return err
+	}
+
+	if !state {
+		return ue.store.RemoveThread(threadId)
+	}
+	return nil
+}
+
+// fileInfoList is a page of the results of a file search. The model package
+// of the server version in use predates file search.
+type fileInfoList struct {
+	Order     []string                   `json:"order"`
+	FileInfos map[string]*model.FileInfo `json:"file_infos"`
+}
+
+// threadsRoute returns the route of the threads followed by the user in the
+// given team.
+func (ue *UserEntity) threadsRoute(teamId string) (string, error) {
+	user, err := ue.getUserFromStore()
+	if err != nil {
+		return "", err
+	}
+	return ue.client.GetUserRoute(user.Id) + ue.client.GetTeamRoute(teamId) + "/threads", nil
+}
+
+// doAPIRequest performs a request to an API route which is not supported by
+// the client version in use, and decodes the response into v, if not nil.
+func (ue *UserEntity) doAPIRequest(method, route, data string, v interface{}) error {
+	r, appErr := ue.client.DoApiRequest(method, ue.client.ApiUrl+route, data, "")
+	if appErr != nil {
+		return model.BuildErrorResponse(r, appErr).Error
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		_ = r.Body.Close()
+	}()
+
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("userentity: failed to decode response of %s: %w", route, err)
+	}
+	return nil
+}
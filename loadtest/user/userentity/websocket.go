@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/store"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/store/memstore"
+	"github.com/mattermost/mattermost-load-test-ng/loadtest/user"
 	"github.com/mattermost/mattermost-load-test-ng/loadtest/user/websocket"
 
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -92,6 +94,72 @@ func (ue *UserEntity) handlePostEvent(ev *model.WebSocketEvent) error {
 	return nil
 }
 
+func (ue *UserEntity) handleThreadEvent(ev *model.WebSocketEvent) error {
+	switch ev.EventType() {
+	case user.WebSocketEventThreadUpdated:
+		var data string
+		if el, ok := ev.Data["thread"]; !ok {
+			return fmt.Errorf("thread data is missing")
+		} else if data, ok = el.(string); !ok {
+			return fmt.Errorf("type of the thread data should be a string, but it is %T", el)
+		}
+
+		var thread *store.Thread
+		if err := json.Unmarshal([]byte(data), &thread); err != nil {
+			return err
+		}
+		return ue.store.SetThreads([]*store.Thread{thread})
+	case user.WebSocketEventThreadFollowChanged:
+		threadId, _ := ev.Data["thread_id"].(string)
+		if state, _ := ev.Data["state"].(bool); !state && threadId != "" {
+			return ue.store.RemoveThread(threadId)
+		}
+	case user.WebSocketEventThreadReadChanged:
+		threadId, _ := ev.Data["thread_id"].(string)
+		if threadId == "" {
+			// All the threads in the team have been marked as read.
+			return ue.store.MarkAllThreadsInTeamAsRead(ev.GetBroadcast().TeamId)
+		}
+		timestamp, _ := ev.Data["timestamp"].(float64)
+		if err := ue.store.MarkThreadAsRead(threadId, int64(timestamp)); err != nil && !errors.Is(err, memstore.ErrThreadNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ue *UserEntity) handleStatusEvent(ev *model.WebSocketEvent) error {
+	userId, ok := ev.Data["user_id"].(string)
+	if !ok || userId == "" {
+		return fmt.Errorf("user_id data is missing")
+	}
+	status, ok := ev.Data["status"].(string)
+	if !ok {
+		return fmt.Errorf("status data is missing")
+	}
+
+	return ue.store.SetStatus(userId, &model.Status{
+		UserId: userId,
+		Status: status,
+	})
+}
+
+func (ue *UserEntity) handleChannelMemberEvent(ev *model.WebSocketEvent) error {
+	var data string
+	if el, ok := ev.Data["channelMember"]; !ok {
+		return fmt.Errorf("channelMember data is missing")
+	} else if data, ok = el.(string); !ok {
+		return fmt.Errorf("type of the channelMember data should be a string, but it is %T", el)
+	}
+
+	var cm *model.ChannelMember
+	if err := json.Unmarshal([]byte(data), &cm); err != nil {
+		return err
+	}
+	return ue.store.SetChannelMember(cm.ChannelId, cm)
+}
+
 // wsEventHandler handles the given WebSocket event by calling the appropriate
 // store methods to make sure the internal user state is kept updated.
 // Handling the event at this layer is needed to keep the user state in
@@ -103,6 +171,12 @@ func (ue *UserEntity) wsEventHandler(ev *model.WebSocketEvent) error {
 		return ue.handleReactionEvent(ev)
 	case model.WEBSOCKET_EVENT_POSTED, model.WEBSOCKET_EVENT_POST_EDITED, model.WEBSOCKET_EVENT_POST_DELETED:
 		return ue.handlePostEvent(ev)
+	case user.WebSocketEventThreadUpdated, user.WebSocketEventThreadFollowChanged, user.WebSocketEventThreadReadChanged:
+		return ue.handleThreadEvent(ev)
+	case model.WEBSOCKET_EVENT_STATUS_CHANGE:
+		return ue.handleStatusEvent(ev)
+	case model.WEBSOCKET_EVENT_CHANNEL_MEMBER_UPDATED:
+		return ue.handleChannelMemberEvent(ev)
 	}
 
 	return nil
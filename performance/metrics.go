@@ -5,6 +5,7 @@ package performance
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -14,6 +15,7 @@ const (
 	metricsNamespace     = "loadtest"
 	metricsSubSystemHTTP = "http"
 	metricsSubSystemWS   = "websocket"
+	metricsSubSystemUser = "user"
 )
 
 type UserEntityMetrics struct {
@@ -23,9 +25,33 @@ type UserEntityMetrics struct {
 	WebSocketConnections prometheus.Gauge
 }
 
+// ControllerMetrics holds the metrics emitted by UserController
+// implementations for each user action.
+type ControllerMetrics struct {
+	ActionTimes    *prometheus.HistogramVec
+	ActionSuccess  *prometheus.CounterVec
+	ActionFailures *prometheus.CounterVec
+}
+
+// ObserveAction records the time taken to execute the given user action and
+// its outcome. It's a no-op if m is nil.
+func (m *ControllerMetrics) ObserveAction(action string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	labels := prometheus.Labels{"action": action}
+	m.ActionTimes.With(labels).Observe(elapsed.Seconds())
+	if err != nil {
+		m.ActionFailures.With(labels).Inc()
+		return
+	}
+	m.ActionSuccess.With(labels).Inc()
+}
+
 type Metrics struct {
 	registry  *prometheus.Registry
 	ueMetrics UserEntityMetrics
+	ucMetrics ControllerMetrics
 }
 
 func NewMetrics() *Metrics {
@@ -66,6 +92,33 @@ func NewMetrics() *Metrics {
 	})
 	m.registry.MustRegister(m.ueMetrics.WebSocketConnections)
 
+	m.ucMetrics.ActionTimes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubSystemUser,
+		Name:      "action_time",
+		Help:      "The time taken to execute user actions.",
+	},
+		[]string{"action"})
+	m.registry.MustRegister(m.ucMetrics.ActionTimes)
+
+	m.ucMetrics.ActionSuccess = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubSystemUser,
+		Name:      "action_success_total",
+		Help:      "The total number of successful user actions.",
+	},
+		[]string{"action"})
+	m.registry.MustRegister(m.ucMetrics.ActionSuccess)
+
+	m.ucMetrics.ActionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubSystemUser,
+		Name:      "action_failures_total",
+		Help:      "The total number of failed user actions.",
+	},
+		[]string{"action"})
+	m.registry.MustRegister(m.ucMetrics.ActionFailures)
+
 	return &m
 }
 
@@ -76,3 +129,7 @@ func (m *Metrics) Handler() http.Handler {
 func (m *Metrics) UserEntityMetrics() *UserEntityMetrics {
 	return &m.ueMetrics
 }
+
+func (m *Metrics) ControllerMetrics() *ControllerMetrics {
+	return &m.ucMetrics
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package performance
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveAction(t *testing.T) {
+	t.Run("nil metrics", func(t *testing.T) {
+		var m *ControllerMetrics
+		require.NotPanics(t, func() {
+			m.ObserveAction("CreatePost", time.Second, nil)
+		})
+	})
+
+	t.Run("outcomes", func(t *testing.T) {
+		m := NewMetrics().ControllerMetrics()
+		labels := prometheus.Labels{"action": "CreatePost"}
+
+		m.ObserveAction("CreatePost", time.Second, nil)
+		m.ObserveAction("CreatePost", time.Second, nil)
+		m.ObserveAction("CreatePost", time.Second, errors.New("failed"))
+
+		require.Equal(t, 2.0, testutil.ToFloat64(m.ActionSuccess.With(labels)))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.ActionFailures.With(labels)))
+	})
+}